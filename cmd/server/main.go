@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -15,6 +16,8 @@ import (
 	"LegoManagerAPI/internal/config"
 	"LegoManagerAPI/internal/config/application"
 	"LegoManagerAPI/internal/database"
+	"LegoManagerAPI/internal/database/migrate"
+	"LegoManagerAPI/internal/events"
 
 	"github.com/charmbracelet/log"
 )
@@ -55,6 +58,15 @@ func main() {
 	}
 	log.Info("Database ping successful!")
 
+	// Bring the schema up to date before anything queries it
+	migrateCtx, migrateCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer migrateCancel()
+
+	if err := migrate.Up(migrateCtx, db.Pool); err != nil {
+		log.Fatal("Failed to run database migrations", "error", err)
+	}
+	log.Info("Database migrations up to date")
+
 	// Initialize Redis connection
 	log.Info("Connecting to Redis...")
 	redisClient, err := cache.NewRedisClient(cfg.Cache)
@@ -63,12 +75,39 @@ func main() {
 	}
 	defer redisClient.Close()
 
-	// Initialize Bricklink service
-	bricklinkService := service.NewBricklinkService(cfg.Bricklink)
-	log.Info("Bricklink service initialized")
+	// Initialize Bricklink service, without credentials the API runs without it and its routes answer 503
+	var bricklinkService *service.BricklinkService
+	if missing := cfg.Bricklink.MissingCredentials(); len(missing) > 0 {
+		log.Warn("BrickLink credentials are not configured, BrickLink routes are disabled", "missing", missing)
+	} else {
+		bricklinkService = service.NewBricklinkService(cfg.Bricklink)
+		if cfg.Bricklink.RateLimitBackend == "redis" {
+			bricklinkService.ShareRateLimit(redisClient)
+		}
+		log.Info("Bricklink service initialized")
+	}
+
+	// Initialize event bus and register subscribers
+	bus := events.NewBus(100, 4)
+	events.Subscribe(bus, func(ctx context.Context, event events.UserCreated) {
+		log.Info("User created", "user_id", event.UserID, "username", event.Username)
+	})
 
 	// Create HTTP server
-	server := api.NewServer(cfg, db, redisClient, bricklinkService)
+	server := api.NewServer(cfg, db, redisClient, bricklinkService, bus)
+
+	// Start background jobs, they run until jobsCtx is cancelled on shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	var jobsDone sync.WaitGroup
+	if server.PortfolioSnapshots != nil {
+		jobsDone.Add(1)
+		go func() {
+			defer jobsDone.Done()
+			server.PortfolioSnapshots.Run(jobsCtx)
+		}()
+	}
 
 	// Start the server in goroutine so it doesn't block
 	go func() {
@@ -95,6 +134,13 @@ func main() {
 		log.Error("Server shutdown error", "error", err)
 	}
 
+	// Stop background jobs and wait for them before closing their dependencies
+	stopJobs()
+	jobsDone.Wait()
+
+	// Drain queued events before closing their dependencies
+	bus.Close()
+
 	// Close Redis connection
 	if err := redisClient.Close(); err != nil {
 		log.Error("Error closing Redis", "error", err)
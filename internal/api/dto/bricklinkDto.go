@@ -0,0 +1,28 @@
+package dto
+
+// ItemExistsResponse represents the result of a BrickLink item existence check
+type ItemExistsResponse struct {
+	Exists bool   `json:"exists"`
+	Name   string `json:"name,omitempty"`
+}
+
+// RecentMinifig is one of the minifigs a user viewed recently, name and thumbnail are left out
+// when the minifig info is not available
+type RecentMinifig struct {
+	ID           string `json:"id"`
+	Name         string `json:"name,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// RecentlyViewedResponse lists the minifigs a user viewed recently, newest first
+type RecentlyViewedResponse struct {
+	Minifigs []RecentMinifig `json:"minifigs"`
+}
+
+// RotateBricklinkCredentialsRequest carries the new BrickLink OAuth credentials, all four are replaced together
+type RotateBricklinkCredentialsRequest struct {
+	ConsumerKey       string `json:"consumer_key" validate:"required"`
+	ConsumerSecret    string `json:"consumer_secret" validate:"required"`
+	AccessToken       string `json:"access_token" validate:"required"`
+	AccessTokenSecret string `json:"access_token_secret" validate:"required"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"time"
+)
+
+// PortfolioHistoryPoint represents one portfolio value snapshot in API responses
+type PortfolioHistoryPoint struct {
+	TakenAt           time.Time `json:"taken_at"`
+	Currency          string    `json:"currency"`
+	TotalValue        float64   `json:"total_value"`
+	TotalPurchaseCost float64   `json:"total_purchase_cost"`
+	ItemCount         int       `json:"item_count"`
+	UnpricedCount     int       `json:"unpriced_count"`
+}
+
+// PortfolioHistoryResponse represents the value history of a portfolio, oldest point first
+type PortfolioHistoryResponse struct {
+	Points []PortfolioHistoryPoint `json:"points"`
+}
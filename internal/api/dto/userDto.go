@@ -6,40 +6,87 @@ import (
 
 // CreateUserRequest represents the request body for creating a user
 type CreateUserRequest struct {
-	Username  string `json:"username"`
-	Password  string `json:"password"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	Username          string `json:"username" validate:"required,min=3,max=50,username"`
+	Password          string `json:"password" validate:"required,min=8,max=72,password"`
+	FirstName         string `json:"first_name" validate:"required,max=100"`
+	LastName          string `json:"last_name" validate:"required,max=100"`
+	PreferredCurrency string `json:"preferred_currency" validate:"omitempty,iso4217"`
+	Timezone          string `json:"timezone" validate:"omitempty,timezone"`
+	Email             string `json:"email" validate:"omitempty,max=255,emailaddr"`
+}
+
+// SyncUserRequest represents the request body for syncing a user from an external system
+// Syncing is idempotent, repeating it with the same external id updates that user instead of creating another
+type SyncUserRequest struct {
+	CreateUserRequest
+	ExternalID string `json:"external_id" validate:"required,max=255"`
 }
 
 // UpdateUserRequest represents the request body for updating a user
+// Optional fields that are omitted keep their current value, Timezone and Email are cleared with ""
 type UpdateUserRequest struct {
-	Username  string `json:"username"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	Username          string  `json:"username" validate:"required,min=3,max=50,username"`
+	FirstName         string  `json:"first_name" validate:"required,max=100"`
+	LastName          string  `json:"last_name" validate:"required,max=100"`
+	PreferredCurrency string  `json:"preferred_currency" validate:"omitempty,iso4217"`
+	Timezone          *string `json:"timezone" validate:"omitempty,timezone"`
+	Email             *string `json:"email" validate:"omitempty,max=255,emailaddr"`
 }
 
 // UpdatePasswordRequest represents the request body for updating a password
 type UpdatePasswordRequest struct {
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password"`
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72,password"`
 }
 
 // UserResponse represents a user in API responses
 type UserResponse struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	FullName  string    `json:"full_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                int64     `json:"id"`
+	Username          string    `json:"username"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	FullName          string    `json:"full_name"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	PreferredCurrency string    `json:"preferred_currency"`
+	Timezone          string    `json:"timezone,omitempty"`
+}
+
+// AccountResponse represents a user to themselves, adding the private fields no other caller may see
+type AccountResponse struct {
+	UserResponse
+	Email      string `json:"email,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
 }
 
 // ListUsersResponse represents a paginated list of users
+// NextCursor is only set in cursor mode while more pages follow
 type ListUsersResponse struct {
-	Users  []UserResponse `json:"users"`
-	Total  int            `json:"total"`
-	Limit  int            `json:"limit"`
-	Offset int            `json:"offset"`
+	Users      []UserResponse `json:"users"`
+	Total      int            `json:"total"`
+	Limit      int            `json:"limit"`
+	Offset     int            `json:"offset"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LoginResponse carries the token issued on a successful login
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ImpersonationResponse carries a short-lived token an admin uses to act as a user
+type ImpersonationResponse struct {
+	Token          string    `json:"token"`
+	TokenType      string    `json:"token_type"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	UserID         int64     `json:"user_id"`
+	ImpersonatedBy int64     `json:"impersonated_by"`
 }
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+)
+
+// CreateWishlistItemRequest represents the request body for adding an item to a wishlist
+// Condition defaults to N (new), without a target price the item is only tracked
+type CreateWishlistItemRequest struct {
+	ItemType    string   `json:"item_type" validate:"required,itemtype"`
+	ItemID      string   `json:"item_id" validate:"required,max=50"`
+	Condition   string   `json:"condition" validate:"omitempty,oneof=N U"`
+	TargetPrice *float64 `json:"target_price" validate:"omitempty,gt=0"`
+}
+
+// UpdateWishlistItemRequest represents the request body for updating a wishlist item, the item itself can't change
+type UpdateWishlistItemRequest struct {
+	Condition   string   `json:"condition" validate:"required,oneof=N U"`
+	TargetPrice *float64 `json:"target_price" validate:"omitempty,gt=0"`
+}
+
+// WishlistItemResponse represents a wishlist item in API responses
+type WishlistItemResponse struct {
+	ID          int64     `json:"id"`
+	ItemType    string    `json:"item_type"`
+	ItemID      string    `json:"item_id"`
+	Condition   string    `json:"condition"`
+	TargetPrice *float64  `json:"target_price,omitempty"`
+	AddedAt     time.Time `json:"added_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WishlistResponse lists a user's wishlist, most recently added first
+type WishlistResponse struct {
+	Items []WishlistItemResponse `json:"items"`
+}
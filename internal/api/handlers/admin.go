@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/auth"
+	"LegoManagerAPI/internal/repos"
+)
+
+// AdminHandler serves the support endpoints, routes must be wrapped by RequireAuth and RequireAdmin
+type AdminHandler struct {
+	userExists func(ctx context.Context, id int64) (bool, error)
+	tokens     *auth.TokenManager
+}
+
+func NewAdminHandler(userRepo *repos.UserRepository, tokens *auth.TokenManager) *AdminHandler {
+	return &AdminHandler{
+		userExists: userRepo.Exists,
+		tokens:     tokens,
+	}
+}
+
+// Impersonate handles POST /api/admin/impersonate/{userId}
+// The returned token acts as the user but carries the admin's id so its use can be audited
+func (h *AdminHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/admin/impersonate/")
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	adminID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Missing bearer token")
+		return
+	}
+	if adminID == userID {
+		response.Error(w, http.StatusBadRequest, "Cannot impersonate yourself")
+		return
+	}
+
+	exists, err := h.userExists(ctx, userID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to look up user")
+		return
+	}
+	if !exists {
+		response.Error(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	token, expiresAt, err := h.tokens.IssueImpersonation(userID, adminID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to issue token")
+		return
+	}
+
+	log.Info("Audit: user impersonated", "actor_id", adminID, "impersonated_id", userID, "expires_at", expiresAt)
+
+	response.JSON(w, http.StatusOK, dto.ImpersonationResponse{
+		Token:          token,
+		TokenType:      "Bearer",
+		ExpiresAt:      expiresAt,
+		UserID:         userID,
+		ImpersonatedBy: adminID,
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/auth"
+	authConfig "LegoManagerAPI/internal/config/auth"
+)
+
+func impersonate(h *AdminHandler, adminID int64, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	rec := httptest.NewRecorder()
+	h.Impersonate(rec, req.WithContext(middleware.WithUserID(req.Context(), adminID)))
+	return rec
+}
+
+func TestImpersonate(t *testing.T) {
+	tokens := auth.NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour, ImpersonationTTL: 15 * time.Minute})
+	h := &AdminHandler{
+		userExists: func(ctx context.Context, id int64) (bool, error) {
+			return id == 7, nil
+		},
+		tokens: tokens,
+	}
+
+	t.Run("issues token acting as the user", func(t *testing.T) {
+		rec := impersonate(h, 1, "/api/admin/impersonate/7")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp dto.ImpersonationResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, int64(7), resp.UserID)
+		assert.Equal(t, int64(1), resp.ImpersonatedBy)
+
+		claims, err := tokens.Parse(resp.Token)
+		require.NoError(t, err)
+		assert.Equal(t, auth.Claims{UserID: 7, ImpersonatedBy: 1}, claims)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, impersonate(h, 1, "/api/admin/impersonate/8").Code)
+	})
+
+	t.Run("self", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, impersonate(h, 7, "/api/admin/impersonate/7").Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, impersonate(h, 1, "/api/admin/impersonate/abc").Code)
+	})
+}
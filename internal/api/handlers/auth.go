@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/request"
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/auth"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+// invalidCredentials is returned for both unknown usernames and wrong passwords so logins can't probe for accounts
+const invalidCredentials = "Invalid username or password"
+
+type AuthHandler struct {
+	findUser func(ctx context.Context, username string) (*models.User, error)
+	tokens   *auth.TokenManager
+
+	// dummyPasswordHash is compared against when the username is unknown, keeping the response time close to a real check
+	dummyPasswordHash []byte
+}
+
+// NewAuthHandler creates the auth handler, bcryptCost is the cost user passwords are hashed with
+// so a login for an unknown username takes as long as one with a wrong password
+func NewAuthHandler(userRepo *repos.UserRepository, tokens *auth.TokenManager, bcryptCost int) *AuthHandler {
+	// The configured cost is already range checked, so this can't fail
+	dummyPasswordHash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcryptCost)
+
+	return &AuthHandler{
+		findUser:          userRepo.FindByUsername,
+		tokens:            tokens,
+		dummyPasswordHash: dummyPasswordHash,
+	}
+}
+
+// Login handles POST /api/auth/login
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var req dto.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
+		return
+	}
+
+	user, err := h.findUser(ctx, req.Username)
+	if errors.Is(err, repos.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(h.dummyPasswordHash, []byte(req.Password))
+		response.Error(w, http.StatusUnauthorized, invalidCredentials)
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to look up user")
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		response.Error(w, http.StatusUnauthorized, invalidCredentials)
+		return
+	}
+
+	token, expiresAt, err := h.tokens.Issue(user.ID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to issue token")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, dto.LoginResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/auth"
+	authConfig "LegoManagerAPI/internal/config/auth"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+func newTestAuthHandler(t *testing.T) (*AuthHandler, *auth.TokenManager) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("supersecret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tokens := auth.NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour})
+	h := &AuthHandler{
+		findUser: func(ctx context.Context, username string) (*models.User, error) {
+			if username != "alice" {
+				return nil, repos.ErrUserNotFound
+			}
+			return &models.User{BaseModel: models.BaseModel{ID: 7}, Username: "alice", PasswordHash: string(hash)}, nil
+		},
+		tokens:            tokens,
+		dummyPasswordHash: hash,
+	}
+
+	return h, tokens
+}
+
+func TestNewAuthHandler_DummyHashUsesConfiguredCost(t *testing.T) {
+	h := NewAuthHandler(&repos.UserRepository{}, nil, bcrypt.MinCost+1)
+
+	cost, err := bcrypt.Cost(h.dummyPasswordHash)
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+}
+
+func login(h *AuthHandler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.Login(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body)))
+	return rec
+}
+
+func TestLogin_IssuesToken(t *testing.T) {
+	h, tokens := newTestAuthHandler(t)
+
+	rec := login(h, `{"username":"alice","password":"supersecret"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.LoginResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Bearer", resp.TokenType)
+
+	claims, err := tokens.Parse(resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), claims.UserID)
+}
+
+func TestLogin_SameErrorForUnknownUserAndWrongPassword(t *testing.T) {
+	h, _ := newTestAuthHandler(t)
+
+	wrongPassword := login(h, `{"username":"alice","password":"nope"}`)
+	unknownUser := login(h, `{"username":"mallory","password":"supersecret"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, wrongPassword.Code)
+	assert.Equal(t, http.StatusUnauthorized, unknownUser.Code)
+	assert.JSONEq(t, wrongPassword.Body.String(), unknownUser.Body.String())
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/api/response"
+)
+
+// Authorization policy: a resource the caller isn't allowed to see is reported exactly like a missing one,
+// a 404 with the resource's usual "not found" message and never a 403. Check ownership before loading the
+// resource so existing-but-unowned and missing ids can't be told apart by status, body or timing.
+
+// requireOwner responds 404 with notFoundCode and notFoundMessage and returns false unless the authenticated user is ownerID
+func requireOwner(w http.ResponseWriter, r *http.Request, ownerID int64, notFoundCode response.Code, notFoundMessage string) bool {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userID != ownerID {
+		response.ErrorCode(w, http.StatusNotFound, notFoundCode, notFoundMessage)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"LegoManagerAPI/internal/api/middleware"
+)
+
+func TestUserMutations_UnownedIDIsNotFound(t *testing.T) {
+	// A nil repo proves ownership is checked before the user is looked up
+	h := NewUserHandler(nil, bcrypt.MinCost, nil)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		path    string
+		body    string
+	}{
+		{"update", h.UpdateUser, http.MethodPut, "/api/users/2", `{"username":"mallory","first_name":"M","last_name":"M"}`},
+		{"delete", h.DeleteUser, http.MethodDelete, "/api/users/2", ""},
+		{"password", h.UpdatePassword, http.MethodPost, "/api/users/2/password", `{"old_password":"x","new_password":"supersecret"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = req.WithContext(middleware.WithUserID(req.Context(), 1))
+			rec := httptest.NewRecorder()
+
+			tt.handler(rec, req)
+
+			assert.Equal(t, http.StatusNotFound, rec.Code, "unowned ids must look missing, not forbidden")
+			assert.JSONEq(t, `{"code":"USER_NOT_FOUND","error":"User not found"}`, rec.Body.String())
+		})
+	}
+}
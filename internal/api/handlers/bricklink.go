@@ -2,27 +2,64 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/log"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/api/request"
 	"LegoManagerAPI/internal/api/response"
 	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
 )
 
 type BricklinkHandler struct {
-	bricklinkService *service.BricklinkService
+	bricklinkClient *service.CachedBricklinkClient
+	recent          *service.RecentlyViewed
+
+	// findUser looks up the logged in user for their preferred currency, nil prices in USD by default
+	findUser func(ctx context.Context, id int64) (*models.User, error)
+}
+
+// NewBricklinkHandler creates the BrickLink handler, a nil client means BrickLink is not configured
+// and every route answers 503. Minifigs fetched by logged in users are recorded in recent and prices
+// default to their preferred currency, looked up in userRepo
+func NewBricklinkHandler(bricklinkClient *service.CachedBricklinkClient, recent *service.RecentlyViewed, userRepo *repos.UserRepository) *BricklinkHandler {
+	h := &BricklinkHandler{
+		bricklinkClient: bricklinkClient,
+		recent:          recent,
+	}
+	if userRepo != nil {
+		h.findUser = userRepo.FindByID
+	}
+	return h
 }
 
-func NewBricklinkHandler(bricklinkService *service.BricklinkService) *BricklinkHandler {
-	return &BricklinkHandler{
-		bricklinkService: bricklinkService,
+// configured responds 503 when BrickLink is not configured and reports whether the request can go on
+func (h *BricklinkHandler) configured(w http.ResponseWriter) bool {
+	if h.bricklinkClient == nil {
+		response.Error(w, http.StatusServiceUnavailable, "BrickLink integration not configured")
+		return false
 	}
+	return true
 }
 
-// GetMinifig handles GET /api/bricklink/minifig/{id}
+// GetMinifig handles GET /api/bricklink/minifig/{id}?currency=EUR&condition=U
 func (h *BricklinkHandler) GetMinifig(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
@@ -33,15 +70,349 @@ func (h *BricklinkHandler) GetMinifig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query, ok := h.priceQuery(ctx, w, r)
+	if !ok {
+		return
+	}
+
 	// Fetch complete minifig data
-	data, err := h.bricklinkService.GetMinifigComplete(ctx, minifigID)
+	data, err := h.bricklinkClient.MinifigComplete(ctx, minifigID, query)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch minifig data: %v", err))
+		bricklinkError(w, err, "Failed to fetch minifig data")
 		return
 	}
 
-	// Convert to structured response
-	structuredResponse := data.ToStructuredResponse()
+	h.recordView(r, minifigID)
+
+	// Convert to structured response, resolving names from the in-memory catalog
+	structuredResponse := data.ToStructuredResponse(h.bricklinkClient.Catalog(ctx))
 
 	response.JSON(w, http.StatusOK, structuredResponse)
 }
+
+// GetSet handles GET /api/bricklink/set/{id}?currency=EUR&condition=both
+func (h *BricklinkHandler) GetSet(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	// BrickLink set numbers carry a variant suffix, e.g. 75192-1
+	setID := strings.TrimPrefix(r.URL.Path, "/api/bricklink/set/")
+	if setID == "" {
+		response.Error(w, http.StatusBadRequest, "Set ID is required")
+		return
+	}
+
+	query, ok := h.priceQuery(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	data, err := h.bricklinkClient.SetComplete(ctx, setID, query)
+	if err != nil {
+		bricklinkError(w, err, "Failed to fetch set data")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, data.ToStructuredResponse(h.bricklinkClient.Catalog(ctx)))
+}
+
+// GetItem handles GET /api/bricklink/items/{type}/{id} for any enabled item type, e.g. /api/bricklink/items/part/3001
+// Sets are returned with their contents split like GetSet, other types like GetMinifig
+func (h *BricklinkHandler) GetItem(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	typeStr, itemID, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/bricklink/items/"), "/")
+
+	itemType, err := h.bricklinkClient.Service().ParseItemType(typeStr)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, unsupportedItemType(typeStr, h.bricklinkClient.Service().SupportedItemTypes()))
+		return
+	}
+
+	if itemID == "" {
+		response.Error(w, http.StatusBadRequest, "Item ID is required")
+		return
+	}
+
+	query, ok := h.priceQuery(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	data, err := h.bricklinkClient.ItemComplete(ctx, itemType, itemID, query)
+	if err != nil {
+		bricklinkError(w, err, "Failed to fetch item data")
+		return
+	}
+
+	catalog := h.bricklinkClient.Catalog(ctx)
+	if itemType == service.ItemTypeSet {
+		response.JSON(w, http.StatusOK, (*service.SetComplete)(data).ToStructuredResponse(catalog))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, data.ToItemResponse(itemType, catalog))
+}
+
+// unsupportedItemType builds the 400 message listing the enabled item types
+func unsupportedItemType(itemType string, supported []service.ItemType) string {
+	names := make([]string, len(supported))
+	for i, t := range supported {
+		names[i] = string(t)
+	}
+	return fmt.Sprintf("Unsupported item type %q, supported values: %s", itemType, strings.Join(names, ", "))
+}
+
+// priceCurrencies are the currencies prices may be requested in
+var priceCurrencies = []string{"USD", "EUR", "GBP", "CAD", "AUD", "CHF"}
+
+// priceQueryParams reads ?currency= and ?condition= (N, U or both), defaulting to new prices in USD
+// Responds 400 and returns false for unsupported values
+func priceQueryParams(w http.ResponseWriter, r *http.Request) (service.PriceQuery, bool) {
+	var query service.PriceQuery
+
+	switch condition := strings.ToUpper(r.URL.Query().Get("condition")); condition {
+	case "", "N", "U":
+		query.Condition = condition
+	case "BOTH":
+		query.BothConditions = true
+	default:
+		response.Error(w, http.StatusBadRequest, "Invalid condition, supported values: N, U, both")
+		return query, false
+	}
+
+	if currency := strings.ToUpper(r.URL.Query().Get("currency")); currency != "" {
+		if !slices.Contains(priceCurrencies, currency) {
+			response.Error(w, http.StatusBadRequest, "Invalid currency, supported values: "+strings.Join(priceCurrencies, ", "))
+			return query, false
+		}
+		query.Currency = currency
+	}
+
+	return query, true
+}
+
+// priceQuery is priceQueryParams, except that without ?currency= a logged in user gets their preferred currency
+// A preferred currency prices can't be requested in, or a failed lookup, keeps the USD default
+func (h *BricklinkHandler) priceQuery(ctx context.Context, w http.ResponseWriter, r *http.Request) (service.PriceQuery, bool) {
+	query, ok := priceQueryParams(w, r)
+	if !ok || query.Currency != "" || h.findUser == nil {
+		return query, ok
+	}
+
+	userID, authenticated := middleware.UserIDFromContext(r.Context())
+	if !authenticated {
+		return query, true
+	}
+
+	user, err := h.findUser(ctx, userID)
+	if err != nil {
+		log.Warn("Failed to look up preferred currency", "user_id", userID, "error", err)
+		return query, true
+	}
+	if slices.Contains(priceCurrencies, user.PreferredCurrency) {
+		query.Currency = user.PreferredCurrency
+	}
+
+	return query, true
+}
+
+// MinifigExists handles GET /api/bricklink/minifig/{id}/exists
+func (h *BricklinkHandler) MinifigExists(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	minifigID := strings.TrimPrefix(r.URL.Path, "/api/bricklink/minifig/")
+	minifigID = strings.TrimSuffix(minifigID, "/exists")
+	if minifigID == "" {
+		response.Error(w, http.StatusBadRequest, "Minifig ID is required")
+		return
+	}
+
+	info, err := h.bricklinkClient.MinifigExists(ctx, minifigID)
+	if errors.Is(err, service.ErrNotFound) {
+		response.JSON(w, http.StatusOK, dto.ItemExistsResponse{Exists: false})
+		return
+	}
+	if err != nil {
+		bricklinkError(w, err, "Failed to check minifig")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, dto.ItemExistsResponse{
+		Exists: true,
+		Name:   info.Name,
+	})
+}
+
+// GetColor handles GET /api/bricklink/colors/{id}, resolving a color id from the cached catalog
+func (h *BricklinkHandler) GetColor(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	colorID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/bricklink/colors/"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Color ID must be a number")
+		return
+	}
+
+	catalog := h.bricklinkClient.Catalog(ctx)
+	err = catalog.ValidateColorID(colorID)
+	if errors.Is(err, service.ErrUnknownColor) {
+		response.Error(w, http.StatusNotFound, fmt.Sprintf("Unknown BrickLink color %d", colorID))
+		return
+	}
+	if err != nil {
+		bricklinkError(w, err, "Failed to resolve color")
+		return
+	}
+
+	color, _ := catalog.Color(colorID)
+	response.JSON(w, http.StatusOK, color)
+}
+
+// RotateCredentials handles POST /api/admin/bricklink/credentials, must be wrapped by RequireAuth and RequireAdmin
+// The new credentials replace the running ones once a test call with them succeeded, no restart is needed
+func (h *BricklinkHandler) RotateCredentials(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var req dto.RotateBricklinkCredentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
+		return
+	}
+
+	adminID, _ := middleware.UserIDFromContext(r.Context())
+
+	// Only the outcome is logged, never the credentials themselves
+	err := h.bricklinkClient.Service().RotateCredentials(ctx, service.Credentials{
+		ConsumerKey:       req.ConsumerKey,
+		ConsumerSecret:    req.ConsumerSecret,
+		AccessToken:       req.AccessToken,
+		AccessTokenSecret: req.AccessTokenSecret,
+	})
+	if errors.Is(err, service.ErrBrickLinkAuth) {
+		log.Warn("Audit: BrickLink credential rotation rejected", "actor_id", adminID)
+		response.ErrorCode(w, http.StatusUnprocessableEntity, response.CodeCredentialsRejected, "BrickLink rejected the new credentials, the current ones stay in use")
+		return
+	}
+	if err != nil {
+		bricklinkError(w, err, "Failed to test new credentials")
+		return
+	}
+
+	log.Info("Audit: BrickLink credentials rotated", "actor_id", adminID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bricklinkError maps a BrickLink failure to a response, unexpected errors get a 500 prefixed with message
+func bricklinkError(w http.ResponseWriter, err error, message string) {
+	var rateErr *service.RateLimitError
+	switch {
+	case errors.Is(err, service.ErrBrickLinkAuth):
+		response.Error(w, http.StatusBadGateway, "BrickLink credentials rejected")
+	case errors.Is(err, service.ErrEndpointDisabled):
+		response.Error(w, http.StatusServiceUnavailable, "BrickLink lookup temporarily disabled")
+	case errors.Is(err, service.ErrCatalogUnavailable):
+		response.Error(w, http.StatusServiceUnavailable, "BrickLink color catalog unavailable, try again later")
+	case errors.As(err, &rateErr):
+		rateLimited(w, rateErr)
+	default:
+		response.Error(w, http.StatusInternalServerError, fmt.Sprintf("%s: %v", message, err))
+	}
+}
+
+// recordView adds minifigID to the recently viewed list of the logged in user
+// Anonymous requests and support staff impersonating the user are not recorded
+func (h *BricklinkHandler) recordView(r *http.Request, minifigID string) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || h.recent == nil {
+		return
+	}
+	if _, impersonated := middleware.ImpersonatorFromContext(r.Context()); impersonated {
+		return
+	}
+
+	h.recent.Record(userID, minifigID)
+}
+
+// RecentlyViewed handles GET /api/users/me/recent?limit=10, the minifigs the logged in user fetched last
+// Must be wrapped by RequireAuth
+func (h *BricklinkHandler) RecentlyViewed(w http.ResponseWriter, r *http.Request) {
+	if !h.configured(w) {
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Missing bearer token")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	limit := h.recent.Limit()
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l < limit {
+		limit = l
+	}
+
+	ids, err := h.recent.List(ctx, userID, limit)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to load recently viewed minifigs")
+		return
+	}
+
+	// Basic info comes from the BrickLink cache, these minifigs were fetched moments ago
+	minifigs := make([]dto.RecentMinifig, 0, len(ids))
+	for _, id := range ids {
+		minifig := dto.RecentMinifig{ID: id}
+		if info, err := h.bricklinkClient.Minifig(ctx, id); err == nil {
+			minifig.Name = info.Name
+			minifig.ThumbnailURL = info.ThumbnailURL
+		} else {
+			log.Debug("Recently viewed minifig info unavailable", "minifig_id", id, "error", err)
+		}
+		minifigs = append(minifigs, minifig)
+	}
+
+	response.JSON(w, http.StatusOK, dto.RecentlyViewedResponse{Minifigs: minifigs})
+}
+
+// rateLimited responds 429 with a Retry-After header in whole seconds, rounded up
+func rateLimited(w http.ResponseWriter, err *service.RateLimitError) {
+	seconds := int64(math.Ceil(err.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	response.Error(w, http.StatusTooManyRequests, "BrickLink rate limit reached, try again later")
+}
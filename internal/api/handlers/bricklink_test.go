@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/config/bricklink"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+func TestRateLimited_SetsRetryAfter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rateLimited(rec, &service.RateLimitError{RetryAfter: 1500 * time.Millisecond})
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+}
+
+func TestPriceQueryParams(t *testing.T) {
+	tests := []struct {
+		query  string
+		want   service.PriceQuery
+		wantOK bool
+	}{
+		{"", service.PriceQuery{}, true},
+		{"?currency=eur&condition=U", service.PriceQuery{Currency: "EUR", Condition: "U"}, true},
+		{"?condition=both", service.PriceQuery{BothConditions: true}, true},
+		{"?currency=XYZ", service.PriceQuery{}, false},
+		{"?condition=mint", service.PriceQuery{}, false},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		got, ok := priceQueryParams(rec, httptest.NewRequest(http.MethodGet, "/api/bricklink/minifig/sw0001"+tt.query, nil))
+
+		assert.Equal(t, tt.wantOK, ok, tt.query)
+		if tt.wantOK {
+			assert.Equal(t, tt.want, got, tt.query)
+		} else {
+			assert.Equal(t, http.StatusBadRequest, rec.Code, tt.query)
+		}
+	}
+}
+
+func TestPriceQuery_DefaultsToPreferredCurrency(t *testing.T) {
+	currencies := map[int64]string{1: "EUR", 2: "JPY"}
+	h := &BricklinkHandler{findUser: func(ctx context.Context, id int64) (*models.User, error) {
+		currency, ok := currencies[id]
+		if !ok {
+			return nil, repos.ErrUserNotFound
+		}
+		return &models.User{BaseModel: models.BaseModel{ID: id}, PreferredCurrency: currency}, nil
+	}}
+
+	tests := []struct {
+		name   string
+		userID int64
+		query  string
+		want   string
+	}{
+		{"anonymous", 0, "", ""},
+		{"preferred", 1, "", "EUR"},
+		{"explicit wins", 1, "?currency=gbp", "GBP"},
+		{"unsupported preference", 2, "", ""},
+		{"failed lookup", 3, "", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/bricklink/minifig/sw0001"+tt.query, nil)
+		if tt.userID != 0 {
+			req = req.WithContext(middleware.WithUserID(req.Context(), tt.userID))
+		}
+
+		got, ok := h.priceQuery(req.Context(), httptest.NewRecorder(), req)
+		assert.True(t, ok, tt.name)
+		assert.Equal(t, tt.want, got.Currency, tt.name)
+	}
+}
+
+func TestGetItem_UnsupportedType(t *testing.T) {
+	bricklinkService := service.NewBricklinkService(bricklink.BricklinkConfig{EnabledItemTypes: []string{"MINIFIG", "SET"}})
+	h := NewBricklinkHandler(service.NewCachedBricklinkClient(bricklinkService, nil, time.Hour, 1), nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.GetItem(rec, httptest.NewRequest(http.MethodGet, "/api/bricklink/items/part/3001", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"code":"UNKNOWN","error":"Unsupported item type \"part\", supported values: MINIFIG, SET"}`, rec.Body.String())
+}
+
+func TestBricklinkHandler_NotConfigured(t *testing.T) {
+	h := NewBricklinkHandler(nil, nil, nil)
+
+	routes := map[string]http.HandlerFunc{
+		"/api/bricklink/minifig/sw0001":        h.GetMinifig,
+		"/api/bricklink/minifig/sw0001/exists": h.MinifigExists,
+		"/api/bricklink/set/75192-1":           h.GetSet,
+		"/api/bricklink/items/minifig/sw0001":  h.GetItem,
+		"/api/bricklink/colors/11":             h.GetColor,
+		"/api/admin/bricklink/credentials":     h.RotateCredentials,
+	}
+
+	for path, handle := range routes {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handle(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+			assert.JSONEq(t, `{"code":"UNKNOWN","error":"BrickLink integration not configured"}`, rec.Body.String())
+		})
+	}
+}
+
+func TestGetColor_InvalidID(t *testing.T) {
+	bricklinkService := service.NewBricklinkService(bricklink.BricklinkConfig{EnabledItemTypes: []string{"MINIFIG", "SET"}})
+	h := NewBricklinkHandler(service.NewCachedBricklinkClient(bricklinkService, nil, time.Hour, 1), nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.GetColor(rec, httptest.NewRequest(http.MethodGet, "/api/bricklink/colors/black", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"code":"UNKNOWN","error":"Color ID must be a number"}`, rec.Body.String())
+}
+
+func TestRotateCredentials_RequiresAllFields(t *testing.T) {
+	bricklinkService := service.NewBricklinkService(bricklink.BricklinkConfig{EnabledItemTypes: []string{"MINIFIG", "SET"}})
+	h := NewBricklinkHandler(service.NewCachedBricklinkClient(bricklinkService, nil, time.Hour, 1), nil, nil)
+
+	rec := httptest.NewRecorder()
+	body := strings.NewReader(`{"consumer_key":"key","consumer_secret":"secret","access_token":"token"}`)
+	h.RotateCredentials(rec, httptest.NewRequest(http.MethodPost, "/api/admin/bricklink/credentials", body))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"access_token_secret"`)
+}
@@ -33,3 +33,53 @@ func (h *HealthHandler) Handle(res http.ResponseWriter, req *http.Request) {
 
 	response.JSON(res, statusCode, healthResponse)
 }
+
+// HandleDetailed serves /health/detailed, the health check plus per-dependency diagnostics for dashboards
+func (h *HealthHandler) HandleDetailed(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	healthResponse := h.healthService.CheckAllDetailed(ctx)
+
+	statusCode := http.StatusOK
+	if healthResponse.Status != "healthy" {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	response.JSON(res, statusCode, healthResponse)
+}
+
+// LiveResponse is the body of /health/live, it never carries details
+type LiveResponse struct {
+	Status string `json:"status"`
+}
+
+// HandleLive serves /health/live for liveness probes, it only runs the liveness checks and never reports
+// which one failed, so it is safe to leave open
+func (h *HealthHandler) HandleLive(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if h.healthService.CheckLiveness(ctx).Status != "healthy" {
+		response.JSON(res, http.StatusServiceUnavailable, LiveResponse{Status: "unhealthy"})
+		return
+	}
+
+	response.JSON(res, http.StatusOK, LiveResponse{Status: "ok"})
+}
+
+// HandleReady serves /health/ready for readiness probes, it runs the dependency checks and
+// returns 503 while any of them is down
+func (h *HealthHandler) HandleReady(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	healthResponse := h.healthService.CheckReadiness(ctx)
+
+	statusCode := http.StatusOK
+	if healthResponse.Status != "healthy" {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	response.JSON(res, statusCode, healthResponse)
+}
@@ -2,41 +2,172 @@ package health
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/sync/singleflight"
 )
 
 // Service orchestrates multiple health checks
 type Service struct {
 	checkers    []Checker
+	names       []string // Result key per checker, unique even when checker names repeat
 	environment string
+
+	// cacheTTL is how long CheckAll, CheckLiveness and CheckReadiness reuse their last response, 0 runs the checks on every call
+	cacheTTL time.Duration
+	refresh  singleflight.Group
+	mu       sync.Mutex
+	cached   map[Probe]cachedResponse
+
+	// checkTimeout bounds each checker on its own so one hanging dependency can't hold up the others, 0 disables it
+	checkTimeout time.Duration
+}
+
+// cachedResponse is the last response of a probe and when it was checked
+type cachedResponse struct {
+	response  Response
+	checkedAt time.Time
 }
 
 // NewService creates a new health check service
+// Checkers sharing a name are reported as name, name-2, name-3... so no result overwrites another
 func NewService(environment string, checkers ...Checker) *Service {
 	return &Service{
 		checkers:    checkers,
+		names:       uniqueNames(checkers),
 		environment: environment,
+		cached:      make(map[Probe]cachedResponse),
+	}
+}
+
+// CacheResults makes CheckAll, CheckLiveness and CheckReadiness reuse its last response for ttl instead of running every check on each call
+// Concurrent calls after it expires share a single refresh, 0 disables the cache
+func (s *Service) CacheResults(ttl time.Duration) {
+	s.cacheTTL = ttl
+}
+
+// TimeoutChecks gives each checker its own timeout, one that takes longer is reported as unhealthy
+// with a "timeout" error instead of delaying the whole response, 0 disables it
+func (s *Service) TimeoutChecks(timeout time.Duration) {
+	s.checkTimeout = timeout
+}
+
+// uniqueNames returns each checker's name, the first checker keeps it and later ones get the next free -N suffix
+func uniqueNames(checkers []Checker) []string {
+	names := make([]string, len(checkers))
+
+	// Reserve every real name first so a suffixed duplicate never takes another checker's name
+	taken := make(map[string]bool, len(checkers))
+	for _, checker := range checkers {
+		taken[checker.Name()] = true
+	}
+
+	kept := make(map[string]bool, len(checkers))
+	for i, checker := range checkers {
+		name := checker.Name()
+		if !kept[name] {
+			kept[name] = true
+			names[i] = name
+			continue
+		}
+
+		unique := name
+		for n := 2; taken[unique]; n++ {
+			unique = fmt.Sprintf("%s-%d", name, n)
+		}
+		log.Warn("Duplicate health checker name, reporting under a suffixed name", "name", name, "reported_as", unique)
+
+		taken[unique] = true
+		names[i] = unique
 	}
+
+	return names
 }
 
-// CheckAll runs all ehalth checks concurently
+// CheckAll runs all ehalth checks concurently, or returns the cached response while it is fresh
 func (s *Service) CheckAll(ctx context.Context) Response {
+	return s.cachedCheck(ctx, allProbes)
+}
+
+// CheckLiveness runs only the liveness checks, the ones telling whether the process itself works
+func (s *Service) CheckLiveness(ctx context.Context) Response {
+	return s.cachedCheck(ctx, Liveness)
+}
+
+// CheckReadiness runs only the readiness checks, the dependencies the API needs to serve traffic
+func (s *Service) CheckReadiness(ctx context.Context) Response {
+	return s.cachedCheck(ctx, Readiness)
+}
+
+// CheckAllDetailed runs all health checks like CheckAll and adds the diagnostics of every DetailedChecker
+// It is never cached
+func (s *Service) CheckAllDetailed(ctx context.Context) Response {
+	return s.check(ctx, allProbes, true)
+}
+
+// cachedCheck runs the checks of probe, or returns the cached response for it while it is fresh
+func (s *Service) cachedCheck(ctx context.Context, probe Probe) Response {
+	if s.cacheTTL <= 0 {
+		return s.check(ctx, probe, false)
+	}
+
+	if resp, ok := s.cachedResponse(probe); ok {
+		return resp
+	}
+
+	// Only one caller refreshes, the others wait for and share its response
+	resp, _, _ := s.refresh.Do(string(probe), func() (any, error) {
+		if resp, ok := s.cachedResponse(probe); ok {
+			return resp, nil
+		}
+
+		resp := s.check(ctx, probe, false)
+
+		s.mu.Lock()
+		s.cached[probe] = cachedResponse{response: resp, checkedAt: time.Now()}
+		s.mu.Unlock()
+
+		return resp, nil
+	})
+	return resp.(Response)
+}
+
+// cachedResponse returns the last response for probe if it is younger than the cache TTL
+func (s *Service) cachedResponse(probe Probe) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, ok := s.cached[probe]
+	if !ok || time.Since(cached.checkedAt) >= s.cacheTTL {
+		return Response{}, false
+	}
+	return cached.response, true
+}
+
+// check runs the checkers belonging to probe concurrently, allProbes runs every checker
+func (s *Service) check(ctx context.Context, probe Probe, detailed bool) Response {
 	services := make(map[string]Status)
 	mu := sync.Mutex{}
 	wg := sync.WaitGroup{}
 
 	// Run all checks concurrently
-	for _, checker := range s.checkers {
+	for i, checker := range s.checkers {
+		if probe != allProbes && probeOf(checker) != probe {
+			continue
+		}
+
 		wg.Add(1)
-		go func(checker Checker) {
+		go func(name string, checker Checker) {
 			defer wg.Done()
-			status := checker.Check(ctx)
+			status := s.runChecker(ctx, checker, detailed)
 
 			mu.Lock()
-			services[checker.Name()] = status
+			services[name] = status
 			mu.Unlock()
-		}(checker)
+		}(s.names[i], checker)
 	}
 
 	wg.Wait()
@@ -57,3 +188,39 @@ func (s *Service) CheckAll(ctx context.Context) Response {
 		Services:    services,
 	}
 }
+
+// runChecker runs one checker under its own timeout, a checker that does not finish in time is reported
+// as unhealthy right away and left to return in the background
+func (s *Service) runChecker(ctx context.Context, checker Checker, detailed bool) Status {
+	run := func(ctx context.Context) Status {
+		status := checker.Check(ctx)
+		if detailer, ok := checker.(DetailedChecker); ok && detailed {
+			status.Details = detailer.Details(ctx)
+		}
+		return status
+	}
+
+	if s.checkTimeout <= 0 {
+		return run(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout)
+	defer cancel()
+
+	// Buffered so a checker ignoring its context can still finish after it was given up on
+	result := make(chan Status, 1)
+	go func() {
+		result <- run(ctx)
+	}()
+
+	select {
+	case status := <-result:
+		return status
+	case <-ctx.Done():
+		return Status{
+			Status:  "unhealthy",
+			Latency: s.checkTimeout.String(),
+			Error:   "timeout",
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package health_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/api/handlers/health"
+)
+
+// fakeChecker reports a fixed status after an optional delay
+type fakeChecker struct {
+	name   string
+	delay  time.Duration
+	status string
+}
+
+func (f *fakeChecker) Name() string {
+	return f.name
+}
+
+func (f *fakeChecker) Check(ctx context.Context) health.Status {
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	return health.Status{Status: f.status, Latency: f.delay.String()}
+}
+
+func TestService_CheckAll_ManyConcurrentCheckers(t *testing.T) {
+	const count = 200
+
+	checkers := make([]health.Checker, 0, count)
+	for i := 0; i < count; i++ {
+		var delay time.Duration
+		if i%2 == 0 {
+			delay = time.Duration(i%10) * time.Millisecond
+		}
+		checkers = append(checkers, &fakeChecker{
+			name:   fmt.Sprintf("checker-%d", i),
+			delay:  delay,
+			status: "healthy",
+		})
+	}
+
+	service := health.NewService("test", checkers...)
+	resp := service.CheckAll(context.Background())
+
+	require.Len(t, resp.Services, count)
+	for _, checker := range checkers {
+		fake := checker.(*fakeChecker)
+		status, ok := resp.Services[fake.name]
+		require.True(t, ok, "missing result for %s", fake.name)
+		assert.Equal(t, "healthy", status.Status)
+		assert.Equal(t, fake.delay.String(), status.Latency)
+	}
+	assert.Equal(t, "healthy", resp.Status)
+	assert.Equal(t, "test", resp.Environment)
+}
+
+func TestService_CheckAll_OneUnhealthy(t *testing.T) {
+	checkers := []health.Checker{
+		&fakeChecker{name: "fast", status: "healthy"},
+		&fakeChecker{name: "slow", delay: 10 * time.Millisecond, status: "unhealthy"},
+	}
+
+	resp := health.NewService("test", checkers...).CheckAll(context.Background())
+
+	assert.Equal(t, "unhealthy", resp.Status)
+	assert.Equal(t, "unhealthy", resp.Services["slow"].Status)
+	assert.Equal(t, "healthy", resp.Services["fast"].Status)
+}
+
+func TestService_CheckAll_DuplicateNamesAreKept(t *testing.T) {
+	checkers := []health.Checker{
+		&fakeChecker{name: "bricklink", status: "healthy"},
+		&fakeChecker{name: "bricklink", status: "unhealthy"},
+		&fakeChecker{name: "bricklink-2", status: "healthy"},
+	}
+
+	resp := health.NewService("test", checkers...).CheckAll(context.Background())
+
+	require.Len(t, resp.Services, 3)
+	assert.Equal(t, "healthy", resp.Services["bricklink"].Status)
+	assert.Equal(t, "unhealthy", resp.Services["bricklink-3"].Status)
+	assert.Equal(t, "healthy", resp.Services["bricklink-2"].Status)
+	assert.Equal(t, "unhealthy", resp.Status, "a failing duplicate must not be masked")
+}
+
+// detailedChecker is a fakeChecker that also reports diagnostics
+type detailedChecker struct {
+	fakeChecker
+	details map[string]any
+}
+
+func (d *detailedChecker) Details(ctx context.Context) map[string]any {
+	return d.details
+}
+
+func TestService_CheckAllDetailed(t *testing.T) {
+	service := health.NewService("test",
+		&detailedChecker{fakeChecker: fakeChecker{name: "postgres", status: "healthy"}, details: map[string]any{"idle_conns": 3}},
+		&fakeChecker{name: "application", status: "healthy"},
+	)
+
+	resp := service.CheckAll(context.Background())
+	assert.Nil(t, resp.Services["postgres"].Details, "the plain health check skips diagnostics")
+
+	resp = service.CheckAllDetailed(context.Background())
+	assert.Equal(t, "healthy", resp.Status)
+	assert.Equal(t, map[string]any{"idle_conns": 3}, resp.Services["postgres"].Details)
+	assert.Nil(t, resp.Services["application"].Details)
+}
+
+// countingChecker counts how often it is run
+type countingChecker struct {
+	fakeChecker
+	calls atomic.Int32
+}
+
+func (c *countingChecker) Check(ctx context.Context) health.Status {
+	c.calls.Add(1)
+	return c.fakeChecker.Check(ctx)
+}
+
+func TestService_CheckAll_CachesResults(t *testing.T) {
+	checker := &countingChecker{fakeChecker: fakeChecker{name: "postgres", status: "healthy"}}
+	service := health.NewService("test", checker)
+	service.CacheResults(50 * time.Millisecond)
+
+	first := service.CheckAll(context.Background())
+	second := service.CheckAll(context.Background())
+	assert.Equal(t, int32(1), checker.calls.Load())
+	assert.Equal(t, first.Timestamp, second.Timestamp, "the cached response is returned as is")
+
+	time.Sleep(60 * time.Millisecond)
+	service.CheckAll(context.Background())
+	assert.Equal(t, int32(2), checker.calls.Load(), "an expired response is refreshed")
+
+	service.CheckAllDetailed(context.Background())
+	assert.Equal(t, int32(3), checker.calls.Load(), "detailed checks always run")
+}
+
+func TestService_CheckAll_ConcurrentRefreshRunsOnce(t *testing.T) {
+	checker := &countingChecker{fakeChecker: fakeChecker{name: "postgres", delay: 20 * time.Millisecond, status: "healthy"}}
+	service := health.NewService("test", checker)
+	service.CacheResults(time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "healthy", service.CheckAll(context.Background()).Status)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), checker.calls.Load())
+}
+
+func TestService_CheckAll_NoCache(t *testing.T) {
+	checker := &countingChecker{fakeChecker: fakeChecker{name: "postgres", status: "healthy"}}
+	service := health.NewService("test", checker)
+
+	service.CheckAll(context.Background())
+	service.CheckAll(context.Background())
+	assert.Equal(t, int32(2), checker.calls.Load())
+}
+
+// livenessChecker is a fakeChecker tagged as a liveness check
+type livenessChecker struct {
+	fakeChecker
+}
+
+func (l *livenessChecker) Probe() health.Probe {
+	return health.Liveness
+}
+
+func TestService_Probes(t *testing.T) {
+	service := health.NewService("test",
+		&livenessChecker{fakeChecker: fakeChecker{name: "application", status: "healthy"}},
+		&fakeChecker{name: "postgres", status: "unhealthy"},
+		&fakeChecker{name: "redis", status: "healthy"},
+	)
+
+	live := service.CheckLiveness(context.Background())
+	assert.Equal(t, "healthy", live.Status, "a dependency outage must not fail liveness")
+	assert.Len(t, live.Services, 1)
+	assert.Contains(t, live.Services, "application")
+
+	ready := service.CheckReadiness(context.Background())
+	assert.Equal(t, "unhealthy", ready.Status)
+	assert.Len(t, ready.Services, 2)
+	assert.NotContains(t, ready.Services, "application")
+
+	all := service.CheckAll(context.Background())
+	assert.Equal(t, "unhealthy", all.Status)
+	assert.Len(t, all.Services, 3)
+}
+
+func TestService_ProbesAreCachedSeparately(t *testing.T) {
+	ready := &countingChecker{fakeChecker: fakeChecker{name: "postgres", status: "healthy"}}
+	service := health.NewService("test", &livenessChecker{fakeChecker: fakeChecker{name: "application", status: "healthy"}}, ready)
+	service.CacheResults(time.Minute)
+
+	service.CheckReadiness(context.Background())
+	service.CheckReadiness(context.Background())
+	assert.Equal(t, int32(1), ready.calls.Load())
+
+	service.CheckLiveness(context.Background())
+	assert.Equal(t, int32(1), ready.calls.Load(), "liveness does not run readiness checks")
+
+	service.CheckAll(context.Background())
+	assert.Equal(t, int32(2), ready.calls.Load(), "the combined view has its own cache entry")
+}
+
+func TestService_CheckAll_TimesOutSlowChecker(t *testing.T) {
+	service := health.NewService("test",
+		&fakeChecker{name: "redis", delay: time.Second, status: "healthy"},
+		&fakeChecker{name: "postgres", status: "healthy"},
+	)
+	service.TimeoutChecks(20 * time.Millisecond)
+
+	start := time.Now()
+	resp := service.CheckAll(context.Background())
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "a hanging checker must not hold up the response")
+	assert.Equal(t, "unhealthy", resp.Status)
+	assert.Equal(t, "unhealthy", resp.Services["redis"].Status)
+	assert.Equal(t, "timeout", resp.Services["redis"].Error)
+	assert.Equal(t, "healthy", resp.Services["postgres"].Status)
+}
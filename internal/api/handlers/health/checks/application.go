@@ -17,6 +17,11 @@ func (a *ApplicationCheck) Name() string {
 	return "application"
 }
 
+// Probe marks the application check as a liveness check, it depends on nothing outside the process
+func (a *ApplicationCheck) Probe() health.Probe {
+	return health.Liveness
+}
+
 func (a *ApplicationCheck) Check(ctx context.Context) health.Status {
 	start := time.Now()
 
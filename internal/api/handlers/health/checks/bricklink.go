@@ -0,0 +1,46 @@
+package checks
+
+import (
+	"context"
+	"time"
+
+	"LegoManagerAPI/internal/api/handlers/health"
+	"LegoManagerAPI/internal/api/service"
+)
+
+// BricklinkCheck reports whether BrickLink accepted our credentials on the last call
+// It does not call the API itself so health polling doesn't consume the daily quota
+type BricklinkCheck struct {
+	service *service.BricklinkService
+}
+
+func NewBricklinkCheck(bricklinkService *service.BricklinkService) *BricklinkCheck {
+	return &BricklinkCheck{service: bricklinkService}
+}
+
+func (b *BricklinkCheck) Name() string {
+	return "bricklink"
+}
+
+func (b *BricklinkCheck) Check(ctx context.Context) health.Status {
+	start := time.Now()
+
+	if err := b.service.AuthStatus(); err != nil {
+		return health.Status{
+			Status: "unhealthy",
+			Error:  err.Error(),
+		}
+	}
+
+	return health.Status{
+		Status:  "healthy",
+		Latency: time.Since(start).String(),
+	}
+}
+
+// Details reports the estimated remaining daily request quota, -1 when there is no daily cap
+func (b *BricklinkCheck) Details(ctx context.Context) map[string]any {
+	return map[string]any{
+		"remaining_daily_quota": b.service.RemainingDailyQuota(ctx),
+	}
+}
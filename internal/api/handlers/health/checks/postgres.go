@@ -30,8 +30,34 @@ func (p *PostgresCheck) Check(ctx context.Context) health.Status {
 		}
 	}
 
+	// A reachable database can still fail real queries, run the configured query when there is one
+	if p.db.HasHealthQuery() {
+		if err := p.db.HealthQuery(ctx); err != nil {
+			return health.Status{
+				Status: "unhealthy",
+				Error:  err.Error(),
+			}
+		}
+	}
+
 	return health.Status{
 		Status:  "healthy",
 		Latency: time.Since(start).String(),
 	}
 }
+
+// Details reports the connection pool stats
+func (p *PostgresCheck) Details(ctx context.Context) map[string]any {
+	stats := p.db.Stats()
+
+	return map[string]any{
+		"total_conns":            stats.TotalConns(),
+		"idle_conns":             stats.IdleConns(),
+		"acquired_conns":         stats.AcquiredConns(),
+		"max_conns":              stats.MaxConns(),
+		"acquire_count":          stats.AcquireCount(),
+		"empty_acquire_count":    stats.EmptyAcquireCount(),
+		"canceled_acquire_count": stats.CanceledAcquireCount(),
+		"acquire_duration":       stats.AcquireDuration().String(),
+	}
+}
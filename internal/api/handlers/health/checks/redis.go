@@ -2,6 +2,7 @@ package checks
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"LegoManagerAPI/internal/api/handlers/health"
@@ -35,3 +36,38 @@ func (r *RedisCheck) Check(ctx context.Context) health.Status {
 		Latency: time.Since(start).String(),
 	}
 }
+
+// Details reports the key count and memory usage, entries Redis fails to report are left out
+func (r *RedisCheck) Details(ctx context.Context) map[string]any {
+	details := map[string]any{}
+
+	if keys, err := r.client.Client().DBSize(ctx).Result(); err == nil {
+		details["keys"] = keys
+	}
+
+	if info, err := r.client.Client().Info(ctx, "memory").Result(); err == nil {
+		fields := parseInfo(info)
+		for _, field := range []string{"used_memory", "used_memory_human", "used_memory_peak_human", "maxmemory_human"} {
+			if value, ok := fields[field]; ok {
+				details[field] = value
+			}
+		}
+	}
+
+	return details
+}
+
+// parseInfo reads the key:value lines of an INFO reply, skipping section headers
+func parseInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
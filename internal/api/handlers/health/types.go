@@ -10,6 +10,9 @@ type Status struct {
 	Status  string `json:"status"`
 	Latency string `json:"latency,omitempty"`
 	Error   string `json:"error,omitempty"`
+
+	// Details holds extra diagnostics, only filled in by CheckAllDetailed
+	Details map[string]any `json:"details,omitempty"`
 }
 
 // Response represents the overall health check result
@@ -25,3 +28,39 @@ type Checker interface {
 	Name() string
 	Check(ctx context.Context) Status
 }
+
+// DetailedChecker is implemented by checkers that can report diagnostics for dependency dashboards
+// Details is only called for /health/detailed, so it may be more expensive than Check
+type DetailedChecker interface {
+	Checker
+	Details(ctx context.Context) map[string]any
+}
+
+// Probe selects which group of checks a health endpoint runs
+type Probe string
+
+const (
+	// Liveness checks tell whether the process itself works, a failure means it should be restarted
+	Liveness Probe = "liveness"
+
+	// Readiness checks cover the dependencies needed to serve traffic, a failure means it should get no requests
+	Readiness Probe = "readiness"
+
+	// allProbes runs every checker regardless of its probe
+	allProbes Probe = "all"
+)
+
+// ProbeChecker is implemented by checkers that belong to a specific probe
+// Checkers without it are readiness checks, most checks cover a dependency
+type ProbeChecker interface {
+	Checker
+	Probe() Probe
+}
+
+// probeOf returns the probe a checker belongs to
+func probeOf(checker Checker) Probe {
+	if tagged, ok := checker.(ProbeChecker); ok {
+		return tagged.Probe()
+	}
+	return Readiness
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+// PortfolioHandler serves the valuation of a user's collection and its history
+type PortfolioHandler struct {
+	findUser      func(ctx context.Context, id int64) (*models.User, error)
+	findItems     func(ctx context.Context, userID int64, includeArchived bool) ([]*models.Collection, error)
+	findSnapshots func(ctx context.Context, userID int64, from, to time.Time) ([]*models.PortfolioSnapshot, error)
+
+	// lookup is nil when BrickLink is not configured
+	lookup service.PriceLookup
+}
+
+// NewPortfolioHandler creates the portfolio handler, prices are looked up through the cached bricklinkClient
+// A nil client means BrickLink is not configured and valuations answer 503, the stored history stays available
+func NewPortfolioHandler(
+	userRepo *repos.UserRepository,
+	collectionRepo *repos.CollectionRepository,
+	snapshotRepo *repos.SnapshotRepository,
+	bricklinkClient *service.CachedBricklinkClient,
+) *PortfolioHandler {
+	h := &PortfolioHandler{
+		findUser:      userRepo.FindByID,
+		findItems:     collectionRepo.FindByUser,
+		findSnapshots: snapshotRepo.FindByUser,
+	}
+	if bricklinkClient != nil {
+		h.lookup = bricklinkClient.ItemPrice
+	}
+	return h
+}
+
+// Value handles GET /api/users/{id}/portfolio/value, must be wrapped by RequireAuth
+// Items are valued in the user's preferred currency, prices that can't be fetched are reported in warnings
+// Archived items are only valued with ?include_archived=true
+func (h *PortfolioHandler) Value(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/users/")
+	idStr = strings.TrimSuffix(idStr, "/portfolio/value")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	// Users may only value their own collection
+	if !requireOwner(w, r, id, response.CodeUserNotFound, "User not found") {
+		return
+	}
+
+	if h.lookup == nil {
+		response.Error(w, http.StatusServiceUnavailable, "BrickLink integration not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	user, err := h.findUser(ctx, id)
+	if errors.Is(err, repos.ErrUserNotFound) {
+		response.ErrorCode(w, http.StatusNotFound, response.CodeUserNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to look up user")
+		return
+	}
+
+	includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+	items, err := h.findItems(ctx, id, includeArchived)
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load collection")
+		return
+	}
+
+	currency := user.PreferredCurrency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	response.JSON(w, http.StatusOK, service.ValuePortfolio(ctx, items, currency, h.lookup))
+}
+
+// History handles GET /api/users/{id}/portfolio/history, must be wrapped by RequireAuth
+// ?from= and ?to= take an RFC 3339 time or a YYYY-MM-DD date, to is exclusive but a date includes the whole day
+// Dates are days in the ?tz= timezone or the user's own, which timestamps are shown in as well
+func (h *PortfolioHandler) History(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/users/")
+	idStr = strings.TrimSuffix(idStr, "/portfolio/history")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	// Users may only see their own history
+	if !requireOwner(w, r, id, response.CodeUserNotFound, "User not found") {
+		return
+	}
+
+	loc, ok := requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	user, err := h.findUser(ctx, id)
+	if errors.Is(err, repos.ErrUserNotFound) {
+		response.ErrorCode(w, http.StatusNotFound, response.CodeUserNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to look up user")
+		return
+	}
+	if loc == nil {
+		loc = user.Location()
+	}
+
+	from, err := parseHistoryBound(r.URL.Query().Get("from"), loc, false)
+	if err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidQuery, "Invalid from, expected an RFC 3339 time or a YYYY-MM-DD date")
+		return
+	}
+	to, err := parseHistoryBound(r.URL.Query().Get("to"), loc, true)
+	if err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidQuery, "Invalid to, expected an RFC 3339 time or a YYYY-MM-DD date")
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidQuery, "from must be before to")
+		return
+	}
+
+	snapshots, err := h.findSnapshots(ctx, id, from, to)
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load portfolio history")
+		return
+	}
+
+	history := dto.PortfolioHistoryResponse{Points: make([]dto.PortfolioHistoryPoint, len(snapshots))}
+	for i, snapshot := range snapshots {
+		history.Points[i] = dto.PortfolioHistoryPoint{
+			TakenAt:           snapshot.CreatedAt.In(loc),
+			Currency:          snapshot.Currency,
+			TotalValue:        snapshot.TotalValue,
+			TotalPurchaseCost: snapshot.TotalPurchaseCost,
+			ItemCount:         snapshot.ItemCount,
+			UnpricedCount:     snapshot.UnpricedCount,
+		}
+	}
+
+	response.JSON(w, http.StatusOK, history)
+}
+
+// parseHistoryBound parses a ?from= or ?to= value, an empty value gives the zero time for an open end
+// A date is midnight in loc, or the following midnight with wholeDay so an exclusive bound still includes it
+func parseHistoryBound(value string, loc *time.Location, wholeDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if wholeDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/models"
+)
+
+func TestPortfolioValue(t *testing.T) {
+	paid := 3.5
+	h := &PortfolioHandler{
+		findUser: func(ctx context.Context, id int64) (*models.User, error) {
+			return &models.User{BaseModel: models.BaseModel{ID: id}, PreferredCurrency: "EUR"}, nil
+		},
+		findItems: func(ctx context.Context, userID int64, includeArchived bool) ([]*models.Collection, error) {
+			return []*models.Collection{
+				{BaseModel: models.BaseModel{ID: 10}, UserID: userID, ItemType: "MINIFIG", ItemID: "sw0001", Condition: "N", Quantity: 2, PurchasePrice: &paid},
+			}, nil
+		},
+		lookup: func(ctx context.Context, itemType service.ItemType, itemID, condition, currency string) (*service.MinifigPrice, error) {
+			return &service.MinifigPrice{QtyAvgPrice: "5.25", CurrencyCode: currency}, nil
+		},
+	}
+
+	serve := func(userID int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/1/portfolio/value", nil)
+		req = req.WithContext(middleware.WithUserID(req.Context(), userID))
+		rec := httptest.NewRecorder()
+		h.Value(rec, req)
+		return rec
+	}
+
+	rec := serve(1)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"currency": "EUR",
+		"total_current_value": 10.50,
+		"total_purchase_cost": 7.00,
+		"total_gain_loss": 3.50,
+		"items": [{
+			"id": 10, "item_type": "MINIFIG", "item_id": "sw0001", "condition": "N", "quantity": 2,
+			"unit_price": 5.25, "current_value": 10.50, "purchase_cost": 7.00, "gain_loss": 3.50
+		}],
+		"warnings": []
+	}`, rec.Body.String())
+
+	rec = serve(2)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "another user's portfolio must look missing")
+}
+
+func TestPortfolioValue_NotConfigured(t *testing.T) {
+	h := &PortfolioHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/1/portfolio/value", nil)
+	req = req.WithContext(middleware.WithUserID(req.Context(), 1))
+	rec := httptest.NewRecorder()
+	h.Value(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestPortfolioValue_IncludeArchived(t *testing.T) {
+	var included []bool
+	h := &PortfolioHandler{
+		findUser: func(ctx context.Context, id int64) (*models.User, error) {
+			return &models.User{BaseModel: models.BaseModel{ID: id}}, nil
+		},
+		findItems: func(ctx context.Context, userID int64, includeArchived bool) ([]*models.Collection, error) {
+			included = append(included, includeArchived)
+			return nil, nil
+		},
+		lookup: func(ctx context.Context, itemType service.ItemType, itemID, condition, currency string) (*service.MinifigPrice, error) {
+			return nil, nil
+		},
+	}
+
+	for _, target := range []string{"/api/users/1/portfolio/value", "/api/users/1/portfolio/value?include_archived=true"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(middleware.WithUserID(req.Context(), 1))
+		rec := httptest.NewRecorder()
+		h.Value(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+	}
+
+	assert.Equal(t, []bool{false, true}, included, "archived items are only valued when asked for")
+}
+
+func TestPortfolioHistory(t *testing.T) {
+	var from, to time.Time
+	taken := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := &PortfolioHandler{
+		findUser: func(ctx context.Context, id int64) (*models.User, error) {
+			return &models.User{BaseModel: models.BaseModel{ID: id}, Timezone: "Europe/Berlin"}, nil
+		},
+		findSnapshots: func(ctx context.Context, userID int64, f, tt time.Time) ([]*models.PortfolioSnapshot, error) {
+			from, to = f, tt
+			return []*models.PortfolioSnapshot{
+				{BaseModel: models.BaseModel{CreatedAt: taken}, UserID: userID, Currency: "EUR", TotalValue: 10.5, TotalPurchaseCost: 7, ItemCount: 2},
+			}, nil
+		},
+	}
+
+	serve := func(target string, userID int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(middleware.WithUserID(req.Context(), userID))
+		rec := httptest.NewRecorder()
+		h.History(rec, req)
+		return rec
+	}
+
+	rec := serve("/api/users/1/portfolio/history?from=2026-03-01&to=2026-03-31", 1)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"points": [{
+		"taken_at": "2026-03-01T13:00:00+01:00", "currency": "EUR",
+		"total_value": 10.5, "total_purchase_cost": 7, "item_count": 2, "unpriced_count": 0
+	}]}`, rec.Body.String())
+
+	// Dates are days in the user's timezone and to includes its whole day
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	assert.True(t, from.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, berlin)), from)
+	assert.True(t, to.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, berlin)), to)
+
+	rec = serve("/api/users/1/portfolio/history?from=2026-03-01T10:00:00Z", 1)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, from.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)), from)
+	assert.True(t, to.IsZero(), "a missing bound leaves the range open")
+
+	for _, target := range []string{
+		"/api/users/1/portfolio/history?from=yesterday",
+		"/api/users/1/portfolio/history?to=2026-13-01",
+		"/api/users/1/portfolio/history?from=2026-03-02&to=2026-03-01",
+	} {
+		assert.Equal(t, http.StatusBadRequest, serve(target, 1).Code, target)
+	}
+
+	rec = serve("/api/users/1/portfolio/history", 2)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "another user's history must look missing")
+}
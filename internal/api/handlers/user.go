@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,74 +11,186 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/charmbracelet/log"
+
 	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/api/request"
 	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/events"
 	"LegoManagerAPI/internal/models"
 	"LegoManagerAPI/internal/repos"
 )
 
 type UserHandler struct {
-	userRepo *repos.UserRepository
+	userRepo   *repos.UserRepository
+	bcryptCost int
+	bus        *events.Bus
 }
 
-func NewUserHandler(userRepo *repos.UserRepository) *UserHandler {
+func NewUserHandler(userRepo *repos.UserRepository, bcryptCost int, bus *events.Bus) *UserHandler {
 	return &UserHandler{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		bcryptCost: bcryptCost,
+		bus:        bus,
 	}
 }
 
 // CreateUser handles POST /api/users
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
 	var req dto.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	req.PreferredCurrency = strings.ToUpper(req.PreferredCurrency)
+	req.Email = normalizeEmail(req.Email)
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
 		return
 	}
 
-	// Validate
-	if req.Username == "" || req.Password == "" || req.FirstName == "" || req.LastName == "" {
-		response.Error(w, http.StatusBadRequest, "Invalid request body")
+	loc, ok := requestLocation(w, r)
+	if !ok {
 		return
 	}
 
 	// / Check if user already exists
 	exists, err := h.userRepo.UsernameExists(ctx, req.Username)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to check username existence")
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to check username existence")
 		return
 	}
 
 	if exists {
-		response.Error(w, http.StatusBadRequest, "Username already exists")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeUsernameTaken, "Username already exists")
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to hash password")
+	if req.Email != "" {
+		exists, err := h.userRepo.EmailExists(ctx, req.Email)
+		if err != nil {
+			response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to check email existence")
+			return
+		}
+
+		if exists {
+			response.ErrorCode(w, http.StatusConflict, response.CodeEmailTaken, "Email already exists")
+			return
+		}
 	}
 
 	// Create User
-	user := &models.User{
-		BaseModel:    models.BaseModel{},
-		Username:     req.Username,
-		PasswordHash: string(hashedPassword),
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
+	user, err := h.newUser(req)
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to hash password")
+		return
 	}
 
-	if err := h.userRepo.Create(ctx, user); err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to create user")
+	err = h.userRepo.Create(ctx, user)
+	if userTaken(w, err) {
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create user")
 		return
 	}
 
-	response.JSON(w, http.StatusCreated, user)
+	h.bus.Publish(events.UserCreated{UserID: user.ID, Username: user.Username})
+
+	// The caller just created this account, so it may see its private fields
+	response.Created(w, response.ResourceURL("/api/users", user.ID), h.toAccountResponse(user, loc))
 }
 
-// GetUser handles GET /api/users/:id
+// SyncUser handles POST /api/admin/users/sync, must be wrapped by RequireAuth and RequireAdmin
+// It creates or updates the user keyed by its external id, so repeated syncs never duplicate a user.
+// Only admins may sync, the update overwrites the profile and email of whichever user holds the external id
+// Responds 201 when the user was created and 200 when an existing user was updated
+func (h *UserHandler) SyncUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var req dto.SyncUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	req.PreferredCurrency = strings.ToUpper(req.PreferredCurrency)
+	req.Email = normalizeEmail(req.Email)
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
+		return
+	}
+
+	loc, ok := requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.newUser(req.CreateUserRequest)
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to hash password")
+		return
+	}
+	user.ExternalID = &req.ExternalID
+
+	created, err := h.userRepo.UpsertByExternalID(ctx, user)
+	if userTaken(w, err) {
+		return
+	}
+	if errors.Is(err, repos.ErrUserDeleted) {
+		response.ErrorCode(w, http.StatusConflict, response.CodeUserDeleted, "User was deleted")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to sync user")
+		return
+	}
+
+	adminID, _ := middleware.UserIDFromContext(r.Context())
+	log.Info("User synced", "admin_id", adminID, "user_id", user.ID, "external_id", req.ExternalID, "created", created)
+
+	if !created {
+		response.JSON(w, http.StatusOK, h.toAccountResponse(user, loc))
+		return
+	}
+
+	h.bus.Publish(events.UserCreated{UserID: user.ID, Username: user.Username})
+
+	response.Created(w, response.ResourceURL("/api/users", user.ID), h.toAccountResponse(user, loc))
+}
+
+// newUser builds the user a create or sync request describes, with the password hashed
+func (h *UserHandler) newUser(req dto.CreateUserRequest) (*models.User, error) {
+	hashedPassword, err := h.hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Default to USD when no preferred currency was given
+	preferredCurrency := req.PreferredCurrency
+	if preferredCurrency == "" {
+		preferredCurrency = "USD"
+	}
+
+	return &models.User{
+		Username:          req.Username,
+		PasswordHash:      hashedPassword,
+		FirstName:         req.FirstName,
+		LastName:          req.LastName,
+		PreferredCurrency: preferredCurrency,
+		Timezone:          req.Timezone,
+		Email:             optionalEmail(req.Email),
+	}, nil
+}
+
+// GetUser handles GET /api/users/:id, should be wrapped by OptionalAuth
+// Everyone sees the public profile, only the user themselves also gets the private fields
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
@@ -86,17 +199,27 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/users/")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, "Invalid user ID")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	loc, ok := requestLocation(w, r)
+	if !ok {
 		return
 	}
 
 	user, err := h.userRepo.FindByID(ctx, id)
 	if err != nil {
-		response.Error(w, http.StatusNotFound, "User not found")
+		response.ErrorCode(w, http.StatusNotFound, response.CodeUserNotFound, "User not found")
+		return
+	}
+
+	if callerID, ok := middleware.UserIDFromContext(r.Context()); ok && callerID == user.ID {
+		response.JSON(w, http.StatusOK, h.toAccountResponse(user, loc))
 		return
 	}
 
-	response.JSON(w, http.StatusOK, h.toUserResponse(user))
+	response.JSON(w, http.StatusOK, h.toUserResponse(user, loc))
 }
 
 // UpdateUser handles PUT /api/users/{id}
@@ -108,34 +231,60 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/users/")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, "Invalid user ID")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	// Users may only change their own account
+	if !requireOwner(w, r, id, response.CodeUserNotFound, "User not found") {
 		return
 	}
 
 	var req dto.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	req.PreferredCurrency = strings.ToUpper(req.PreferredCurrency)
+	if req.Email != nil {
+		*req.Email = normalizeEmail(*req.Email)
+	}
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
+		return
+	}
+
+	loc, ok := requestLocation(w, r)
+	if !ok {
 		return
 	}
 
 	// Get existing user
 	user, err := h.userRepo.FindByID(ctx, id)
 	if err != nil {
-		response.Error(w, http.StatusNotFound, "User not found")
+		response.ErrorCode(w, http.StatusNotFound, response.CodeUserNotFound, "User not found")
 		return
 	}
 
-	// Update fields
-	user.Username = req.Username
-	user.FirstName = req.FirstName
-	user.LastName = req.LastName
+	// Support staff impersonating a user can't redirect where password resets are sent
+	if _, impersonating := middleware.ImpersonatorFromContext(r.Context()); impersonating && req.Email != nil && *req.Email != email(user) {
+		response.Error(w, http.StatusForbidden, "Not allowed while impersonating a user")
+		return
+	}
+
+	applyUserUpdate(user, req)
 
-	if err := h.userRepo.Update(ctx, user); err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to update user")
+	err = h.userRepo.Update(ctx, user)
+	if userTaken(w, err) {
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update user")
 		return
 	}
 
-	response.JSON(w, http.StatusOK, h.toUserResponse(user))
+	response.JSON(w, http.StatusOK, h.toAccountResponse(user, loc))
 }
 
 // DeleteUser handles DELETE /api/users/{id}
@@ -146,12 +295,23 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/users/")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, "Invalid user ID")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	// Users may only change their own account
+	if !requireOwner(w, r, id, response.CodeUserNotFound, "User not found") {
 		return
 	}
 
-	if err := h.userRepo.Delete(ctx, id); err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to delete user")
+	// Soft delete keeps the user's portfolio history
+	err = h.userRepo.SoftDelete(ctx, id)
+	if errors.Is(err, repos.ErrUserNotFound) {
+		response.ErrorCode(w, http.StatusNotFound, response.CodeUserNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to delete user")
 		return
 	}
 
@@ -159,6 +319,8 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 }
 
 // ListUsers handles GET /api/users
+// Passing cursor (empty for the first page) switches from offset to cursor pagination, which is always newest first
+// Offset pages are ordered by sort, e.g. sort=-username, defaulting to newest first
 func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -181,29 +343,48 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	users, err := h.userRepo.List(ctx, limit, offset)
+	loc, ok := requestLocation(w, r)
+	if !ok {
+		return
+	}
+
+	var users []*models.User
+	var nextCursor string
+	var err error
+	if r.URL.Query().Has("cursor") {
+		offset = 0
+		users, nextCursor, err = h.userRepo.ListAfter(ctx, r.URL.Query().Get("cursor"), limit)
+	} else {
+		sort, _ := repos.ParseUserSort(r.URL.Query().Get("sort"))
+		users, err = h.userRepo.List(ctx, limit, offset, sort)
+	}
+	if errors.Is(err, repos.ErrInvalidCursor) {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidQuery, "Invalid cursor")
+		return
+	}
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to list users")
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to list users")
 		return
 	}
 
 	total, err := h.userRepo.Count(ctx)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to count users")
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to count users")
 		return
 	}
 
 	// Convert to response DTOs
 	userResponses := make([]dto.UserResponse, len(users))
 	for i, user := range users {
-		userResponses[i] = h.toUserResponse(user)
+		userResponses[i] = h.toUserResponse(user, loc)
 	}
 
 	resp := dto.ListUsersResponse{
-		Users:  userResponses,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+		Users:      userResponses,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: nextCursor,
 	}
 
 	response.JSON(w, http.StatusOK, resp)
@@ -216,19 +397,24 @@ func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 
 	searchTerm := r.URL.Query().Get("q")
 	if searchTerm == "" {
-		response.Error(w, http.StatusBadRequest, "Search term is required")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidQuery, "Search term is required")
+		return
+	}
+
+	loc, ok := requestLocation(w, r)
+	if !ok {
 		return
 	}
 
 	users, err := h.userRepo.SearchByName(ctx, searchTerm)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to search users")
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to search users")
 		return
 	}
 
 	userResponses := make([]dto.UserResponse, len(users))
 	for i, user := range users {
-		userResponses[i] = h.toUserResponse(user)
+		userResponses[i] = h.toUserResponse(user, loc)
 	}
 
 	response.JSON(w, http.StatusOK, userResponses)
@@ -243,54 +429,159 @@ func (h *UserHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	idStr = strings.TrimSuffix(idStr, "/password")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, "Invalid user ID")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	// Users may only change their own account
+	if !requireOwner(w, r, id, response.CodeUserNotFound, "User not found") {
 		return
 	}
 
 	var req dto.UpdatePasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
 		return
 	}
 
 	// Get user to verify old password
 	user, err := h.userRepo.FindByID(ctx, id)
 	if err != nil {
-		response.Error(w, http.StatusNotFound, "User not found")
+		response.ErrorCode(w, http.StatusNotFound, response.CodeUserNotFound, "User not found")
 		return
 	}
 
 	// Verify old password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.OldPassword)); err != nil {
-		response.Error(w, http.StatusUnauthorized, "Invalid old password")
+		response.ErrorCode(w, http.StatusUnauthorized, response.CodeInvalidPassword, "Invalid old password")
 		return
 	}
 
 	// Hash new password
-	newHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	newHash, err := h.hashPassword(req.NewPassword)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to hash password")
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to hash password")
 		return
 	}
 
 	// Update password
-	if err := h.userRepo.UpdatePassword(ctx, id, string(newHash)); err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to update password")
+	if err := h.userRepo.UpdatePassword(ctx, id, newHash); err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update password")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// Helper to convert model to response DTO
-func (h *UserHandler) toUserResponse(user *models.User) dto.UserResponse {
+// hashPassword hashes a password with the configured bcrypt cost
+func (h *UserHandler) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// requestLocation parses the optional ?tz= param, writing a 400 and returning false when it is invalid
+func requestLocation(w http.ResponseWriter, r *http.Request) (*time.Location, bool) {
+	loc, err := request.Location(r)
+	if err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidTimezone, "Invalid timezone, expected an IANA name like Europe/Berlin")
+		return nil, false
+	}
+	return loc, true
+}
+
+// Helper to convert model to the public response DTO, safe to show to anyone
+// Timestamps are shown in loc when given, otherwise in the user's stored timezone (UTC by default)
+func (h *UserHandler) toUserResponse(user *models.User, loc *time.Location) dto.UserResponse {
+	if loc == nil {
+		loc = user.Location()
+	}
+
 	return dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		FullName:  user.FullName(), // Add this
-		CreatedAt: user.CreatedAt,  // Add this
-		UpdatedAt: user.UpdatedAt,  // Add this
+		ID:                user.ID,
+		Username:          user.Username,
+		FirstName:         user.FirstName,
+		LastName:          user.LastName,
+		FullName:          user.FullName(), // Add this
+		CreatedAt:         user.CreatedAt.In(loc),
+		UpdatedAt:         user.UpdatedAt.In(loc),
+		PreferredCurrency: user.PreferredCurrency,
+		Timezone:          user.Timezone,
+	}
+}
+
+// toAccountResponse converts a model to the DTO shown to the user themselves (or an admin syncing them),
+// never use it for responses other callers can see
+func (h *UserHandler) toAccountResponse(user *models.User, loc *time.Location) dto.AccountResponse {
+	return dto.AccountResponse{
+		UserResponse: h.toUserResponse(user, loc),
+		Email:        email(user),
+		ExternalID:   externalID(user),
+	}
+}
+
+// userTaken responds when err reports another user already has the username or email and returns whether it did
+func userTaken(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, repos.ErrUsernameTaken):
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeUsernameTaken, "Username already exists")
+	case errors.Is(err, repos.ErrEmailTaken):
+		response.ErrorCode(w, http.StatusConflict, response.CodeEmailTaken, "Email already exists")
+	default:
+		return false
+	}
+	return true
+}
+
+// applyUserUpdate copies a validated update onto the user, optional fields that were omitted keep their value
+// An explicit empty timezone clears it back to UTC and an explicit empty email removes it
+func applyUserUpdate(user *models.User, req dto.UpdateUserRequest) {
+	user.Username = req.Username
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	if req.PreferredCurrency != "" {
+		user.PreferredCurrency = req.PreferredCurrency
+	}
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+	}
+	if req.Email != nil {
+		user.Email = optionalEmail(*req.Email)
+	}
+}
+
+// normalizeEmail trims and lowercases an email so lookups and the unique constraint ignore case
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// optionalEmail maps an empty email to nil, stored as NULL so users without one don't collide
+func optionalEmail(email string) *string {
+	if email == "" {
+		return nil
+	}
+	return &email
+}
+
+// externalID returns the user's external id, or an empty string for local users
+func externalID(user *models.User) string {
+	if user.ExternalID == nil {
+		return ""
+	}
+	return *user.ExternalID
+}
+
+// email returns the user's email, or an empty string when none is set
+func email(user *models.User) string {
+	if user.Email == nil {
+		return ""
 	}
+	return *user.Email
 }
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+func TestHashPassword_UsesConfiguredCost(t *testing.T) {
+	h := NewUserHandler(nil, bcrypt.MinCost+1, nil)
+
+	hash, err := h.hashPassword("supersecret")
+	require.NoError(t, err)
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("supersecret")))
+}
+
+func TestCreatedUserResponse_OmitsPasswordHash(t *testing.T) {
+	h := NewUserHandler(nil, bcrypt.MinCost, nil)
+	user := &models.User{BaseModel: models.BaseModel{ID: 7}, Username: "alice", PasswordHash: "$2a$04$secrethash"}
+
+	rec := httptest.NewRecorder()
+	response.Created(rec, response.ResourceURL("/api/users", user.ID), h.toUserResponse(user, nil))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.NotContains(t, body, "password_hash")
+	assert.NotContains(t, rec.Body.String(), user.PasswordHash)
+
+	// The model itself must never marshal the hash either
+	raw, err := json.Marshal(user)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), user.PasswordHash)
+}
+
+func TestSyncUser_RequiresExternalID(t *testing.T) {
+	h := NewUserHandler(nil, bcrypt.MinCost, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/sync", strings.NewReader(
+		`{"password": "supersecret1", "first_name": "Emmet", "last_name": "Brickowski"}`,
+	))
+	rec := httptest.NewRecorder()
+	h.SyncUser(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{
+		"error": "Validation failed",
+		"code": "VALIDATION_FAILED",
+		"details": [
+			{"field": "username", "message": "is required"},
+			{"field": "external_id", "message": "is required"}
+		]
+	}`, rec.Body.String())
+}
+
+func TestUserResponse_OmitsExternalID(t *testing.T) {
+	h := NewUserHandler(nil, bcrypt.MinCost, nil)
+	externalID := "sso|42"
+	user := &models.User{BaseModel: models.BaseModel{ID: 7}, Username: "alice", ExternalID: &externalID}
+
+	raw, err := json.Marshal(h.toUserResponse(user, nil))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), externalID)
+}
+
+func TestUserResponse_EmailOnlyForAccount(t *testing.T) {
+	h := NewUserHandler(nil, bcrypt.MinCost, nil)
+	address := "alice@bricksburg.com"
+	user := &models.User{BaseModel: models.BaseModel{ID: 7}, Username: "alice", Email: &address}
+
+	public, err := json.Marshal(h.toUserResponse(user, nil))
+	require.NoError(t, err)
+	assert.NotContains(t, string(public), address)
+
+	account, err := json.Marshal(h.toAccountResponse(user, nil))
+	require.NoError(t, err)
+	assert.Contains(t, string(account), `"email":"alice@bricksburg.com"`)
+	assert.Contains(t, string(account), `"username":"alice"`)
+}
+
+func TestApplyUserUpdate_OmittedFieldsAreKept(t *testing.T) {
+	address := "alice@bricksburg.com"
+	user := &models.User{Username: "alice", PreferredCurrency: "EUR", Timezone: "Europe/Berlin", Email: &address}
+
+	var req dto.UpdateUserRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"username":"alice2","first_name":"Alice","last_name":"Brick"}`), &req))
+	applyUserUpdate(user, req)
+
+	assert.Equal(t, "alice2", user.Username)
+	assert.Equal(t, "EUR", user.PreferredCurrency)
+	assert.Equal(t, "Europe/Berlin", user.Timezone)
+	require.NotNil(t, user.Email)
+	assert.Equal(t, address, *user.Email)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"username":"alice2","first_name":"Alice","last_name":"Brick","timezone":"","email":""}`), &req))
+	applyUserUpdate(user, req)
+
+	assert.Empty(t, user.Timezone, "an explicit empty timezone clears it")
+	assert.Nil(t, user.Email, "an explicit empty email removes it")
+}
+
+func TestUserTaken(t *testing.T) {
+	rec := httptest.NewRecorder()
+	require.True(t, userTaken(rec, fmt.Errorf("update: %w", repos.ErrUsernameTaken)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"USERNAME_TAKEN"`)
+
+	rec = httptest.NewRecorder()
+	require.True(t, userTaken(rec, repos.ErrEmailTaken))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"EMAIL_TAKEN"`)
+
+	assert.False(t, userTaken(httptest.NewRecorder(), errors.New("connection reset")))
+	assert.False(t, userTaken(httptest.NewRecorder(), nil))
+}
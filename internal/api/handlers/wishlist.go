@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/request"
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+// WishlistHandler serves the wishlist routes under /api/users/{id}/wishlist, they must be wrapped by RequireAuth
+// A wishlist is private, only its owner can see or change it
+type WishlistHandler struct {
+	wishlist *repos.WishlistRepository
+	findUser func(ctx context.Context, id int64) (*models.User, error)
+
+	// lookup is nil when BrickLink is not configured
+	lookup service.PriceLookup
+}
+
+// NewWishlistHandler creates the wishlist handler, deal prices are looked up through the cached bricklinkClient
+// A nil client means BrickLink is not configured and only the deals route answers 503
+func NewWishlistHandler(userRepo *repos.UserRepository, wishlistRepo *repos.WishlistRepository, bricklinkClient *service.CachedBricklinkClient) *WishlistHandler {
+	h := &WishlistHandler{
+		wishlist: wishlistRepo,
+		findUser: userRepo.FindByID,
+	}
+	if bricklinkClient != nil {
+		h.lookup = bricklinkClient.ItemPrice
+	}
+	return h
+}
+
+// List handles GET /api/users/{id}/wishlist
+func (h *WishlistHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	items, err := h.wishlist.FindByUser(ctx, userID)
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load wishlist")
+		return
+	}
+
+	wishlistResponse := dto.WishlistResponse{Items: make([]dto.WishlistItemResponse, len(items))}
+	for i, item := range items {
+		wishlistResponse.Items[i] = toWishlistItemResponse(item)
+	}
+
+	response.JSON(w, http.StatusOK, wishlistResponse)
+}
+
+// Create handles POST /api/users/{id}/wishlist
+func (h *WishlistHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var req dto.CreateWishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	req.ItemType = strings.ToUpper(req.ItemType)
+	req.Condition = strings.ToUpper(req.Condition)
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
+		return
+	}
+
+	item := &models.Wishlist{
+		UserID:      userID,
+		ItemType:    req.ItemType,
+		ItemID:      req.ItemID,
+		Condition:   req.Condition,
+		TargetPrice: req.TargetPrice,
+	}
+	if item.Condition == "" {
+		item.Condition = models.ConditionNew
+	}
+
+	err := h.wishlist.Create(ctx, item)
+	if errors.Is(err, repos.ErrWishlistItemExists) {
+		response.ErrorCode(w, http.StatusConflict, response.CodeWishlistItemExists, "Item is already on the wishlist")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to add wishlist item")
+		return
+	}
+
+	response.Created(w, response.ResourceURL(fmt.Sprintf("/api/users/%d/wishlist", userID), item.ID), toWishlistItemResponse(item))
+}
+
+// Update handles PUT /api/users/{id}/wishlist/{itemId}
+func (h *WishlistHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, itemID, ok := h.ownedItemID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	var req dto.UpdateWishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	req.Condition = strings.ToUpper(req.Condition)
+
+	if errs := request.Validate(req); errs != nil {
+		response.ValidationErrors(w, errs)
+		return
+	}
+
+	item, ok := h.findOwnedItem(ctx, w, userID, itemID)
+	if !ok {
+		return
+	}
+
+	item.Condition = req.Condition
+	item.TargetPrice = req.TargetPrice
+
+	err := h.wishlist.Update(ctx, item)
+	if errors.Is(err, repos.ErrWishlistItemNotFound) {
+		response.ErrorCode(w, http.StatusNotFound, response.CodeWishlistItemNotFound, "Wishlist item not found")
+		return
+	}
+	if errors.Is(err, repos.ErrWishlistItemExists) {
+		response.ErrorCode(w, http.StatusConflict, response.CodeWishlistItemExists, "Item is already on the wishlist")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update wishlist item")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, toWishlistItemResponse(item))
+}
+
+// Delete handles DELETE /api/users/{id}/wishlist/{itemId}
+func (h *WishlistHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, itemID, ok := h.ownedItemID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if _, ok := h.findOwnedItem(ctx, w, userID, itemID); !ok {
+		return
+	}
+
+	err := h.wishlist.Delete(ctx, itemID)
+	if errors.Is(err, repos.ErrWishlistItemNotFound) {
+		response.ErrorCode(w, http.StatusNotFound, response.CodeWishlistItemNotFound, "Wishlist item not found")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to delete wishlist item")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deals handles GET /api/users/{id}/wishlist/deals
+// Every item gets its current minimum price in the user's preferred currency, items listed at or below
+// their target price are flagged. Prices come from the BrickLink cache, so polling stays cheap
+func (h *WishlistHandler) Deals(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	if h.lookup == nil {
+		response.Error(w, http.StatusServiceUnavailable, "BrickLink integration not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	user, err := h.findUser(ctx, userID)
+	if errors.Is(err, repos.ErrUserNotFound) {
+		response.ErrorCode(w, http.StatusNotFound, response.CodeUserNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to look up user")
+		return
+	}
+
+	items, err := h.wishlist.FindByUser(ctx, userID)
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load wishlist")
+		return
+	}
+
+	currency := user.PreferredCurrency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	response.JSON(w, http.StatusOK, service.FindDeals(ctx, items, currency, h.lookup))
+}
+
+// owner parses the user id of a wishlist path and checks the caller owns it, responding 400 or 404 otherwise
+func (h *WishlistHandler) owner(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, _, err := wishlistPath(r.URL.Path)
+	if err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid user ID")
+		return 0, false
+	}
+
+	return userID, requireOwner(w, r, userID, response.CodeUserNotFound, "User not found")
+}
+
+// ownedItemID is owner for paths naming a wishlist item, it also parses the item id
+func (h *WishlistHandler) ownedItemID(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, ok := h.owner(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+
+	_, rest, _ := wishlistPath(r.URL.Path)
+	itemID, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil {
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidID, "Invalid wishlist item ID")
+		return 0, 0, false
+	}
+
+	return userID, itemID, true
+}
+
+// findOwnedItem loads a wishlist item, responding 404 when it is missing or on another user's wishlist
+func (h *WishlistHandler) findOwnedItem(ctx context.Context, w http.ResponseWriter, userID, itemID int64) (*models.Wishlist, bool) {
+	item, err := h.wishlist.FindByID(ctx, itemID)
+	if errors.Is(err, repos.ErrWishlistItemNotFound) || (err == nil && item.UserID != userID) {
+		response.ErrorCode(w, http.StatusNotFound, response.CodeWishlistItemNotFound, "Wishlist item not found")
+		return nil, false
+	}
+	if err != nil {
+		response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load wishlist item")
+		return nil, false
+	}
+
+	return item, true
+}
+
+// wishlistPath splits /api/users/{id}/wishlist/{rest} into the user id and rest, "" for the wishlist itself
+func wishlistPath(path string) (int64, string, error) {
+	userPart, rest, _ := strings.Cut(strings.TrimPrefix(path, "/api/users/"), "/wishlist")
+	userID, err := strconv.ParseInt(userPart, 10, 64)
+	return userID, strings.Trim(rest, "/"), err
+}
+
+// toWishlistItemResponse converts a wishlist model to its response DTO
+func toWishlistItemResponse(item *models.Wishlist) dto.WishlistItemResponse {
+	return dto.WishlistItemResponse{
+		ID:          item.ID,
+		ItemType:    item.ItemType,
+		ItemID:      item.ItemID,
+		Condition:   item.Condition,
+		TargetPrice: item.TargetPrice,
+		AddedAt:     item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"LegoManagerAPI/internal/api/middleware"
+)
+
+func TestWishlist_OtherUsersWishlistLooksMissing(t *testing.T) {
+	// No repository is needed, ownership is checked before anything is loaded
+	h := &WishlistHandler{}
+
+	for _, tc := range []struct {
+		method, path string
+		serve        http.HandlerFunc
+	}{
+		{http.MethodGet, "/api/users/2/wishlist", h.List},
+		{http.MethodPost, "/api/users/2/wishlist", h.Create},
+		{http.MethodPut, "/api/users/2/wishlist/7", h.Update},
+		{http.MethodDelete, "/api/users/2/wishlist/7", h.Delete},
+		{http.MethodGet, "/api/users/2/wishlist/deals", h.Deals},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+		req = req.WithContext(middleware.WithUserID(req.Context(), 1))
+		rec := httptest.NewRecorder()
+		tc.serve(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, tc.method+" "+tc.path)
+	}
+}
+
+func TestWishlist_BadRequests(t *testing.T) {
+	h := &WishlistHandler{}
+
+	serve := func(method, path, body string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(middleware.WithUserID(req.Context(), 1))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/api/users/1/wishlist", `{"item_type": "castle", "item_id": "sw0001"}`, h.Create)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"item_type"`)
+
+	rec = serve(http.MethodPut, "/api/users/1/wishlist/abc", `{"condition": "N"}`, h.Update)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(http.MethodGet, "/api/users/1/wishlist/deals", ``, h.Deals)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "deals need BrickLink")
+}
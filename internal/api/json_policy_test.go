@@ -0,0 +1,101 @@
+package api_test
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/handlers"
+	"LegoManagerAPI/internal/api/handlers/health"
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/models"
+)
+
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// TestJSONTagsAreSnakeCase asserts every exported field of the API payload types
+// has an explicit snake_case (or "-") json tag, so no Go field name leaks into responses
+func TestJSONTagsAreSnakeCase(t *testing.T) {
+	types := []interface{}{
+		// Requests
+		dto.CreateUserRequest{},
+		dto.SyncUserRequest{},
+		dto.UpdateUserRequest{},
+		dto.UpdatePasswordRequest{},
+		dto.RotateBricklinkCredentialsRequest{},
+		dto.CreateWishlistItemRequest{},
+		dto.UpdateWishlistItemRequest{},
+
+		// Responses
+		dto.UserResponse{},
+		dto.AccountResponse{},
+		dto.ListUsersResponse{},
+		dto.ItemExistsResponse{},
+		dto.RecentlyViewedResponse{},
+		dto.WishlistResponse{},
+		dto.PortfolioHistoryResponse{},
+		response.ValidationError{},
+		response.ErrorResponse{},
+		health.Response{},
+		handlers.LiveResponse{},
+		service.MinifigCompleteResponse{},
+		service.MinifigComplete{},
+		service.Color{},
+		service.PortfolioValue{},
+		service.WishlistDeals{},
+		models.User{},
+		models.Collection{},
+		models.Wishlist{},
+		models.PortfolioSnapshot{},
+	}
+
+	seen := make(map[reflect.Type]bool)
+	for _, v := range types {
+		checkJSONTags(t, reflect.TypeOf(v), seen)
+	}
+}
+
+// checkJSONTags walks a type and its nested struct types checking each field's json tag
+func checkJSONTags(t *testing.T, typ reflect.Type, seen map[reflect.Type]bool) {
+	t.Helper()
+
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+
+	// Only walk our own structs, stdlib types like time.Time marshal themselves
+	if typ.Kind() != reflect.Struct || !strings.HasPrefix(typ.PkgPath(), "LegoManagerAPI/") || seen[typ] {
+		return
+	}
+	seen[typ] = true
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup("json")
+		name := strings.SplitN(tag, ",", 2)[0]
+
+		// Embedded structs without a tag are flattened into the parent
+		if field.Anonymous && name == "" {
+			checkJSONTags(t, field.Type, seen)
+			continue
+		}
+
+		switch {
+		case !hasTag || name == "":
+			t.Errorf("%s.%s has no explicit json name and would serialize as %q", typ.Name(), field.Name, field.Name)
+		case name == "-":
+			continue
+		case !snakeCase.MatchString(name):
+			t.Errorf("%s.%s has non snake_case json name %q", typ.Name(), field.Name, name)
+		}
+
+		checkJSONTags(t, field.Type, seen)
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/auth"
+)
+
+// claimsKey is the context key the verified token claims are stored under
+type claimsKey struct{}
+
+// Users reports whether a user still exists, soft deleted users don't
+type Users interface {
+	Exists(ctx context.Context, id int64) (bool, error)
+}
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <token>" header with a 401
+// Tokens of deleted users are rejected too instead of working until they expire
+// The authenticated user id is available to next through UserIDFromContext
+func RequireAuth(next http.Handler, tokens *auth.TokenManager, users Users) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			response.Error(w, http.StatusUnauthorized, "Missing bearer token")
+			return
+		}
+
+		claims, err := tokens.Parse(token)
+		if err != nil {
+			response.Error(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+
+		active, err := activeClaims(r.Context(), users, claims)
+		if err != nil {
+			log.Error("Failed to check token user", "user_id", claims.UserID, "error", err)
+			response.ErrorCode(w, http.StatusInternalServerError, response.CodeInternal, "Failed to verify token")
+			return
+		}
+		if !active {
+			response.Error(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+
+		// Attribute every impersonated action to the admin behind it
+		if claims.Impersonated() {
+			log.Info("Audit: impersonated request",
+				"actor_id", claims.ImpersonatedBy, "impersonated_id", claims.UserID,
+				"method", r.Method, "path", r.URL.Path)
+		}
+
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
+	})
+}
+
+// OptionalAuth identifies the caller when the request carries a valid bearer token and lets it through
+// anonymously otherwise, for public routes that behave differently for logged in users
+// Tokens of deleted users, or ones that can't be checked, are treated as anonymous
+func OptionalAuth(next http.Handler, tokens *auth.TokenManager, users Users) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := tokens.Parse(token)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if active, err := activeClaims(r.Context(), users, claims); err != nil || !active {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
+	})
+}
+
+// activeClaims reports whether the user of claims, and the admin behind an impersonation, still exist
+func activeClaims(ctx context.Context, users Users, claims auth.Claims) (bool, error) {
+	exists, err := users.Exists(ctx, claims.UserID)
+	if err != nil || !exists || !claims.Impersonated() {
+		return exists, err
+	}
+	return users.Exists(ctx, claims.ImpersonatedBy)
+}
+
+// DenyImpersonation rejects requests made with an impersonation token with a 403
+// Must be wrapped by RequireAuth
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := ImpersonatorFromContext(r.Context()); ok {
+			response.Error(w, http.StatusForbidden, "Not allowed while impersonating a user")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin rejects requests from users outside adminIDs with a 403
+// Must be wrapped by RequireAuth
+func RequireAdmin(next http.Handler, adminIDs []int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok || !slices.Contains(adminIDs, userID) {
+			response.Error(w, http.StatusForbidden, "Admin access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UserIDFromContext returns the id of the user authenticated by RequireAuth
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(auth.Claims)
+	return claims.UserID, ok
+}
+
+// ImpersonatorFromContext returns the admin id when the request uses an impersonation token
+func ImpersonatorFromContext(ctx context.Context) (int64, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(auth.Claims)
+	return claims.ImpersonatedBy, ok && claims.Impersonated()
+}
+
+// WithUserID returns a context carrying userID as the authenticated user
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return withClaims(ctx, auth.Claims{UserID: userID})
+}
+
+func withClaims(ctx context.Context, claims auth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
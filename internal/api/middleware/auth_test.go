@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/auth"
+	authConfig "LegoManagerAPI/internal/config/auth"
+)
+
+// fakeUsers holds the ids of the users that still exist, err fails every lookup
+type fakeUsers struct {
+	ids map[int64]bool
+	err error
+}
+
+func (u fakeUsers) Exists(_ context.Context, id int64) (bool, error) {
+	return u.ids[id], u.err
+}
+
+func TestRequireAuth(t *testing.T) {
+	tokens := auth.NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour})
+
+	var gotUserID int64
+	handler := RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		require.True(t, ok)
+		gotUserID = userID
+		w.WriteHeader(http.StatusNoContent)
+	}), tokens, fakeUsers{ids: map[int64]bool{1: true, 7: true}})
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/users/7", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		token, _, err := tokens.Issue(7)
+		require.NoError(t, err)
+
+		rec := serve("Bearer " + token)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, int64(7), gotUserID)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		rec := serve("")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"code":"UNKNOWN","error":"Missing bearer token"}`, rec.Body.String())
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		rec := serve("Bearer not-a-token")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		token, _, err := tokens.Issue(8)
+		require.NoError(t, err)
+
+		rec := serve("Bearer " + token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"code":"UNKNOWN","error":"Invalid or expired token"}`, rec.Body.String())
+	})
+
+	t.Run("impersonation by a deleted admin", func(t *testing.T) {
+		token, _, err := tokens.IssueImpersonation(7, 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, serve("Bearer "+token).Code)
+	})
+}
+
+func TestRequireAuth_UserLookupFails(t *testing.T) {
+	tokens := auth.NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour})
+	handler := RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("next should not run when the user can't be checked")
+	}), tokens, fakeUsers{err: errors.New("connection refused")})
+
+	token, _, err := tokens.Issue(7)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/users/7/wishlist", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"code":"INTERNAL_ERROR","error":"Failed to verify token"}`, rec.Body.String())
+}
+
+func TestOptionalAuth(t *testing.T) {
+	tokens := auth.NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour})
+
+	serve := func(authorization string) (int64, bool) {
+		var userID int64
+		var ok bool
+		handler := OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok = UserIDFromContext(r.Context())
+		}), tokens, fakeUsers{ids: map[int64]bool{7: true}})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/bricklink/minifig/sw0001", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return userID, ok
+	}
+
+	t.Run("valid token identifies the user", func(t *testing.T) {
+		token, _, err := tokens.Issue(7)
+		require.NoError(t, err)
+
+		userID, ok := serve("Bearer " + token)
+		assert.True(t, ok)
+		assert.Equal(t, int64(7), userID)
+	})
+
+	t.Run("missing token stays anonymous", func(t *testing.T) {
+		_, ok := serve("")
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid token stays anonymous", func(t *testing.T) {
+		_, ok := serve("Bearer not-a-token")
+		assert.False(t, ok)
+	})
+
+	t.Run("deleted user stays anonymous", func(t *testing.T) {
+		token, _, err := tokens.Issue(8)
+		require.NoError(t, err)
+
+		_, ok := serve("Bearer " + token)
+		assert.False(t, ok)
+	})
+}
+
+func TestUserIDFromContext_Unauthenticated(t *testing.T) {
+	_, ok := UserIDFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context())
+	assert.False(t, ok)
+}
+
+func TestDenyImpersonation(t *testing.T) {
+	tokens := auth.NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour, ImpersonationTTL: time.Minute})
+	handler := RequireAuth(DenyImpersonation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})), tokens, fakeUsers{ids: map[int64]bool{1: true, 7: true}})
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/api/users/7", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	token, _, err := tokens.Issue(7)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, serve(token))
+
+	impersonation, _, err := tokens.IssueImpersonation(7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, serve(impersonation))
+}
+
+func TestRequireAdmin(t *testing.T) {
+	handler := RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), []int64{1})
+
+	serve := func(userID int64) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate/7", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(WithUserID(req.Context(), userID)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(1))
+	assert.Equal(t, http.StatusForbidden, serve(7))
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"LegoManagerAPI/internal/api/response"
+)
+
+// Budget caps the total time a request may take, including everything wrapped inside it
+// A request still running when the budget runs out gets a 503 right away, next keeps running in the
+// background with a cancelled context and whatever it writes afterwards is discarded
+// It keeps its LimitInFlight slot until it returns, handlers derive their contexts from r.Context() so the 503 cancels their work
+// Responses are buffered until next returns so a late write can't interleave with the 503,
+// the writer is no http.Flusher so handlers behind the budget can't stream
+// A budget of 0 or less disables it
+func Budget(next http.Handler, budget time.Duration) http.Handler {
+	if budget <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), budget)
+		defer cancel()
+
+		bw := &budgetWriter{header: make(http.Header), requestID: response.RequestIDFromContext(r.Context())}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			next.ServeHTTP(bw, r.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case <-done:
+			bw.flushTo(w)
+		case <-ctx.Done():
+			bw.expire()
+
+			// A client that went away gets no response at all
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				response.ErrorCode(w, http.StatusServiceUnavailable, response.CodeTimeout, "Request took too long, try again later")
+			}
+		}
+	})
+}
+
+// budgetWriter buffers a response until the handler returns or the budget runs out
+type budgetWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	expired     bool
+
+	// requestID is read from the request context, the buffered header doesn't have X-Request-ID
+	requestID string
+}
+
+func (bw *budgetWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *budgetWriter) WriteHeader(status int) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.expired || bw.wroteHeader {
+		return
+	}
+	bw.status = status
+	bw.wroteHeader = true
+}
+
+func (bw *budgetWriter) Write(b []byte) (int, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.expired {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !bw.wroteHeader {
+		bw.status = http.StatusOK
+		bw.wroteHeader = true
+	}
+	return bw.body.Write(b)
+}
+
+// RequestID lets error responses written into the buffer repeat the request's id
+func (bw *budgetWriter) RequestID() string {
+	return bw.requestID
+}
+
+// expire discards anything written from now on
+func (bw *budgetWriter) expire() {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	bw.expired = true
+}
+
+// flushTo writes the buffered response to w
+func (bw *budgetWriter) flushTo(w http.ResponseWriter) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	dst := w.Header()
+	for key, values := range bw.header {
+		dst[key] = values
+	}
+
+	status := bw.status
+	if !bw.wroteHeader {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(bw.body.Bytes())
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/api/response"
+)
+
+func TestBudget_PassesFastResponsesThrough(t *testing.T) {
+	handler := Budget(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		assert.True(t, hasDeadline, "the handler sees the budget as its deadline")
+
+		w.Header().Set("X-Test", "yes")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}), time.Second)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users", nil))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "yes", rec.Header().Get("X-Test"))
+	assert.Equal(t, "created", rec.Body.String())
+}
+
+func TestBudget_RejectsSlowRequests(t *testing.T) {
+	release := make(chan struct{})
+	finished := make(chan error, 1)
+
+	handler := Budget(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("X-Late", "yes")
+		_, err := w.Write([]byte("too late"))
+		finished <- err
+	}), 20*time.Millisecond)
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/bricklink/minifig/sw0001", nil))
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"code":"TIMEOUT","error":"Request took too long, try again later"}`, rec.Body.String())
+
+	close(release)
+	require.ErrorIs(t, <-finished, http.ErrHandlerTimeout, "late writes are discarded")
+	assert.Empty(t, rec.Header().Get("X-Late"))
+}
+
+func TestBudget_BufferedWriterCannotFlush(t *testing.T) {
+	handler := Budget(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, canFlush := w.(http.Flusher)
+		assert.False(t, canFlush)
+		assert.ErrorIs(t, http.NewResponseController(w).Flush(), http.ErrNotSupported)
+	}), time.Second)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users", nil))
+}
+
+func TestBudget_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := Budget(next, 0)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestBudget_RepanicsHandlerPanics(t *testing.T) {
+	handler := Budget(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), time.Second)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestBudget_ErrorBodiesCarryRequestID(t *testing.T) {
+	handler := LogRequests(Budget(response.WithPrettyPrint(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "true" {
+			<-r.Context().Done()
+			return
+		}
+		response.ErrorCode(w, http.StatusBadRequest, response.CodeInvalidQuery, "Bad query")
+	}), true), 50*time.Millisecond))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("X-Request-ID", "client-42")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":"Bad query","code":"INVALID_QUERY","request_id":"client-42"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users?slow=true", nil)
+	req.Header.Set("X-Request-ID", "client-43")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"error":"Request took too long, try again later","code":"TIMEOUT","request_id":"client-43"}`, rec.Body.String())
+}
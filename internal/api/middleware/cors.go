@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+)
+
+const (
+	corsAllowMethods  = "GET, POST, PUT, DELETE, OPTIONS"
+	corsAllowHeaders  = "Authorization, Content-Type"
+	corsExposeHeaders = "Location, Retry-After, X-Environment, X-Request-ID"
+	corsMaxAge        = "600"
+)
+
+// CORS lets browsers on allowedOrigins call the API and answers preflight requests itself
+// An origin of "*" allows any origin, with allowCredentials the caller's origin is echoed back instead
+// since browsers reject a wildcard on credentialed requests. No allowed origins disables CORS entirely
+func CORS(next http.Handler, allowedOrigins []string, allowCredentials bool) http.Handler {
+	if len(allowedOrigins) == 0 {
+		return next
+	}
+
+	wildcard := slices.Contains(allowedOrigins, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The response depends on the caller's origin, caches must not share it across origins
+		w.Header().Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		allowed := wildcard || slices.Contains(allowedOrigins, origin)
+
+		if allowed {
+			if wildcard && !allowCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if allowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if preflight {
+			// Disallowed origins get no CORS headers, which makes the browser block the real request
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed {
+			w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
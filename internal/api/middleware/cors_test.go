@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveCORS(handler http.Handler, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/users", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := CORS(next, []string{"https://portfolio.example.com"}, false)
+
+	rec := serveCORS(handler, http.MethodGet, "https://portfolio.example.com", false)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://portfolio.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	rec = serveCORS(handler, http.MethodGet, "https://evil.example.com", false)
+	assert.Equal(t, http.StatusOK, rec.Code, "the request is still served, the browser hides the response")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = serveCORS(handler, http.MethodGet, "", false)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), "same-origin and non-browser calls are untouched")
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	handler := CORS(next, []string{"https://portfolio.example.com"}, true)
+
+	rec := serveCORS(handler, http.MethodOptions, "https://portfolio.example.com", true)
+	assert.False(t, called, "preflights are answered by the middleware")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://portfolio.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), "DELETE")
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	rec = serveCORS(handler, http.MethodOptions, "https://evil.example.com", true)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORS_Wildcard(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	rec := serveCORS(CORS(next, []string{"*"}, false), http.MethodGet, "http://localhost:5173", false)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = serveCORS(CORS(next, []string{"*"}, true), http.MethodGet, "http://localhost:5173", false)
+	assert.Equal(t, "http://localhost:5173", rec.Header().Get("Access-Control-Allow-Origin"),
+		"credentialed responses must name the origin, browsers reject a wildcard")
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusMethodNotAllowed) })
+
+	rec := serveCORS(CORS(next, nil, false), http.MethodOptions, "https://portfolio.example.com", true)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
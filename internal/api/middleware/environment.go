@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// Environment sets an X-Environment header on every response so clients can tell which environment served them
+func Environment(next http.Handler, environment string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Environment", environment)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+
+	"LegoManagerAPI/internal/api/response"
+)
+
+// RequireInternal restricts diagnostics to callers presenting token in the X-Health-Token header
+// or connecting from one of networks. With no token and no networks the handler stays open
+// Only the connection's address is checked, X-Forwarded-For can be forged and is ignored
+func RequireInternal(next http.Handler, token string, networks []*net.IPNet) http.Handler {
+	if token == "" && len(networks) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Health-Token")), []byte(token)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if fromNetworks(r.RemoteAddr, networks) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		response.Error(w, http.StatusForbidden, "Diagnostics are only available internally")
+	})
+}
+
+// fromNetworks reports whether remoteAddr's IP lies in one of networks
+func fromNetworks(remoteAddr string, networks []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
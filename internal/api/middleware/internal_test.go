@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireInternal(t *testing.T) {
+	_, private, _ := net.ParseCIDR("10.0.0.0/8")
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := RequireInternal(next, "s3cret", []*net.IPNet{private})
+
+	serve := func(remoteAddr, token string, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("X-Health-Token", token)
+		}
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("10.1.2.3:5000", "", ""), "internal network")
+	assert.Equal(t, http.StatusOK, serve("203.0.113.7:5000", "s3cret", ""), "shared secret")
+	assert.Equal(t, http.StatusForbidden, serve("203.0.113.7:5000", "", ""))
+	assert.Equal(t, http.StatusForbidden, serve("203.0.113.7:5000", "wrong", ""))
+	assert.Equal(t, http.StatusForbidden, serve("203.0.113.7:5000", "", "10.1.2.3"), "forwarded headers are not trusted")
+}
+
+func TestRequireInternal_Unconfigured(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.RemoteAddr = "203.0.113.7:5000"
+	RequireInternal(next, "", nil).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
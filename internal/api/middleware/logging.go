@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"LegoManagerAPI/internal/api/response"
+)
+
+// validRequestID matches ids we accept from an incoming X-Request-ID header, anything else is replaced
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDFromContext returns the id LogRequests assigned to the request, "" outside of it
+func RequestIDFromContext(ctx context.Context) string {
+	return response.RequestIDFromContext(ctx)
+}
+
+// statusRecorder remembers the status code and body size written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status    int
+	bytes     int
+	requestID string
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// RequestID lets error responses repeat the request's id
+func (w *statusRecorder) RequestID() string {
+	return w.requestID
+}
+
+// LogRequests writes an access log line with method, path, status, size and latency for every request
+// Each request gets an id, taken from a well-formed X-Request-ID header or generated, which is echoed
+// back in X-Request-ID and logged so clients can correlate. Health checks are only logged at debug level
+func LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get("X-Request-ID")
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		rec := &statusRecorder{ResponseWriter: w, requestID: requestID}
+		next.ServeHTTP(rec, r.WithContext(response.WithRequestID(r.Context(), requestID)))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		logFn := log.Info
+		if strings.HasPrefix(r.URL.Path, "/health") {
+			logFn = log.Debug
+		}
+		logFn("Request handled",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"latency", time.Since(start),
+			"request_id", requestID)
+	})
+}
+
+// newRequestID returns a random 16 byte hex id
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
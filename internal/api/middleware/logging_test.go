@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLog redirects the global logger into a buffer at level for the duration of the test
+func captureLog(t *testing.T, level log.Level) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := log.GetLevel()
+	log.SetOutput(&buf)
+	log.SetLevel(level)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetLevel(previous)
+	})
+
+	return &buf
+}
+
+func TestLogRequests(t *testing.T) {
+	logs := captureLog(t, log.InfoLevel)
+
+	var seenID string
+	handler := LogRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenID = RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users", nil))
+
+	requestID := rec.Header().Get("X-Request-ID")
+	require.Len(t, requestID, 32)
+	assert.Equal(t, requestID, seenID, "handlers see the id that is echoed back")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+
+	line := logs.String()
+	assert.Contains(t, line, "method=POST")
+	assert.Contains(t, line, "path=/api/users")
+	assert.Contains(t, line, "status=418")
+	assert.Contains(t, line, "bytes=15")
+	assert.Contains(t, line, "latency=")
+	assert.Contains(t, line, "request_id="+requestID)
+}
+
+func TestLogRequests_ImplicitOK(t *testing.T) {
+	logs := captureLog(t, log.InfoLevel)
+
+	handler := LogRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	assert.Contains(t, logs.String(), "status=200")
+}
+
+func TestLogRequests_IncomingRequestID(t *testing.T) {
+	captureLog(t, log.InfoLevel)
+	handler := LogRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("X-Request-ID", "frontend-1234")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "frontend-1234", rec.Header().Get("X-Request-ID"))
+
+	req.Header.Set("X-Request-ID", "bad id\nwith=injected fields")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Len(t, rec.Header().Get("X-Request-ID"), 32, "malformed ids are replaced")
+}
+
+func TestLogRequests_HealthChecksAtDebug(t *testing.T) {
+	logs := captureLog(t, log.InfoLevel)
+	handler := LogRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Empty(t, logs.String())
+
+	log.SetLevel(log.DebugLevel)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Contains(t, logs.String(), "path=/health")
+}
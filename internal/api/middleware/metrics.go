@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// Metrics reports every request's matched route, method, status and duration to observe
+// It must wrap the ServeMux directly, the route comes from the pattern the mux sets on the request
+func Metrics(next http.Handler, observe func(route, method string, status int, duration time.Duration)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		observe(route, r.Method, rec.status, time.Since(start))
+	})
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_ReportsRoutePattern(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("/api/users/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	type observation struct {
+		route, method string
+		status        int
+	}
+	var observed []observation
+	handler := Metrics(router, func(route, method string, status int, duration time.Duration) {
+		observed = append(observed, observation{route, method, status})
+	})
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	assert.Equal(t, []observation{
+		{"/api/users/", http.MethodGet, http.StatusNotFound},
+		{"unmatched", http.MethodGet, http.StatusNotFound},
+	}, observed, "ids in the path must not become label values")
+}
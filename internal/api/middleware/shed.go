@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"LegoManagerAPI/internal/api/response"
+)
+
+// LimitInFlight serves at most maxInFlight requests at once and rejects the rest immediately with a 503
+// and Retry-After, so overload is shed instead of queueing until the DB pool and goroutines run out
+// Requests for bypassPaths (e.g. /health) are never limited, maxInFlight below 1 disables the limit
+func LimitInFlight(next http.Handler, maxInFlight int, bypassPaths ...string) http.Handler {
+	if maxInFlight < 1 {
+		return next
+	}
+
+	slots := make(chan struct{}, maxInFlight)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(bypassPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			response.Error(w, http.StatusServiceUnavailable, "Server is overloaded, try again later")
+		}
+	})
+}
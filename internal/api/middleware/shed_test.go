@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitInFlight_ShedsBeyondCap(t *testing.T) {
+	const maxInFlight = 5
+
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(maxInFlight)
+
+	handler := LimitInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		default:
+			if r.URL.Path != "/health" {
+				started.Done()
+				<-release
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}), maxInFlight, "/health")
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// Occupy every slot with a request that blocks until released
+	var inFlight sync.WaitGroup
+	for i := 0; i < maxInFlight; i++ {
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			resp, err := http.Get(server.URL + "/api/users")
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+		}()
+	}
+	started.Wait()
+
+	// Everything past the cap must be rejected fast rather than wait for a slot
+	client := &http.Client{Timeout: time.Second}
+	var shed sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		shed.Add(1)
+		go func() {
+			defer shed.Done()
+			start := time.Now()
+			resp, err := client.Get(server.URL + "/api/users")
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+				assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+				assert.Less(t, time.Since(start), 500*time.Millisecond)
+			}
+		}()
+	}
+	shed.Wait()
+
+	// Health checks bypass the limit even while it is saturated
+	resp, err := client.Get(server.URL + "/health")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	close(release)
+	inFlight.Wait()
+
+	// Freed slots are reused
+	resp, err = client.Get(server.URL + "/api/users")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Location returns the IANA timezone requested with ?tz=, or nil when the parameter is absent
+// "Local" is rejected so responses never depend on the server's own timezone, see LoadTimezone
+func Location(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return nil, nil
+	}
+
+	return LoadTimezone(tz)
+}
+
+// LoadTimezone loads an IANA timezone by name, the "timezone" validation tag accepts exactly the names it loads
+// "" and "Local" are rejected, time.LoadLocation would map them to UTC and the server's own timezone
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+
+	return loc, nil
+}
@@ -0,0 +1,26 @@
+package request_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/api/request"
+)
+
+func TestLocation(t *testing.T) {
+	loc, err := request.Location(httptest.NewRequest("GET", "/api/users/1", nil))
+	require.NoError(t, err)
+	assert.Nil(t, loc, "no tz param should leave timestamps untouched")
+
+	loc, err = request.Location(httptest.NewRequest("GET", "/api/users/1?tz=Europe/Berlin", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	for _, tz := range []string{"Mars/Olympus", "Local"} {
+		_, err = request.Location(httptest.NewRequest("GET", "/api/users/1?tz="+tz, nil))
+		assert.Error(t, err, tz)
+	}
+}
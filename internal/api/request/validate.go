@@ -0,0 +1,138 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"LegoManagerAPI/internal/api/response"
+	"LegoManagerAPI/internal/config/bricklink"
+)
+
+var validate = newValidator()
+
+// emailPattern accepts a local part, an @ and a dotted domain, without whitespace
+// Deliberately loose, the only real proof an address works is mail reaching it
+var emailPattern = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@.]+$`)
+
+// usernamePattern keeps usernames safe to show in URLs and logs
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// newValidator creates a validator that reports fields by their json names
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	// emailaddr and timezone accept "" and leave requiring a value to required, so an optional pointer field
+	// can be cleared with "" even though omitempty only skips nil pointers
+	v.RegisterValidation("emailaddr", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "" || emailPattern.MatchString(fl.Field().String())
+	})
+
+	v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return usernamePattern.MatchString(fl.Field().String())
+	})
+
+	v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return strongPassword(fl.Field().String())
+	})
+
+	v.RegisterValidation("itemtype", func(fl validator.FieldLevel) bool {
+		return slices.Contains(bricklink.ItemTypes, fl.Field().String())
+	})
+
+	// Replaces the built-in tag so stored timezones are exactly those ?tz= accepts
+	v.RegisterValidation("timezone", func(fl validator.FieldLevel) bool {
+		if fl.Field().String() == "" {
+			return true
+		}
+		_, err := LoadTimezone(fl.Field().String())
+		return err == nil
+	})
+
+	return v
+}
+
+// strongPassword reports whether password mixes letters with digits or symbols
+// Length is checked separately through the min tag
+func strongPassword(password string) bool {
+	var letter, other bool
+	for _, r := range password {
+		if unicode.IsLetter(r) {
+			letter = true
+		} else if !unicode.IsSpace(r) {
+			other = true
+		}
+	}
+	return letter && other
+}
+
+// Validate checks a DTO against its `validate` struct tags
+// Returns nil when the DTO is valid, otherwise one entry per failing field
+func Validate(dto interface{}) []response.ValidationError {
+	err := validate.Struct(dto)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []response.ValidationError{{Field: "", Message: err.Error()}}
+	}
+
+	errs := make([]response.ValidationError, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		errs = append(errs, response.ValidationError{
+			Field:   fe.Field(),
+			Message: message(fe),
+		})
+	}
+
+	return errs
+}
+
+// message translates a validator tag failure into a human readable message
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "iso4217":
+		return "must be a valid ISO 4217 currency code"
+	case "timezone":
+		return "must be a valid IANA timezone"
+	case "emailaddr":
+		return "must be a valid email address"
+	case "username":
+		return "may only contain letters, digits, dots, dashes and underscores"
+	case "password":
+		return "must contain letters and at least one digit or symbol"
+	case "itemtype":
+		return "must be one of " + strings.Join(bricklink.ItemTypes, ", ")
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed %s validation", fe.Tag())
+	}
+}
@@ -0,0 +1,136 @@
+package request_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"LegoManagerAPI/internal/api/dto"
+	"LegoManagerAPI/internal/api/request"
+	"LegoManagerAPI/internal/api/response"
+)
+
+func TestValidate_Valid(t *testing.T) {
+	req := dto.CreateUserRequest{
+		Username:  "brickfan",
+		Password:  "supersecret1",
+		FirstName: "Emmet",
+		LastName:  "Brickowski",
+	}
+
+	assert.Nil(t, request.Validate(req))
+}
+
+func TestValidate_FieldErrors(t *testing.T) {
+	req := dto.CreateUserRequest{
+		Username:  "ab",
+		Password:  "",
+		FirstName: "Emmet",
+		LastName:  "Brickowski",
+	}
+
+	errs := request.Validate(req)
+
+	assert.ElementsMatch(t, []response.ValidationError{
+		{Field: "username", Message: "must be at least 3 characters"},
+		{Field: "password", Message: "is required"},
+	}, errs)
+}
+
+func TestValidate_Email(t *testing.T) {
+	req := dto.CreateUserRequest{
+		Username:  "brickfan",
+		Password:  "supersecret1",
+		FirstName: "Emmet",
+		LastName:  "Brickowski",
+	}
+
+	for _, email := range []string{"emmet@bricksburg.com", "emmet.b+lego@mail.bricksburg.co.uk"} {
+		req.Email = email
+		assert.Nil(t, request.Validate(req), email)
+	}
+
+	for _, email := range []string{"emmet", "emmet@", "emmet@bricksburg", "emmet @bricksburg.com", "emmet@bricksburg."} {
+		req.Email = email
+		assert.Equal(t, []response.ValidationError{
+			{Field: "email", Message: "must be a valid email address"},
+		}, request.Validate(req), email)
+	}
+}
+
+func TestValidate_Timezone(t *testing.T) {
+	req := dto.UpdateUserRequest{
+		Username:  "brickfan",
+		FirstName: "Emmet",
+		LastName:  "Brickowski",
+	}
+
+	// Exactly the names ?tz= accepts
+	for _, tz := range []string{"Mars/Olympus", "Local", "local"} {
+		req.Timezone = &tz
+		assert.Equal(t, []response.ValidationError{
+			{Field: "timezone", Message: "must be a valid IANA timezone"},
+		}, request.Validate(req), tz)
+	}
+
+	for _, tz := range []string{"America/New_York", "UTC", ""} {
+		req.Timezone = &tz
+		assert.Nil(t, request.Validate(req), "%q", tz)
+	}
+
+	req.Timezone = nil
+	assert.Nil(t, request.Validate(req))
+}
+
+func TestValidate_UsernameCharset(t *testing.T) {
+	req := dto.CreateUserRequest{
+		Username:  "brick.fan_2-go",
+		Password:  "supersecret1",
+		FirstName: "Emmet",
+		LastName:  "Brickowski",
+	}
+	assert.Nil(t, request.Validate(req))
+
+	for _, username := range []string{"brick fan", "brick/fan", "<script>", "émmet"} {
+		req.Username = username
+		assert.Equal(t, []response.ValidationError{
+			{Field: "username", Message: "may only contain letters, digits, dots, dashes and underscores"},
+		}, request.Validate(req), username)
+	}
+}
+
+func TestValidate_PasswordStrength(t *testing.T) {
+	req := dto.UpdatePasswordRequest{OldPassword: "anything"}
+
+	for _, password := range []string{"supersecret1", "brick-by-brick", "Ünïcode!pass"} {
+		req.NewPassword = password
+		assert.Nil(t, request.Validate(req), password)
+	}
+
+	for _, password := range []string{"supersecret", "1234567890", "!!!!!!!!!!"} {
+		req.NewPassword = password
+		assert.Equal(t, []response.ValidationError{
+			{Field: "new_password", Message: "must contain letters and at least one digit or symbol"},
+		}, request.Validate(req), password)
+	}
+
+	req.NewPassword = "short1"
+	assert.Equal(t, []response.ValidationError{
+		{Field: "new_password", Message: "must be at least 8 characters"},
+	}, request.Validate(req))
+}
+
+func TestValidate_WishlistItem(t *testing.T) {
+	zero := 0.0
+	req := dto.CreateWishlistItemRequest{ItemType: "MINIFIG", ItemID: "sw0001", Condition: "U"}
+	assert.Nil(t, request.Validate(req))
+
+	req.ItemType = "CASTLE"
+	req.Condition = "X"
+	req.TargetPrice = &zero
+	errs := request.Validate(req)
+
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs, response.ValidationError{Field: "condition", Message: "must be one of N, U"})
+	assert.Contains(t, errs, response.ValidationError{Field: "target_price", Message: "must be greater than 0"})
+}
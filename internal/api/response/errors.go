@@ -0,0 +1,110 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Code is a stable machine-readable error category, clients branch on it instead of the message
+type Code string
+
+const (
+	// CodeUnknown is sent by Error for failures that have no specific code yet
+	CodeUnknown Code = "UNKNOWN"
+
+	// CodeInternal is an unexpected server side failure, retrying may help
+	CodeInternal Code = "INTERNAL_ERROR"
+
+	// CodeTimeout means the request ran out of time on the server, retrying later may help
+	CodeTimeout Code = "TIMEOUT"
+
+	// CodeNotFound means no route serves the requested path
+	CodeNotFound Code = "NOT_FOUND"
+
+	// CodeMethodNotAllowed means the route exists but doesn't serve the request's method, Allow lists those it does
+	CodeMethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+
+	// CodeInvalidBody means the request body is not valid JSON for the endpoint
+	CodeInvalidBody Code = "INVALID_BODY"
+
+	// CodeValidationFailed means some fields are invalid, details lists them as ValidationError
+	CodeValidationFailed Code = "VALIDATION_FAILED"
+
+	// CodeInvalidID means the id in the path is malformed
+	CodeInvalidID Code = "INVALID_ID"
+
+	// CodeInvalidQuery means a query parameter is missing or malformed
+	CodeInvalidQuery Code = "INVALID_QUERY"
+
+	// CodeInvalidTimezone means the requested timezone is not an IANA name
+	CodeInvalidTimezone Code = "INVALID_TIMEZONE"
+
+	// CodeUserNotFound means the user does not exist or is not visible to the caller
+	CodeUserNotFound Code = "USER_NOT_FOUND"
+
+	// CodeUserDeleted means the user was deleted and can't be changed anymore
+	CodeUserDeleted Code = "USER_DELETED"
+
+	// CodeUsernameTaken means another user already has the username
+	CodeUsernameTaken Code = "USERNAME_TAKEN"
+
+	// CodeEmailTaken means another user already has the email
+	CodeEmailTaken Code = "EMAIL_TAKEN"
+
+	// CodeInvalidPassword means the current password given to change it is wrong
+	CodeInvalidPassword Code = "INVALID_PASSWORD"
+
+	// CodeCredentialsRejected means BrickLink refused the credentials an admin tried to rotate to
+	CodeCredentialsRejected Code = "CREDENTIALS_REJECTED"
+
+	// CodeWishlistItemNotFound means the wishlist item does not exist or belongs to another user
+	CodeWishlistItemNotFound Code = "WISHLIST_ITEM_NOT_FOUND"
+
+	// CodeWishlistItemExists means the item is already on the wishlist in the same condition
+	CodeWishlistItemExists Code = "WISHLIST_ITEM_EXISTS"
+)
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Code    Code   `json:"code"`
+	Details any    `json:"details,omitempty"`
+
+	// RequestID repeats the X-Request-ID response header so a reported error body can be found in the logs
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Error writes an error JSON response with CodeUnknown, prefer ErrorCode for new errors
+func Error(res http.ResponseWriter, status int, message string) {
+	ErrorCode(res, status, CodeUnknown, message)
+}
+
+// ErrorCode writes an error JSON response carrying a machine-readable code
+func ErrorCode(res http.ResponseWriter, status int, code Code, message string) {
+	ErrorDetails(res, status, code, message, nil)
+}
+
+// ErrorDetails writes an error JSON response with extra details, e.g. the invalid fields of a request
+func ErrorDetails(res http.ResponseWriter, status int, code Code, message string, details any) {
+	JSON(res, status, ErrorResponse{
+		Error:   message,
+		Code:    code,
+		Details: details,
+
+		// Assigned by the request logging middleware, writers in the chain report it from the request context
+		RequestID: requestID(res),
+	})
+}
+
+// NotFound writes the 404 for a path no route serves
+func NotFound(res http.ResponseWriter) {
+	ErrorCode(res, http.StatusNotFound, CodeNotFound, "Route not found")
+}
+
+// MethodNotAllowed writes the 405 for a route that doesn't serve the request's method, allowed is sent in Allow
+func MethodNotAllowed(res http.ResponseWriter, allowed ...string) {
+	if len(allowed) > 0 {
+		res.Header().Set("Allow", strings.Join(allowed, ", "))
+	}
+	ErrorCode(res, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed")
+}
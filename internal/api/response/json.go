@@ -1,25 +1,62 @@
 package response
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 
 	"github.com/charmbracelet/log"
 )
 
+// prettyPrinter is implemented by response writers that requested indented JSON
+type prettyPrinter interface {
+	PrettyJSON() bool
+}
+
+// prettyResponseWriter marks a response as wanting indented JSON
+type prettyResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *prettyResponseWriter) PrettyJSON() bool {
+	return true
+}
+
+// Unwrap lets http.ResponseController and error responses reach the underlying writer
+func (w *prettyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithPrettyPrint lets clients request indented JSON with ?pretty=true when allowed
+func WithPrettyPrint(next http.Handler, allowed bool) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if allowed && req.URL.Query().Get("pretty") == "true" {
+			res = &prettyResponseWriter{ResponseWriter: res}
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
 // JSON writes a JSON response
+// The body is encoded into a buffer first so an encoding failure never sends a partial response
 func JSON(res http.ResponseWriter, status int, data interface{}) {
-	res.Header().Set("Content-Type", "application/json")
-	res.WriteHeader(status)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
 
-	if err := json.NewEncoder(res).Encode(data); err != nil {
+	if p, ok := res.(prettyPrinter); ok && p.PrettyJSON() {
+		encoder.SetIndent("", "  ")
+	}
+
+	if err := encoder.Encode(data); err != nil {
 		log.Error("Failed to encode JSON response", "error", err)
+		http.Error(res, "Internal server error", http.StatusInternalServerError)
+		return
 	}
-}
 
-// Error writes an error JSON response
-func Error(res http.ResponseWriter, status int, message string) {
-	JSON(res, status, map[string]string{
-		"error": message,
-	})
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+
+	if _, err := res.Write(buf.Bytes()); err != nil {
+		log.Error("Failed to write JSON response", "error", err)
+	}
 }
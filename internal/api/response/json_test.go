@@ -0,0 +1,105 @@
+package response_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"LegoManagerAPI/internal/api/response"
+)
+
+func TestJSON_PrettyPrint(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	tests := []struct {
+		name     string
+		allowed  bool
+		target   string
+		expected string
+	}{
+		{"compact by default", true, "/", "{\"status\":\"ok\"}\n"},
+		{"indented with flag", true, "/?pretty=true", "{\n  \"status\": \"ok\"\n}\n"},
+		{"compact when not allowed", false, "/?pretty=true", "{\"status\":\"ok\"}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			response.WithPrettyPrint(handler, tt.allowed).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expected, rec.Body.String())
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	response.ErrorCode(rec, http.StatusConflict, response.CodeUsernameTaken, "Username already exists")
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.JSONEq(t, `{"error":"Username already exists","code":"USERNAME_TAKEN"}`, rec.Body.String())
+}
+
+func TestError_UsesUnknownCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	response.Error(rec, http.StatusTeapot, "Short and stout")
+
+	assert.JSONEq(t, `{"error":"Short and stout","code":"UNKNOWN"}`, rec.Body.String())
+}
+
+func TestValidationErrors_ListsFieldsInDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	response.ValidationErrors(rec, []response.ValidationError{{Field: "username", Message: "is required"}})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{
+		"error": "Validation failed",
+		"code": "VALIDATION_FAILED",
+		"details": [{"field": "username", "message": "is required"}]
+	}`, rec.Body.String())
+}
+
+// idWriter is a response writer that knows its request id, as the request logging middleware's does
+type idWriter struct {
+	*httptest.ResponseRecorder
+	id string
+}
+
+func (w *idWriter) RequestID() string {
+	return w.id
+}
+
+func TestErrorCode_RepeatsRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	response.NotFound(&idWriter{ResponseRecorder: rec, id: "abc123"})
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error":"Route not found","code":"NOT_FOUND","request_id":"abc123"}`, rec.Body.String())
+}
+
+func TestErrorCode_RequestIDThroughPrettyPrint(t *testing.T) {
+	handler := response.WithPrettyPrint(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.NotFound(w)
+	}), true)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(&idWriter{ResponseRecorder: rec, id: "abc123"}, httptest.NewRequest(http.MethodGet, "/?pretty=true", nil))
+
+	assert.JSONEq(t, `{"error":"Route not found","code":"NOT_FOUND","request_id":"abc123"}`, rec.Body.String())
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	response.MethodNotAllowed(rec, http.MethodGet, http.MethodPost)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get("Allow"))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"Method not allowed","code":"METHOD_NOT_ALLOWED"}`, rec.Body.String())
+}
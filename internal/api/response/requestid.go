@@ -0,0 +1,42 @@
+package response
+
+import (
+	"context"
+	"net/http"
+)
+
+// requestIDKey is the context key holding the request's id
+type requestIDKey struct{}
+
+// requestIDWriter is implemented by response writers that know the id of the request they answer
+type requestIDWriter interface {
+	RequestID() string
+}
+
+// WithRequestID returns a copy of ctx carrying the request's id
+// It lives here rather than in middleware so error responses can repeat the id without an import cycle
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the id stored by WithRequestID, "" without one
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestID returns the id of the request res answers, looking through wrapping writers
+// Middleware that replaces the writer, e.g. to buffer the response, reports the id from the request context
+func requestID(res http.ResponseWriter) string {
+	for res != nil {
+		if w, ok := res.(requestIDWriter); ok {
+			return w.RequestID()
+		}
+		u, ok := res.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return ""
+		}
+		res = u.Unwrap()
+	}
+	return ""
+}
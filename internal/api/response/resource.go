@@ -0,0 +1,18 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResourceURL builds the canonical URL of a resource in a collection, e.g. /api/users/42
+func ResourceURL(collection string, id int64) string {
+	return strings.TrimSuffix(collection, "/") + "/" + strconv.FormatInt(id, 10)
+}
+
+// Created writes a 201 JSON response with a Location header pointing at the created resource
+func Created(res http.ResponseWriter, location string, data interface{}) {
+	res.Header().Set("Location", location)
+	JSON(res, http.StatusCreated, data)
+}
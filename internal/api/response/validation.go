@@ -0,0 +1,17 @@
+package response
+
+import (
+	"net/http"
+)
+
+// ValidationError describes a single request field that failed validation
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors writes a 422 response with CodeValidationFailed, listing every invalid field in details
+// A body that is well-formed JSON but fails validation is unprocessable rather than a bad request
+func ValidationErrors(res http.ResponseWriter, errs []ValidationError) {
+	ErrorDetails(res, http.StatusUnprocessableEntity, CodeValidationFailed, "Validation failed", errs)
+}
@@ -12,10 +12,16 @@ import (
 	"LegoManagerAPI/internal/api/handlers"
 	health2 "LegoManagerAPI/internal/api/handlers/health"
 	checks2 "LegoManagerAPI/internal/api/handlers/health/checks"
+	"LegoManagerAPI/internal/api/middleware"
+	"LegoManagerAPI/internal/api/response"
 	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/auth"
 	"LegoManagerAPI/internal/cache"
 	"LegoManagerAPI/internal/config"
 	"LegoManagerAPI/internal/database"
+	"LegoManagerAPI/internal/events"
+	"LegoManagerAPI/internal/jobs"
+	"LegoManagerAPI/internal/metrics"
 	"LegoManagerAPI/internal/repos"
 )
 
@@ -23,31 +29,157 @@ type Server struct {
 	httpServer    *http.Server
 	cfg           *config.Config
 	HealthService *health2.Service
+
+	// PortfolioSnapshots records the portfolio value history once started, nil when BrickLink is not configured
+	// or PORTFOLIO_SNAPSHOT_INTERVAL is 0
+	PortfolioSnapshots *jobs.PortfolioSnapshots
 }
 
-func NewServer(cfg *config.Config, db *database.PostgresDB, redisClient *cache.RedisClient, bricklinkService *service.BricklinkService) *Server {
+func NewServer(cfg *config.Config, db *database.PostgresDB, redisClient *cache.RedisClient, bricklinkService *service.BricklinkService, bus *events.Bus) *Server {
 	// Health checks
 	healthCheckers := []health2.Checker{
 		checks2.NewPostgresCheck(db),
 		checks2.NewRedisCheck(redisClient),
 		checks2.NewApplicationCheck(),
 	}
+	if bricklinkService != nil {
+		healthCheckers = append(healthCheckers, checks2.NewBricklinkCheck(bricklinkService))
+	}
 	healthService := health2.NewService(cfg.App.Environment, healthCheckers...)
+	healthService.CacheResults(cfg.App.HealthCacheTTL)
+	healthService.TimeoutChecks(cfg.App.HealthCheckTimeout)
 
 	// Initialize repositories
 	userRepo := repos.NewUserRepository(db.Pool)
+	userRepo.SetRetryPolicy(repos.RetryPolicy{
+		MaxAttempts: cfg.Database.RetryAttempts,
+		BaseDelay:   cfg.Database.RetryBaseDelay,
+		MaxDelay:    cfg.Database.RetryMaxDelay,
+	})
+	collectionRepo := repos.NewCollectionRepository(db.Pool)
+	collectionRepo.SetRetryPolicy(repos.RetryPolicy{
+		MaxAttempts: cfg.Database.RetryAttempts,
+		BaseDelay:   cfg.Database.RetryBaseDelay,
+		MaxDelay:    cfg.Database.RetryMaxDelay,
+	})
+	wishlistRepo := repos.NewWishlistRepository(db.Pool)
+	wishlistRepo.SetRetryPolicy(repos.RetryPolicy{
+		MaxAttempts: cfg.Database.RetryAttempts,
+		BaseDelay:   cfg.Database.RetryBaseDelay,
+		MaxDelay:    cfg.Database.RetryMaxDelay,
+	})
+	snapshotRepo := repos.NewSnapshotRepository(db.Pool)
+	snapshotRepo.SetRetryPolicy(repos.RetryPolicy{
+		MaxAttempts: cfg.Database.RetryAttempts,
+		BaseDelay:   cfg.Database.RetryBaseDelay,
+		MaxDelay:    cfg.Database.RetryMaxDelay,
+	})
+
+	// Shared BrickLink client, every feature calling BrickLink should go through it
+	// It stays nil when BrickLink is not configured, the handler then answers 503
+	var bricklinkClient *service.CachedBricklinkClient
+	if bricklinkService != nil {
+		bricklinkClient = service.NewCachedBricklinkClient(bricklinkService, redisClient, cfg.Bricklink.CacheTTL, cfg.Bricklink.MaxConcurrency)
+	}
+
+	// Portfolio value history, main starts the job and stops it on shutdown
+	var portfolioSnapshots *jobs.PortfolioSnapshots
+	if bricklinkClient != nil && cfg.App.PortfolioSnapshotInterval > 0 {
+		portfolioSnapshots = jobs.NewPortfolioSnapshots(userRepo, collectionRepo, snapshotRepo, bricklinkClient, cfg.App.PortfolioSnapshotInterval, cfg.App.PortfolioSnapshotQuotaReserve)
+	}
+
+	// Prometheus metrics, the pool gauges are read from the pool on every scrape
+	appMetrics := metrics.New()
+	appMetrics.Register(metrics.NewPoolCollector(db.Stats))
+	if bricklinkService != nil {
+		bricklinkService.ObserveRequests(appMetrics.ObserveBricklink)
+	}
+
+	// Signs and verifies login tokens
+	tokens := auth.NewTokenManager(cfg.Auth)
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(healthService)
-	userHandler := handlers.NewUserHandler(userRepo)
-	bricklinkHandler := handlers.NewBricklinkHandler(bricklinkService)
+	userHandler := handlers.NewUserHandler(userRepo, cfg.App.BcryptCost, bus)
+	bricklinkHandler := handlers.NewBricklinkHandler(bricklinkClient, service.NewRecentlyViewed(redisClient, cfg.Bricklink.RecentlyViewedLimit), userRepo)
+	authHandler := handlers.NewAuthHandler(userRepo, tokens, cfg.App.BcryptCost)
+	adminHandler := handlers.NewAdminHandler(userRepo, tokens)
+	portfolioHandler := handlers.NewPortfolioHandler(userRepo, collectionRepo, snapshotRepo, bricklinkClient)
+	wishlistHandler := handlers.NewWishlistHandler(userRepo, wishlistRepo, bricklinkClient)
 
 	// Setup router
 	router := http.NewServeMux()
 
 	// Register routes
 	router.HandleFunc("/", handleRoot)
-	router.HandleFunc("/health", healthHandler.Handle)
+	router.Handle("/metrics", appMetrics.Handler())
+
+	// Health checks expose dependency errors and the environment, they can be restricted to internal callers
+	// The liveness probe reveals nothing and always stays open
+	router.HandleFunc("/health/live", healthHandler.HandleLive)
+	router.Handle("/health", middleware.RequireInternal(
+		http.HandlerFunc(healthHandler.Handle), cfg.App.HealthToken, cfg.App.HealthAllowedNetworks,
+	))
+	router.Handle("/health/ready", middleware.RequireInternal(
+		http.HandlerFunc(healthHandler.HandleReady), cfg.App.HealthToken, cfg.App.HealthAllowedNetworks,
+	))
+
+	// Dependency diagnostics for internal dashboards, admins only
+	router.Handle("/health/detailed", middleware.RequireInternal(
+		middleware.RequireAuth(
+			middleware.RequireAdmin(http.HandlerFunc(healthHandler.HandleDetailed), cfg.Auth.AdminUserIDs),
+			tokens, userRepo,
+		),
+		cfg.App.HealthToken, cfg.App.HealthAllowedNetworks,
+	))
+
+	// Auth routes
+	router.HandleFunc("/api/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		authHandler.Login(w, r)
+	})
+
+	// Admin routes, an impersonation token can't be used to start another impersonation
+	impersonate := middleware.RequireAuth(middleware.DenyImpersonation(
+		middleware.RequireAdmin(http.HandlerFunc(adminHandler.Impersonate), cfg.Auth.AdminUserIDs),
+	), tokens, userRepo)
+
+	router.HandleFunc("/api/admin/impersonate/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		impersonate.ServeHTTP(w, r)
+	})
+
+	// Swaps the BrickLink API credentials of the running server
+	rotateBricklinkCredentials := middleware.RequireAuth(middleware.DenyImpersonation(
+		middleware.RequireAdmin(http.HandlerFunc(bricklinkHandler.RotateCredentials), cfg.Auth.AdminUserIDs),
+	), tokens, userRepo)
+
+	router.HandleFunc("/api/admin/bricklink/credentials", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		rotateBricklinkCredentials.ServeHTTP(w, r)
+	})
+
+	// Creates or updates users from an external system, admins only since a sync overwrites an existing user
+	syncUser := middleware.RequireAuth(middleware.DenyImpersonation(
+		middleware.RequireAdmin(http.HandlerFunc(userHandler.SyncUser), cfg.Auth.AdminUserIDs),
+	), tokens, userRepo)
+
+	router.HandleFunc("/api/admin/users/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			response.MethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		syncUser.ServeHTTP(w, r)
+	})
 
 	// User routes
 	router.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
@@ -62,17 +194,97 @@ func NewServer(cfg *config.Config, db *database.PostgresDB, redisClient *cache.R
 		case http.MethodPost:
 			userHandler.CreateUser(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.MethodNotAllowed(w, http.MethodGet, http.MethodPost)
 		}
 	})
 
+	// User mutations require a valid login token, reads stay public
+	// Support staff impersonating a user can't change the password or delete the account
+	updatePassword := middleware.RequireAuth(middleware.DenyImpersonation(http.HandlerFunc(userHandler.UpdatePassword)), tokens, userRepo)
+	// Impersonation may edit the profile, but UpdateUser itself refuses to change the email
+	updateUser := middleware.RequireAuth(http.HandlerFunc(userHandler.UpdateUser), tokens, userRepo)
+
+	// Profiles are public, the user themselves also sees their email
+	getUser := middleware.OptionalAuth(http.HandlerFunc(userHandler.GetUser), tokens, userRepo)
+	deleteUser := middleware.RequireAuth(middleware.DenyImpersonation(http.HandlerFunc(userHandler.DeleteUser)), tokens, userRepo)
+
+	// A collection's value and the wishlist are private to their owner
+	portfolioValue := middleware.RequireAuth(http.HandlerFunc(portfolioHandler.Value), tokens, userRepo)
+	portfolioHistory := middleware.RequireAuth(http.HandlerFunc(portfolioHandler.History), tokens, userRepo)
+	listWishlist := middleware.RequireAuth(http.HandlerFunc(wishlistHandler.List), tokens, userRepo)
+	createWishlistItem := middleware.RequireAuth(http.HandlerFunc(wishlistHandler.Create), tokens, userRepo)
+	updateWishlistItem := middleware.RequireAuth(http.HandlerFunc(wishlistHandler.Update), tokens, userRepo)
+	deleteWishlistItem := middleware.RequireAuth(http.HandlerFunc(wishlistHandler.Delete), tokens, userRepo)
+	wishlistDeals := middleware.RequireAuth(http.HandlerFunc(wishlistHandler.Deals), tokens, userRepo)
+
+	// More specific than /api/users/, so "me" is never looked up as a user id
+	recentlyViewed := middleware.RequireAuth(http.HandlerFunc(bricklinkHandler.RecentlyViewed), tokens, userRepo)
+
+	router.HandleFunc("/api/users/me/recent", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		recentlyViewed.ServeHTTP(w, r)
+	})
+
 	router.HandleFunc("/api/users/", func(w http.ResponseWriter, r *http.Request) {
+		// Check if it's a portfolio valuation or its history
+		if strings.HasSuffix(r.URL.Path, "/portfolio/value") {
+			if r.Method == http.MethodGet {
+				portfolioValue.ServeHTTP(w, r)
+			} else {
+				response.MethodNotAllowed(w, http.MethodGet)
+			}
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/portfolio/history") {
+			if r.Method == http.MethodGet {
+				portfolioHistory.ServeHTTP(w, r)
+			} else {
+				response.MethodNotAllowed(w, http.MethodGet)
+			}
+			return
+		}
+
+		// Check if it's the wishlist, its deals or one of its items
+		if strings.HasSuffix(r.URL.Path, "/wishlist") {
+			switch r.Method {
+			case http.MethodGet:
+				listWishlist.ServeHTTP(w, r)
+			case http.MethodPost:
+				createWishlistItem.ServeHTTP(w, r)
+			default:
+				response.MethodNotAllowed(w, http.MethodGet, http.MethodPost)
+			}
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/wishlist/deals") {
+			if r.Method == http.MethodGet {
+				wishlistDeals.ServeHTTP(w, r)
+			} else {
+				response.MethodNotAllowed(w, http.MethodGet)
+			}
+			return
+		}
+		if strings.Contains(r.URL.Path, "/wishlist/") {
+			switch r.Method {
+			case http.MethodPut:
+				updateWishlistItem.ServeHTTP(w, r)
+			case http.MethodDelete:
+				deleteWishlistItem.ServeHTTP(w, r)
+			default:
+				response.MethodNotAllowed(w, http.MethodPut, http.MethodDelete)
+			}
+			return
+		}
+
 		// Check if it's a password update
 		if strings.HasSuffix(r.URL.Path, "/password") {
 			if r.Method == http.MethodPost {
-				userHandler.UpdatePassword(w, r)
+				updatePassword.ServeHTTP(w, r)
 			} else {
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				response.MethodNotAllowed(w, http.MethodPost)
 			}
 			return
 		}
@@ -80,26 +292,86 @@ func NewServer(cfg *config.Config, db *database.PostgresDB, redisClient *cache.R
 		// Regular user CRUD
 		switch r.Method {
 		case http.MethodGet:
-			userHandler.GetUser(w, r)
+			getUser.ServeHTTP(w, r)
 		case http.MethodPut:
-			userHandler.UpdateUser(w, r)
+			updateUser.ServeHTTP(w, r)
 		case http.MethodDelete:
-			userHandler.DeleteUser(w, r)
+			deleteUser.ServeHTTP(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.MethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
 		}
 	})
 
+	// Catalog lookups stay public, a logged in user gets prices in their preferred currency by default
+	// and their minifig lookups are added to their recently viewed list
+	getMinifig := middleware.OptionalAuth(http.HandlerFunc(bricklinkHandler.GetMinifig), tokens, userRepo)
+	getSet := middleware.OptionalAuth(http.HandlerFunc(bricklinkHandler.GetSet), tokens, userRepo)
+	getItem := middleware.OptionalAuth(http.HandlerFunc(bricklinkHandler.GetItem), tokens, userRepo)
+
 	router.HandleFunc("/api/bricklink/minifig/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodGet {
-			bricklinkHandler.GetMinifig(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, http.MethodGet)
+			return
+		}
+
+		// Check if it's an existence check
+		if strings.HasSuffix(r.URL.Path, "/exists") {
+			bricklinkHandler.MinifigExists(w, r)
+			return
+		}
+
+		getMinifig.ServeHTTP(w, r)
+	})
+
+	router.HandleFunc("/api/bricklink/set/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, http.MethodGet)
+			return
+		}
+
+		getSet.ServeHTTP(w, r)
+	})
+
+	router.HandleFunc("/api/bricklink/items/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, http.MethodGet)
+			return
 		}
+
+		getItem.ServeHTTP(w, r)
 	})
+
+	router.HandleFunc("/api/bricklink/colors/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.MethodNotAllowed(w, http.MethodGet)
+			return
+		}
+
+		bricklinkHandler.GetColor(w, r)
+	})
+
+	// Wrap the router with middleware, innermost first
+	var handler http.Handler = response.WithPrettyPrint(router, cfg.App.AllowPrettyJSON)
+
+	// Outside WithPrettyPrint so handlers still see its writer, and before anything copies the request
+	// so the route pattern set by the router is visible
+	handler = middleware.Metrics(handler, appMetrics.ObserveHTTP)
+	if cfg.App.ExposeEnvironment {
+		handler = middleware.Environment(handler, cfg.App.Environment)
+	}
+	handler = middleware.LimitInFlight(handler, cfg.App.MaxInFlightRequests, "/health", "/health/live", "/health/ready", "/metrics")
+
+	// Caps the whole chain below, also for handlers whose own timeouts are longer
+	// CORS and the access log stay outside so a 503 from it still carries CORS headers and gets logged
+	handler = middleware.Budget(handler, cfg.App.RequestBudget)
+
+	// Outermost so preflights never take an in-flight slot and shed responses still carry CORS headers
+	handler = middleware.CORS(handler, cfg.App.AllowedOrigins, cfg.App.CORSAllowCredentials)
+	handler = middleware.LogRequests(handler)
+
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.App.Port),
-		Handler:      router,
+		Handler:      handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -109,6 +381,8 @@ func NewServer(cfg *config.Config, db *database.PostgresDB, redisClient *cache.R
 		httpServer:    server,
 		cfg:           cfg,
 		HealthService: healthService,
+
+		PortfolioSnapshots: portfolioSnapshots,
 	}
 }
 
@@ -133,7 +407,13 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// handleRoot greets on / and answers every path no other route matches with a JSON 404
 func handleRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		response.NotFound(w)
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Hello World!"))
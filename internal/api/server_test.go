@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"LegoManagerAPI/internal/api/middleware"
+)
+
+func TestHandleRoot_UnknownRoutesAreJSON(t *testing.T) {
+	handler := middleware.LogRequests(http.HandlerFunc(handleRoot))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Hello World!", rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/typo", nil)
+	req.Header.Set("X-Request-ID", "typo-1")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"Route not found","code":"NOT_FOUND","request_id":"typo-1"}`, rec.Body.String())
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	// ErrUnknownColor is returned for color ids that are not in BrickLink's color list
+	ErrUnknownColor = errors.New("unknown bricklink color")
+
+	// ErrCatalogUnavailable is returned when the color/category catalog could not be loaded
+	ErrCatalogUnavailable = errors.New("bricklink catalog unavailable")
+)
+
+const (
+	// catalogLoadTimeout bounds a catalog load, which runs detached from the request that started it
+	catalogLoadTimeout = 30 * time.Second
+
+	// catalogRetryBackoff is how long after a failed load the catalog isn't fetched again
+	catalogRetryBackoff = time.Minute
+)
+
+// Catalog is an immutable snapshot of the BrickLink color and category lists
+// A nil *Catalog is valid and resolves every id to an empty name
+type Catalog struct {
+	colors     map[int]Color
+	categories map[int]string
+	loadedAt   time.Time
+}
+
+// ColorName returns the name of a BrickLink color, or "" if unknown
+func (c *Catalog) ColorName(colorID int) string {
+	if c == nil {
+		return ""
+	}
+	return c.colors[colorID].ColorName
+}
+
+// Color returns a BrickLink color and whether it exists
+func (c *Catalog) Color(colorID int) (Color, bool) {
+	if c == nil {
+		return Color{}, false
+	}
+	color, ok := c.colors[colorID]
+	return color, ok
+}
+
+// ValidateColorID returns ErrUnknownColor when colorID is not one of BrickLink's colors
+// Without a catalog nothing can be checked and ErrCatalogUnavailable is returned
+func (c *Catalog) ValidateColorID(colorID int) error {
+	if c == nil {
+		return ErrCatalogUnavailable
+	}
+	if _, ok := c.colors[colorID]; !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownColor, colorID)
+	}
+	return nil
+}
+
+// CategoryName returns the name of a BrickLink category, or "" if unknown
+func (c *Catalog) CategoryName(categoryID int) string {
+	if c == nil {
+		return ""
+	}
+	return c.categories[categoryID]
+}
+
+// GetColors fetches the full BrickLink color list
+func (s *BricklinkService) GetColors(ctx context.Context) ([]Color, error) {
+	var resp BricklinkResponse[[]Color]
+	if err := s.makeRequest(ctx, "GET", "/colors", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// GetCategories fetches the full BrickLink category list
+func (s *BricklinkService) GetCategories(ctx context.Context) ([]Category, error) {
+	var resp BricklinkResponse[[]Category]
+	if err := s.makeRequest(ctx, "GET", "/categories", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// Catalog returns the in-memory color/category catalog
+// The first call loads it from BrickLink, later calls are pure memory lookups
+// Once the refresh interval has passed the stale catalog is still served while a single reload runs in the
+// background. After a failed load nothing is fetched for catalogRetryBackoff, whatever catalog there is is served
+func (s *BricklinkService) Catalog(ctx context.Context) *Catalog {
+	s.catalogMu.RLock()
+	catalog := s.catalog
+	failedAt := s.catalogFailedAt
+	s.catalogMu.RUnlock()
+
+	now := s.clock.Now()
+	if !failedAt.IsZero() && now.Sub(failedAt) < catalogRetryBackoff {
+		return catalog
+	}
+
+	if catalog == nil {
+		loaded, err := s.loadCatalog(ctx)
+		if err != nil {
+			log.Warn("Failed to load BrickLink catalog", "error", err)
+			return nil
+		}
+		return loaded
+	}
+
+	if now.Sub(catalog.loadedAt) > s.credentials.CatalogRefreshInterval && s.catalogRefreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer s.catalogRefreshing.Store(false)
+
+			if _, err := s.loadCatalog(context.Background()); err != nil {
+				log.Warn("Failed to refresh BrickLink catalog", "error", err)
+			}
+		}()
+	}
+
+	return catalog
+}
+
+// loadCatalog fetches colors and categories and swaps in the new catalog, or records the failure
+// Concurrent callers share a single in-flight load. It runs detached with its own timeout, so a caller
+// whose ctx ends stops waiting without cancelling the load for the others
+func (s *BricklinkService) loadCatalog(ctx context.Context) (*Catalog, error) {
+	ch := s.catalogGroup.DoChan("catalog", func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.Background(), catalogLoadTimeout)
+		defer cancel()
+
+		catalog, err := s.fetchCatalog(loadCtx)
+
+		s.catalogMu.Lock()
+		defer s.catalogMu.Unlock()
+
+		if err != nil {
+			s.catalogFailedAt = s.clock.Now()
+			return nil, err
+		}
+		s.catalog = catalog
+		s.catalogFailedAt = time.Time{}
+		return catalog, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*Catalog), nil
+	}
+}
+
+// fetchCatalog fetches colors and categories from BrickLink in parallel
+func (s *BricklinkService) fetchCatalog(ctx context.Context) (*Catalog, error) {
+	var colors []Color
+	var categories []Category
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		var err error
+		colors, err = s.GetColors(gCtx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch colors: %w", err)
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		var err error
+		categories, err = s.GetCategories(gCtx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch categories: %w", err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{
+		colors:     make(map[int]Color, len(colors)),
+		categories: make(map[int]string, len(categories)),
+		loadedAt:   s.clock.Now(),
+	}
+	for _, color := range colors {
+		catalog.colors[color.ColorID] = color
+	}
+	for _, category := range categories {
+		catalog.categories[category.CategoryID] = category.CategoryName
+	}
+
+	log.Info("BrickLink catalog loaded", "colors", len(colors), "categories", len(categories))
+	return catalog, nil
+}
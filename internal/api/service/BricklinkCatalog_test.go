@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/clock"
+	"LegoManagerAPI/internal/config/bricklink"
+)
+
+// newTestService returns a BricklinkService pointed at the given test server
+func newTestService(serverURL string) *BricklinkService {
+	s := NewBricklinkService(bricklink.BricklinkConfig{
+		SignatureMethod:        "HMAC-SHA1",
+		ConsumerKey:            "key",
+		ConsumerSecret:         "secret",
+		AccessToken:            "token",
+		AccessTokenSecret:      "token_secret",
+		CatalogRefreshInterval: time.Hour,
+		EnabledItemTypes:       []string{"MINIFIG", "SET", "PART"},
+		MinifigInfoPath:        "/items/MINIFIG/%s",
+		MinifigSubsetsPath:     "/items/MINIFIG/%s/subsets",
+		MinifigPricePath:       "/items/MINIFIG/%s/price",
+		SetInfoPath:            "/items/SET/%s",
+		SetSubsetsPath:         "/items/SET/%s/subsets",
+		SetPricePath:           "/items/SET/%s/price",
+	})
+	s.baseURL = serverURL
+	return s
+}
+
+func TestCatalog_ConcurrentReadsLoadOnce(t *testing.T) {
+	var colorHits, categoryHits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/colors":
+			colorHits.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte(`{"meta":{"code":200},"data":[{"color_id":11,"color_name":"Black"}]}`))
+		case "/categories":
+			categoryHits.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte(`{"meta":{"code":200},"data":[{"category_id":65,"category_name":"Star Wars"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			catalog := s.Catalog(context.Background())
+			assert.Equal(t, "Black", catalog.ColorName(11))
+			assert.Equal(t, "Star Wars", catalog.CategoryName(65))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), colorHits.Load(), "colors should be loaded once")
+	assert.Equal(t, int32(1), categoryHits.Load(), "categories should be loaded once")
+}
+
+// catalogServer serves the color and category lists, failing while fail is set, and counts color requests
+func catalogServer(t *testing.T, colorHits *atomic.Int32, fail *atomic.Bool, delay time.Duration) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/colors" {
+			colorHits.Add(1)
+		}
+		time.Sleep(delay)
+		if fail.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/colors":
+			w.Write([]byte(`{"meta":{"code":200},"data":[{"color_id":11,"color_name":"Black"}]}`))
+		case "/categories":
+			w.Write([]byte(`{"meta":{"code":200},"data":[{"category_id":65,"category_name":"Star Wars"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCatalog_BacksOffAfterFailedLoad(t *testing.T) {
+	var colorHits atomic.Int32
+	var fail atomic.Bool
+	fail.Store(true)
+
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := newTestService(catalogServer(t, &colorHits, &fail, 0).URL)
+	s.clock = fake
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, s.Catalog(context.Background()))
+	}
+	assert.Equal(t, int32(1), colorHits.Load(), "a failed load is not retried right away")
+
+	fail.Store(false)
+	fake.Advance(catalogRetryBackoff)
+	assert.Equal(t, "Black", s.Catalog(context.Background()).ColorName(11))
+	assert.Equal(t, int32(2), colorHits.Load())
+}
+
+func TestCatalog_RefreshesOnceInBackground(t *testing.T) {
+	var colorHits atomic.Int32
+	var fail atomic.Bool
+
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := newTestService(catalogServer(t, &colorHits, &fail, 20*time.Millisecond).URL)
+	s.clock = fake
+
+	first := s.Catalog(context.Background())
+	require.NotNil(t, first)
+
+	fake.Advance(time.Hour + time.Second)
+	for i := 0; i < 20; i++ {
+		assert.Same(t, first, s.Catalog(context.Background()), "the stale catalog is served during the refresh")
+	}
+
+	require.Eventually(t, func() bool {
+		return s.Catalog(context.Background()) != first
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), colorHits.Load(), "stale reads share a single refresh")
+}
+
+func TestCatalog_LoadOutlivesCallerContext(t *testing.T) {
+	var colorHits atomic.Int32
+	var fail atomic.Bool
+
+	s := newTestService(catalogServer(t, &colorHits, &fail, 50*time.Millisecond).URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, s.Catalog(ctx), "the caller stops waiting when its context ends")
+
+	require.Eventually(t, func() bool {
+		s.catalogMu.RLock()
+		defer s.catalogMu.RUnlock()
+		return s.catalog != nil
+	}, time.Second, 5*time.Millisecond, "the load itself keeps going")
+	assert.Equal(t, int32(1), colorHits.Load())
+}
+
+func TestCatalog_NilResolvesEmpty(t *testing.T) {
+	var catalog *Catalog
+
+	require.NotPanics(t, func() {
+		assert.Equal(t, "", catalog.ColorName(1))
+		assert.Equal(t, "", catalog.CategoryName(1))
+	})
+}
+
+func TestCatalog_ValidateColorID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/colors":
+			w.Write([]byte(`{"meta":{"code":200},"data":[{"color_id":11,"color_name":"Black","color_code":"212121","color_type":"Solid"}]}`))
+		case "/categories":
+			w.Write([]byte(`{"meta":{"code":200},"data":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	catalog := newTestService(server.URL).Catalog(context.Background())
+	require.NotNil(t, catalog)
+
+	t.Run("known color", func(t *testing.T) {
+		assert.NoError(t, catalog.ValidateColorID(11))
+
+		color, ok := catalog.Color(11)
+		assert.True(t, ok)
+		assert.Equal(t, Color{ColorID: 11, ColorName: "Black", ColorCode: "212121", ColorType: "Solid"}, color)
+	})
+
+	t.Run("unknown color", func(t *testing.T) {
+		err := catalog.ValidateColorID(9999)
+		assert.ErrorIs(t, err, ErrUnknownColor)
+		assert.ErrorContains(t, err, "9999")
+
+		_, ok := catalog.Color(9999)
+		assert.False(t, ok)
+	})
+
+	t.Run("without a catalog", func(t *testing.T) {
+		var missing *Catalog
+		assert.ErrorIs(t, missing.ValidateColorID(11), ErrCatalogUnavailable)
+	})
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// credentialsCheckEndpoint is the cheap call RotateCredentials makes to test new credentials
+const credentialsCheckEndpoint = "/colors/1"
+
+// Credentials are the OAuth1 secrets BrickLink requests are signed with
+type Credentials struct {
+	ConsumerKey       string
+	ConsumerSecret    string
+	AccessToken       string
+	AccessTokenSecret string
+}
+
+// oauthCredentials returns the credentials requests are currently signed with
+// The secret fields of s.credentials must only be read through it and written through setCredentials
+func (s *BricklinkService) oauthCredentials() Credentials {
+	s.credentialsMu.RLock()
+	defer s.credentialsMu.RUnlock()
+
+	return Credentials{
+		ConsumerKey:       s.credentials.ConsumerKey,
+		ConsumerSecret:    s.credentials.ConsumerSecret,
+		AccessToken:       s.credentials.AccessToken,
+		AccessTokenSecret: s.credentials.AccessTokenSecret,
+	}
+}
+
+// setCredentials replaces the credentials requests are signed with, all four change together for concurrent readers
+func (s *BricklinkService) setCredentials(creds Credentials) {
+	s.credentialsMu.Lock()
+	defer s.credentialsMu.Unlock()
+
+	s.credentials.ConsumerKey = creds.ConsumerKey
+	s.credentials.ConsumerSecret = creds.ConsumerSecret
+	s.credentials.AccessToken = creds.AccessToken
+	s.credentials.AccessTokenSecret = creds.AccessTokenSecret
+}
+
+// RotateCredentials replaces the credentials of the running service, e.g. after the BrickLink API key was rotated
+// The new credentials are tested with a BrickLink call first, if it fails the old ones stay in use and the error is returned,
+// wrapping ErrBrickLinkAuth when BrickLink rejected them. Requests already in flight finish with the old credentials
+func (s *BricklinkService) RotateCredentials(ctx context.Context, creds Credentials) error {
+	var resp BricklinkResponse[Color]
+	if err := s.makeSignedRequest(ctx, creds, "GET", credentialsCheckEndpoint, nil, &resp); err != nil {
+		return fmt.Errorf("new credentials failed the test call: %w", err)
+	}
+
+	s.setCredentials(creds)
+
+	// The test call just succeeded, so an earlier rejection no longer applies
+	s.authFailed.Store(false)
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyServer answers a color to requests signed with one of the accepted consumer keys and 401 to all others
+func keyServer(t *testing.T, accepted ...string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, key := range accepted {
+			if strings.Contains(r.Header.Get("Authorization"), `oauth_consumer_key="`+key+`"`) {
+				w.Write([]byte(`{"meta":{"code":200},"data":{"color_id":1,"color_name":"White"}}`))
+				return
+			}
+		}
+		w.Write([]byte(`{"meta":{"code":401,"message":"BAD_OAUTH_REQUEST","description":"CONSUMER_KEY_UNKNOWN"},"data":{}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRotateCredentials_SwapsAfterTestCall(t *testing.T) {
+	// Only the new key is accepted, as once the old one was revoked
+	s := newTestService(keyServer(t, "rotated").URL)
+
+	_, err := s.GetMinifigInfo(context.Background(), "sw0001")
+	require.ErrorIs(t, err, ErrBrickLinkAuth)
+	require.ErrorIs(t, s.AuthStatus(), ErrBrickLinkAuth)
+
+	err = s.RotateCredentials(context.Background(), Credentials{
+		ConsumerKey:       "rotated",
+		ConsumerSecret:    "rotated_secret",
+		AccessToken:       "rotated_token",
+		AccessTokenSecret: "rotated_token_secret",
+	})
+	require.NoError(t, err)
+	assert.NoError(t, s.AuthStatus(), "a successful rotation clears the earlier rejection")
+
+	_, err = s.GetMinifigInfo(context.Background(), "sw0001")
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated_token_secret", s.oauthCredentials().AccessTokenSecret)
+}
+
+func TestRotateCredentials_KeepsOldCredentialsWhenRejected(t *testing.T) {
+	s := newTestService(keyServer(t, "key").URL)
+
+	err := s.RotateCredentials(context.Background(), Credentials{ConsumerKey: "typo", ConsumerSecret: "s", AccessToken: "t", AccessTokenSecret: "ts"})
+	require.ErrorIs(t, err, ErrBrickLinkAuth)
+	assert.NoError(t, s.AuthStatus(), "rejected new credentials say nothing about the ones in use")
+
+	assert.Equal(t, "key", s.oauthCredentials().ConsumerKey)
+	_, err = s.GetMinifigInfo(context.Background(), "sw0001")
+	assert.NoError(t, err)
+}
+
+func TestRotateCredentials_ConcurrentWithRequests(t *testing.T) {
+	s := newTestService(keyServer(t, "key", "rotated").URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.GetMinifigInfo(context.Background(), "sw0001")
+			assert.NoError(t, err)
+		}()
+	}
+
+	require.NoError(t, s.RotateCredentials(context.Background(), Credentials{
+		ConsumerKey: "rotated", ConsumerSecret: "s", AccessToken: "t", AccessTokenSecret: "ts",
+	}))
+	wg.Wait()
+}
+
+// Run with -race, signing must never observe a half-written swap
+// The initial test credentials follow the same pattern with an empty suffix
+func TestCredentials_ConcurrentReadsSeeConsistentSets(t *testing.T) {
+	s := newTestService("http://unused")
+	generation := func(i int) Credentials {
+		suffix := strconv.Itoa(i)
+		return Credentials{ConsumerKey: "key" + suffix, ConsumerSecret: "secret" + suffix, AccessToken: "token" + suffix, AccessTokenSecret: "token_secret" + suffix}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			s.setCredentials(generation(i))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				creds := s.oauthCredentials()
+				suffix := strings.TrimPrefix(creds.ConsumerKey, "key")
+				if creds.ConsumerSecret != "secret"+suffix || creds.AccessToken != "token"+suffix || creds.AccessTokenSecret != "token_secret"+suffix {
+					t.Errorf("read a mixed credential set: %+v", creds)
+					return
+				}
+
+				params := s.generateOAuthParams(creds)
+				_, err := s.signRequest("GET", "http://unused/colors", nil, params, creds)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, generation(999), s.oauthCredentials())
+}
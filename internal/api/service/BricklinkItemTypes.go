@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnsupportedItemType is returned for item types BrickLink doesn't have or that aren't enabled
+var ErrUnsupportedItemType = errors.New("unsupported bricklink item type")
+
+// ItemType is a BrickLink catalog item type as it appears in API paths, e.g. MINIFIG or PART
+type ItemType string
+
+// Item types with dedicated endpoint configuration, the others use the standard /items/{type} paths
+const (
+	ItemTypeMinifig ItemType = "MINIFIG"
+	ItemTypeSet     ItemType = "SET"
+)
+
+// SupportedItemTypes returns the item types enabled through BRICKLINK_ITEM_TYPES
+func (s *BricklinkService) SupportedItemTypes() []ItemType {
+	itemTypes := make([]ItemType, 0, len(s.credentials.EnabledItemTypes))
+	for _, itemType := range s.credentials.EnabledItemTypes {
+		itemTypes = append(itemTypes, ItemType(itemType))
+	}
+	return itemTypes
+}
+
+// ParseItemType validates a case-insensitive item type against the enabled types
+func (s *BricklinkService) ParseItemType(value string) (ItemType, error) {
+	itemType := ItemType(strings.ToUpper(value))
+	if !slices.Contains(s.SupportedItemTypes(), itemType) {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedItemType, value)
+	}
+	return itemType, nil
+}
+
+// GetItemComplete fetches all data for an item of any enabled type concurrently
+func (s *BricklinkService) GetItemComplete(ctx context.Context, itemType ItemType, itemID string, query PriceQuery) (*MinifigComplete, error) {
+	fetch, err := s.fetchersFor(itemType)
+	if err != nil {
+		return nil, err
+	}
+	return fetchItemComplete(ctx, fetch, itemID, query, s.credentials.SlowFetchThreshold)
+}
+
+// fetchersFor returns the uncached lookups for an enabled item type
+func (s *BricklinkService) fetchersFor(itemType ItemType) (itemFetchers, error) {
+	if !slices.Contains(s.SupportedItemTypes(), itemType) {
+		return itemFetchers{}, fmt.Errorf("%w: %q", ErrUnsupportedItemType, itemType)
+	}
+
+	switch itemType {
+	case ItemTypeMinifig:
+		return s.minifigFetchers(), nil
+	case ItemTypeSet:
+		return s.setFetchers(), nil
+	}
+
+	basePath := "/items/" + string(itemType) + "/%s"
+	return itemFetchers{
+		kind: strings.ToLower(string(itemType)),
+		info: func(ctx context.Context, itemID string) (*MinifigInfo, error) {
+			return s.getItemInfo(ctx, basePath, itemID)
+		},
+		subsets: func(ctx context.Context, itemID string) (MinifigSubsets, error) {
+			return s.getItemSubsets(ctx, basePath+"/subsets", itemID)
+		},
+		price: func(ctx context.Context, itemID, condition, currency string) (*MinifigPrice, error) {
+			return s.getItemPrice(ctx, basePath+"/price", itemID, condition, currency)
+		},
+	}, nil
+}
+
+// ItemCompleteResponse is the structured response for an item of any type
+type ItemCompleteResponse struct {
+	ItemType   ItemType          `json:"item_type"`
+	ItemID     string            `json:"item_id"`
+	BasicInfo  MinifigBasicInfo  `json:"basic_info"`
+	Components MinifigComponents `json:"components"`
+	Market     MinifigMarketData `json:"market"`
+	Images     MinifigImages     `json:"images"`
+	Metadata   ResponseMetadata  `json:"metadata"`
+}
+
+// ToItemResponse converts raw item data to the structured response for its type
+// The catalog resolves color and category names and may be nil
+func (mc *MinifigComplete) ToItemResponse(itemType ItemType, catalog *Catalog) *ItemCompleteResponse {
+	marketData := toAvailableMarketData(mc.Price, mc.UsedPrice, mc.PriceUnavailable)
+
+	return &ItemCompleteResponse{
+		ItemType:   itemType,
+		ItemID:     itemNo(mc.Info),
+		BasicInfo:  toBasicInfo(mc.Info, catalog),
+		Components: toComponents(mc.Subsets, catalog),
+		Market:     marketData,
+		Images:     toImages(mc.Info),
+		Metadata:   toMetadata(mc, mc.Info != nil && marketData.Available),
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetItemComplete_UsesTypedEndpoints(t *testing.T) {
+	var paths sync.Map
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths.Store(r.URL.Path, true)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/price"):
+			w.Write([]byte(`{"meta":{"code":200},"data":{"currency_code":"USD","new_or_used":"N"}}`))
+		case strings.HasSuffix(r.URL.Path, "/subsets"):
+			w.Write([]byte(`{"meta":{"code":200},"data":[]}`))
+		default:
+			w.Write([]byte(`{"meta":{"code":200},"data":{"no":"3001","name":"Brick 2 x 4","type":"PART"}}`))
+		}
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+
+	result, err := s.GetItemComplete(context.Background(), "PART", "3001", PriceQuery{})
+	require.NoError(t, err)
+
+	for _, path := range []string{"/items/PART/3001", "/items/PART/3001/subsets", "/items/PART/3001/price"} {
+		_, ok := paths.Load(path)
+		assert.True(t, ok, "expected a request to %s", path)
+	}
+
+	resp := result.ToItemResponse("PART", nil)
+	assert.Equal(t, "3001", resp.ItemID)
+	assert.Equal(t, "Brick 2 x 4", resp.BasicInfo.Name)
+}
+
+func TestGetItemComplete_RejectsDisabledType(t *testing.T) {
+	s := newTestService("http://unused.invalid")
+
+	_, err := s.GetItemComplete(context.Background(), "GEAR", "852", PriceQuery{})
+	assert.ErrorIs(t, err, ErrUnsupportedItemType)
+
+	_, err = s.ParseItemType("gear")
+	assert.ErrorIs(t, err, ErrUnsupportedItemType)
+
+	itemType, err := s.ParseItemType("part")
+	require.NoError(t, err)
+	assert.Equal(t, ItemType("PART"), itemType)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAmount is returned when a price string can't be parsed as a decimal amount
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// currencyDecimals lists currencies whose minor unit isn't cents, every other currency uses 2 decimals
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// CurrencyDecimals returns how many decimals amounts in currency are rounded to
+func CurrencyDecimals(currency string) int {
+	if decimals, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// Money is an exact amount counted in a currency's minor units, e.g. cents
+// BrickLink sends prices as decimal strings with up to 4 decimals, parsing them straight into minor
+// units keeps sums exact where float64 would drift. It is encoded as a JSON number with the currency's decimals
+type Money struct {
+	minor    int64
+	decimals int
+}
+
+// MinorUnits returns the amount in minor units, e.g. 1230 for 12.30 USD
+func (m Money) MinorUnits() int64 {
+	return m.minor
+}
+
+// ParseMoney parses a decimal string such as "12.3456", rounding half away from zero to the currency's decimals
+// An empty string is zero
+func ParseMoney(value, currency string) (Money, error) {
+	decimals := CurrencyDecimals(currency)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Money{decimals: decimals}, nil
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	// Keep one extra digit to decide the rounding
+	fraction += strings.Repeat("0", decimals+1)
+	minor, err := strconv.ParseInt(whole+fraction[:decimals], 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if fraction[decimals] >= '5' {
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{minor: minor, decimals: decimals}, nil
+}
+
+// isDigits reports whether s only holds ASCII digits, "" counts as digits
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SumMoney adds amounts exactly, they are expected to share a currency
+func SumMoney(amounts ...Money) Money {
+	var total Money
+	for i, amount := range amounts {
+		if i == 0 {
+			total.decimals = amount.decimals
+		}
+		total.minor += amount.minor
+	}
+	return total
+}
+
+// Times multiplies the amount by a whole quantity, e.g. a unit price by the number of copies
+func (m Money) Times(quantity int) Money {
+	return Money{minor: m.minor * int64(quantity), decimals: m.decimals}
+}
+
+// Minus subtracts other exactly, both are expected to share a currency
+func (m Money) Minus(other Money) Money {
+	return Money{minor: m.minor - other.minor, decimals: m.decimals}
+}
+
+// Float64 returns the amount as a float, for display or comparisons only, never for further arithmetic
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.String(), 64)
+	return f
+}
+
+// String formats the amount with exactly the currency's decimals, e.g. 12.30
+func (m Money) String() string {
+	minor := m.minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	digits := strconv.FormatInt(minor, 10)
+	if m.decimals <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= m.decimals {
+		digits = strings.Repeat("0", m.decimals-len(digits)+1) + digits
+	}
+
+	split := len(digits) - m.decimals
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// MarshalJSON writes the amount as a JSON number, so responses keep their numeric price fields
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     string
+	}{
+		{"12.3456", "USD", "12.35"},
+		{"12.3449", "USD", "12.34"},
+		{"12.345", "EUR", "12.35"},
+		{"0.005", "USD", "0.01"},
+		{"7", "USD", "7.00"},
+		{".5", "USD", "0.50"},
+		{"-1.005", "USD", "-1.01"},
+		{"", "USD", "0.00"},
+		{"1234.5", "JPY", "1235"},
+		{"99.99", "jpy", "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.value, func(t *testing.T) {
+			m, err := ParseMoney(tt.value, tt.currency)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m.String())
+		})
+	}
+}
+
+func TestParseMoney_Invalid(t *testing.T) {
+	for _, value := range []string{"abc", "1.2.3", "1,50", "1e5", "--1"} {
+		_, err := ParseMoney(value, "USD")
+		assert.ErrorIs(t, err, ErrInvalidAmount, value)
+	}
+}
+
+func TestSumMoney_NoDriftOver100Items(t *testing.T) {
+	amounts := make([]Money, 100)
+	var floatTotal float64
+	for i := range amounts {
+		m, err := ParseMoney("0.10", "USD")
+		require.NoError(t, err)
+		amounts[i] = m
+		floatTotal += 0.10
+	}
+
+	total := SumMoney(amounts...)
+	assert.Equal(t, int64(1000), total.MinorUnits())
+	assert.Equal(t, "10.00", total.String())
+	assert.NotEqual(t, 10.0, floatTotal, "float64 accumulation drifts, which is why amounts are summed in minor units")
+}
+
+func TestMoney_MarshalJSON(t *testing.T) {
+	summary := PriceSummary{}
+	summary.Average, _ = ParseMoney("12.3", "USD")
+	summary.Minimum, _ = ParseMoney("0.0449", "USD")
+
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"minimum":0.04,"maximum":0.00,"average":12.30,"weighted_average":0.00}`, string(data))
+}
+
+func TestMoney_TimesAndMinus(t *testing.T) {
+	unit, _ := ParseMoney("4.99", "USD")
+	cost, _ := ParseMoney("20", "USD")
+
+	value := unit.Times(3)
+	assert.Equal(t, "14.97", value.String())
+	assert.Equal(t, "-5.03", value.Minus(cost).String())
+}
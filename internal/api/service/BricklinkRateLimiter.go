@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+
+	"LegoManagerAPI/internal/cache"
+	"LegoManagerAPI/internal/clock"
+)
+
+// ErrRateLimited is returned instead of calling BrickLink when our own request budget is used up
+var ErrRateLimited = errors.New("bricklink rate limit reached")
+
+// RateLimitError reports how long to wait before BrickLink may be called again, it matches ErrRateLimited
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrRateLimited, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// requestLimiter decides whether another BrickLink call fits in the request budget
+type requestLimiter interface {
+	allow(ctx context.Context) error
+
+	// remainingToday estimates how many requests the daily cap still allows, -1 when there is no cap
+	remainingToday(ctx context.Context) int
+}
+
+// rateLimiter throttles outbound calls to a per-second rate and a per-day ceiling
+// Limits of zero or less are disabled, it is safe for concurrent use
+type rateLimiter struct {
+	perSecond *rate.Limiter
+
+	mu       sync.Mutex
+	daily    int
+	day      time.Time // Start of the UTC day dayCount belongs to
+	dayCount int
+
+	clock clock.Clock
+}
+
+func newRateLimiter(perSecond, daily int) *rateLimiter {
+	l := &rateLimiter{
+		daily: daily,
+		clock: clock.Real(),
+	}
+	if perSecond > 0 {
+		l.perSecond = rate.NewLimiter(rate.Limit(perSecond), perSecond)
+	}
+	return l
+}
+
+// allow takes budget for one request, returning a *RateLimitError when none is left
+func (l *rateLimiter) allow(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+
+	// BrickLink's daily cap resets at midnight UTC
+	if l.daily > 0 {
+		day := now.UTC().Truncate(24 * time.Hour)
+		if !day.Equal(l.day) {
+			l.day = day
+			l.dayCount = 0
+		}
+		if l.dayCount >= l.daily {
+			return &RateLimitError{RetryAfter: day.Add(24 * time.Hour).Sub(now)}
+		}
+	}
+
+	if l.perSecond != nil {
+		reservation := l.perSecond.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return &RateLimitError{RetryAfter: delay}
+		}
+	}
+
+	l.dayCount++
+	return nil
+}
+
+func (l *rateLimiter) remainingToday(ctx context.Context) int {
+	if l.daily <= 0 {
+		return -1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.clock.Now().UTC().Truncate(24 * time.Hour).Equal(l.day) {
+		return l.daily
+	}
+	return max(l.daily-l.dayCount, 0)
+}
+
+// redisRateLimitScript counts a request against the per-second and daily windows, only when both have room
+// Returns 0 when allowed, 1 when the per-second limit and 2 when the daily limit is reached
+var redisRateLimitScript = redis.NewScript(`
+local second = tonumber(redis.call('GET', KEYS[1]) or '0')
+local day = tonumber(redis.call('GET', KEYS[2]) or '0')
+if tonumber(ARGV[2]) > 0 and day >= tonumber(ARGV[2]) then
+	return 2
+end
+if tonumber(ARGV[1]) > 0 and second >= tonumber(ARGV[1]) then
+	return 1
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('INCR', KEYS[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+return 0
+`)
+
+// redisRateLimiter shares the request budget between all instances through fixed Redis windows
+// When Redis can't be reached the instance falls back to its own in-memory limiter
+type redisRateLimiter struct {
+	redis     *cache.RedisClient
+	perSecond int
+	daily     int
+	fallback  *rateLimiter
+
+	// storeDown is set while Redis can't be reached, so losing and regaining it is logged once each
+	storeDown atomic.Bool
+
+	clock clock.Clock
+}
+
+func newRedisRateLimiter(redisClient *cache.RedisClient, perSecond, daily int) *redisRateLimiter {
+	return &redisRateLimiter{
+		redis:     redisClient,
+		perSecond: perSecond,
+		daily:     daily,
+		fallback:  newRateLimiter(perSecond, daily),
+		clock:     clock.Real(),
+	}
+}
+
+func (l *redisRateLimiter) allow(ctx context.Context) error {
+	now := l.clock.Now().UTC()
+	second := now.Truncate(time.Second)
+	day := now.Truncate(24 * time.Hour)
+
+	keys := []string{
+		fmt.Sprintf("bricklink:ratelimit:second:%d", second.Unix()),
+		fmt.Sprintf("bricklink:ratelimit:day:%s", day.Format(time.DateOnly)),
+	}
+	secondTTL := second.Add(time.Second).Sub(now) + time.Second
+	dayTTL := day.Add(24*time.Hour).Sub(now) + time.Minute
+
+	result, err := redisRateLimitScript.Run(ctx, l.redis.Client(), keys,
+		l.perSecond, l.daily, secondTTL.Milliseconds(), dayTTL.Milliseconds()).Int()
+	if err != nil {
+		// A cancelled or expired caller says nothing about the store
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if l.storeDown.CompareAndSwap(false, true) {
+			log.Warn("BrickLink rate limit store unavailable, using local limits", "error", err)
+		}
+		return l.fallback.allow(ctx)
+	}
+	if l.storeDown.CompareAndSwap(true, false) {
+		log.Info("BrickLink rate limit store available again, sharing limits")
+	}
+
+	switch result {
+	case 1:
+		return &RateLimitError{RetryAfter: second.Add(time.Second).Sub(now)}
+	case 2:
+		return &RateLimitError{RetryAfter: day.Add(24 * time.Hour).Sub(now)}
+	}
+	return nil
+}
+
+func (l *redisRateLimiter) remainingToday(ctx context.Context) int {
+	if l.daily <= 0 {
+		return -1
+	}
+
+	day := l.clock.Now().UTC().Truncate(24 * time.Hour)
+	used, err := l.redis.Client().Get(ctx, fmt.Sprintf("bricklink:ratelimit:day:%s", day.Format(time.DateOnly))).Int()
+	if errors.Is(err, redis.Nil) {
+		return l.daily
+	}
+	if err != nil {
+		return l.fallback.remainingToday(ctx)
+	}
+	return max(l.daily-used, 0)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/cache"
+	"LegoManagerAPI/internal/clock"
+	cacheConfig "LegoManagerAPI/internal/config/cache"
+)
+
+func TestRateLimiter_PerSecond(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	l := newRateLimiter(2, 0)
+	l.clock = now
+
+	require.NoError(t, l.allow(context.Background()))
+	require.NoError(t, l.allow(context.Background()))
+
+	err := l.allow(context.Background())
+	var rateErr *RateLimitError
+	require.ErrorAs(t, err, &rateErr)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 500*time.Millisecond, rateErr.RetryAfter)
+
+	now.Advance(500 * time.Millisecond)
+	assert.NoError(t, l.allow(context.Background()), "a token should be refilled after the wait")
+}
+
+func TestRateLimiter_DailyResetsAtMidnightUTC(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC))
+	l := newRateLimiter(0, 2)
+	l.clock = now
+
+	require.NoError(t, l.allow(context.Background()))
+	require.NoError(t, l.allow(context.Background()))
+
+	var rateErr *RateLimitError
+	require.ErrorAs(t, l.allow(context.Background()), &rateErr)
+	assert.Equal(t, time.Hour, rateErr.RetryAfter)
+
+	now.Advance(time.Hour)
+	assert.NoError(t, l.allow(context.Background()))
+}
+
+func TestRateLimiter_RemainingToday(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC))
+	l := newRateLimiter(0, 3)
+	l.clock = now
+	ctx := context.Background()
+
+	assert.Equal(t, 3, l.remainingToday(ctx))
+	require.NoError(t, l.allow(ctx))
+	assert.Equal(t, 2, l.remainingToday(ctx))
+
+	now.Advance(time.Hour)
+	assert.Equal(t, 3, l.remainingToday(ctx), "the budget resets at midnight UTC")
+
+	assert.Equal(t, -1, newRateLimiter(1, 0).remainingToday(ctx), "no daily cap")
+}
+
+func TestMakeRequest_RateLimitedSkipsHTTPCall(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001","name":"Battle Droid"}}`))
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+	s.limiter = newRateLimiter(0, 3)
+
+	var wg sync.WaitGroup
+	var limited atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.GetMinifigInfo(context.Background(), "sw0001"); err != nil {
+				assert.ErrorIs(t, err, ErrRateLimited)
+				limited.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), hits.Load())
+	assert.Equal(t, int32(7), limited.Load())
+}
+
+func TestRedisRateLimiter_SharesDailyBudgetAcrossInstances(t *testing.T) {
+	redisClient := newTestRedis(t)
+	now := clock.NewFake(time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC))
+
+	instances := []*redisRateLimiter{
+		newRedisRateLimiter(redisClient, 0, 3),
+		newRedisRateLimiter(redisClient, 0, 3),
+	}
+	for _, l := range instances {
+		l.clock = now
+	}
+
+	require.NoError(t, instances[0].allow(context.Background()))
+	require.NoError(t, instances[1].allow(context.Background()))
+	require.NoError(t, instances[0].allow(context.Background()))
+
+	var rateErr *RateLimitError
+	require.ErrorAs(t, instances[1].allow(context.Background()), &rateErr)
+	assert.Equal(t, time.Hour, rateErr.RetryAfter)
+}
+
+func TestRedisRateLimiter_RemainingToday(t *testing.T) {
+	redisClient := newTestRedis(t)
+	now := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	a := newRedisRateLimiter(redisClient, 0, 5)
+	b := newRedisRateLimiter(redisClient, 0, 5)
+	a.clock, b.clock = now, now
+
+	assert.Equal(t, 5, b.remainingToday(ctx))
+	require.NoError(t, a.allow(ctx))
+	require.NoError(t, a.allow(ctx))
+	assert.Equal(t, 3, b.remainingToday(ctx), "the shared budget is reported by every instance")
+}
+
+func TestRedisRateLimiter_PerSecond(t *testing.T) {
+	now := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, int(250*time.Millisecond), time.UTC))
+	l := newRedisRateLimiter(newTestRedis(t), 2, 0)
+	l.clock = now
+
+	require.NoError(t, l.allow(context.Background()))
+	require.NoError(t, l.allow(context.Background()))
+
+	var rateErr *RateLimitError
+	require.ErrorAs(t, l.allow(context.Background()), &rateErr)
+	assert.Equal(t, 750*time.Millisecond, rateErr.RetryAfter)
+
+	now.Advance(750 * time.Millisecond)
+	assert.NoError(t, l.allow(context.Background()), "the next second starts a new window")
+}
+
+func TestRedisRateLimiter_FallsBackToMemory(t *testing.T) {
+	redisClient := newTestRedis(t)
+	l := newRedisRateLimiter(redisClient, 0, 1)
+	require.NoError(t, redisClient.Close())
+
+	require.NoError(t, l.allow(context.Background()))
+	assert.ErrorIs(t, l.allow(context.Background()), ErrRateLimited, "the local limits should still apply")
+}
+
+func TestRedisRateLimiter_CancelledContextIsNotAStoreError(t *testing.T) {
+	redisClient := newTestRedis(t)
+	l := newRedisRateLimiter(redisClient, 0, 1)
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, l.allow(ctx), context.Canceled)
+	assert.False(t, l.storeDown.Load())
+	assert.NotContains(t, logs.String(), "rate limit store unavailable")
+
+	require.NoError(t, redisClient.Close())
+	assert.NoError(t, l.allow(context.Background()), "the cancelled call shouldn't spend the local quota")
+}
+
+func TestRedisRateLimiter_LogsStoreStateChanges(t *testing.T) {
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	redisClient, err := cache.NewRedisClient(cacheConfig.CacheConfig{Host: mr.Host(), Port: port})
+	require.NoError(t, err)
+	t.Cleanup(func() { redisClient.Close() })
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	l := newRedisRateLimiter(redisClient, 0, 0)
+
+	mr.SetError("LOADING Redis is loading the dataset in memory")
+	for i := 0; i < 5; i++ {
+		require.NoError(t, l.allow(context.Background()))
+	}
+	assert.Equal(t, 1, strings.Count(logs.String(), "rate limit store unavailable"), "losing the store is logged once")
+
+	mr.SetError("")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.allow(context.Background()))
+	}
+	assert.Equal(t, 1, strings.Count(logs.String(), "rate limit store available again"), "regaining it is logged once")
+}
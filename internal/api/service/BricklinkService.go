@@ -7,6 +7,7 @@ import (
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,9 +20,32 @@ import (
 	"github.com/charmbracelet/log"
 	"golang.org/x/sync/errgroup"
 
+	"LegoManagerAPI/internal/cache"
+	"LegoManagerAPI/internal/clock"
 	"LegoManagerAPI/internal/config/bricklink"
 )
 
+var (
+	// ErrNotFound is returned when BrickLink reports that the requested item does not exist
+	ErrNotFound = errors.New("bricklink item not found")
+
+	// ErrBrickLinkAuth is returned when BrickLink rejects our OAuth credentials (expired, revoked or invalid signature)
+	ErrBrickLinkAuth = errors.New("bricklink credentials rejected")
+
+	// ErrEndpointDisabled is returned when a lookup was turned off through BRICKLINK_DISABLED_ENDPOINTS
+	ErrEndpointDisabled = errors.New("bricklink endpoint temporarily disabled")
+)
+
+// maxNotFoundEntries bounds the negative cache of missing minifig ids, a flood of made up ids can't grow it further
+const maxNotFoundEntries = 10000
+
+// Endpoint names accepted in BRICKLINK_DISABLED_ENDPOINTS
+const (
+	EndpointInfo    = "info"
+	EndpointSubsets = "subsets"
+	EndpointPrice   = "price"
+)
+
 func NewBricklinkService(cfg bricklink.BricklinkConfig) *BricklinkService {
 	return &BricklinkService{
 		credentials: cfg,
@@ -29,26 +53,132 @@ func NewBricklinkService(cfg bricklink.BricklinkConfig) *BricklinkService {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		notFound:      make(map[string]time.Time),
+		notFoundLimit: maxNotFoundEntries,
+		limiter:       newRateLimiter(cfg.RateLimitPerSecond, cfg.RateLimitPerDay),
+		clock:         clock.Real(),
+	}
+}
+
+// ShareRateLimit moves the request budget into Redis so every instance draws from the same quota
+// A nil client keeps the in-memory limiter
+func (s *BricklinkService) ShareRateLimit(redisClient *cache.RedisClient) {
+	if redisClient == nil {
+		return
+	}
+	s.limiter = newRedisRateLimiter(redisClient, s.credentials.RateLimitPerSecond, s.credentials.RateLimitPerDay)
+}
+
+// AuthStatus reports whether the most recent BrickLink call was rejected for bad credentials
+// Returns ErrBrickLinkAuth until a later call succeeds
+func (s *BricklinkService) AuthStatus() error {
+	if s.authFailed.Load() {
+		return ErrBrickLinkAuth
+	}
+	return nil
+}
+
+// RemainingDailyQuota estimates how many BrickLink calls are left before today's cap, -1 when there is no cap
+// With a shared limiter this is the budget left for all instances together
+func (s *BricklinkService) RemainingDailyQuota(ctx context.Context) int {
+	return s.limiter.remainingToday(ctx)
+}
+
+// EndpointDisabled reports whether the named lookup is turned off to conserve API quota
+func (s *BricklinkService) EndpointDisabled(name string) bool {
+	for _, disabled := range s.credentials.DisabledEndpoints {
+		if strings.EqualFold(disabled, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// itemFetchers are the individual lookups GetMinifigComplete, GetSetComplete and GetItemComplete fan out to
+// Both the raw service and CachedBricklinkClient supply their own implementations
+type itemFetchers struct {
+	kind    string // lowercase item type, e.g. "minifig", used in cache keys, errors and logs
+	info    func(ctx context.Context, itemID string) (*MinifigInfo, error)
+	subsets func(ctx context.Context, itemID string) (MinifigSubsets, error)
+	price   func(ctx context.Context, itemID, condition, currency string) (*MinifigPrice, error)
+}
+
+// PriceQuery selects the price guide a complete lookup fetches
+// Empty fields default to new prices in USD
+type PriceQuery struct {
+	Currency  string // ISO 4217 code, e.g. EUR
+	Condition string // "N" for new or "U" for used, ignored when BothConditions is set
+
+	// BothConditions fetches new and used prices together
+	BothConditions bool
+}
+
+// withDefaults fills in USD and new condition for empty fields
+func (q PriceQuery) withDefaults() PriceQuery {
+	if q.Currency == "" {
+		q.Currency = "USD"
+	}
+	if q.Condition == "" {
+		q.Condition = "N"
 	}
+	return q
 }
 
 // GetMinifigComplete fetches all minifig data concurrenlty
-func (s *BricklinkService) GetMinifigComplete(ctx context.Context, minifigID string) (*MinifigComplete, error) {
-	startTime := time.Now()
+func (s *BricklinkService) GetMinifigComplete(ctx context.Context, minifigID string, query PriceQuery) (*MinifigComplete, error) {
+	return fetchItemComplete(ctx, s.minifigFetchers(), minifigID, query, s.credentials.SlowFetchThreshold)
+}
+
+// GetSetComplete fetches all set data concurrently
+func (s *BricklinkService) GetSetComplete(ctx context.Context, setID string, query PriceQuery) (*SetComplete, error) {
+	result, err := fetchItemComplete(ctx, s.setFetchers(), setID, query, s.credentials.SlowFetchThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return (*SetComplete)(result), nil
+}
 
-	result := &MinifigComplete{
-		IndividualFetchTimeMs: make(map[string]int64),
+// minifigFetchers returns the uncached minifig lookups backed directly by the BrickLink API
+func (s *BricklinkService) minifigFetchers() itemFetchers {
+	return itemFetchers{
+		kind:    "minifig",
+		info:    s.GetMinifigInfo,
+		subsets: s.GetMinifigSubsets,
+		price:   s.getMinifigPrice,
 	}
+}
+
+// setFetchers returns the uncached set lookups backed directly by the BrickLink API
+func (s *BricklinkService) setFetchers() itemFetchers {
+	return itemFetchers{
+		kind:    "set",
+		info:    s.GetSetInfo,
+		subsets: s.GetSetSubsets,
+		price:   s.getSetPrice,
+	}
+}
+
+// fetchItemComplete runs the info, subsets and price lookups concurrently and combines the results
+// A disabled price lookup does not fail the call, the result is marked PriceUnavailable instead
+// The completion is logged as a warning when the fetch or any endpoint took longer than slowAfter
+func fetchItemComplete(ctx context.Context, fetch itemFetchers, itemID string, query PriceQuery, slowAfter time.Duration) (*MinifigComplete, error) {
+	startTime := time.Now()
+	query = query.withDefaults()
+
+	result := &MinifigComplete{}
+
+	// Each lookup records its own timing, the map is only built once every goroutine has returned
+	var infoTime, subsetsTime, priceTime time.Duration
 
 	g, gCtx := errgroup.WithContext(ctx)
 
 	// Fetch info
 	g.Go(func() error {
 		startInfo := time.Now()
-		info, err := s.GetMinifigInfo(gCtx, minifigID)
-		result.IndividualFetchTimeMs["info"] = time.Since(startInfo).Milliseconds()
+		info, err := fetch.info(gCtx, itemID)
+		infoTime = time.Since(startInfo)
 		if err != nil {
-			return fmt.Errorf("failed to fetch minifig info: %w", err)
+			return fmt.Errorf("failed to fetch %s info: %w", fetch.kind, err)
 		}
 		result.Info = info
 		return nil
@@ -57,10 +187,10 @@ func (s *BricklinkService) GetMinifigComplete(ctx context.Context, minifigID str
 	// Fetch subsets
 	g.Go(func() error {
 		startSubsets := time.Now()
-		subsets, err := s.GetMinifigSubsets(gCtx, minifigID)
-		result.IndividualFetchTimeMs["subsets"] = time.Since(startSubsets).Milliseconds()
+		subsets, err := fetch.subsets(gCtx, itemID)
+		subsetsTime = time.Since(startSubsets)
 		if err != nil {
-			return fmt.Errorf("failed to fetch minifig subsets: %w", err)
+			return fmt.Errorf("failed to fetch %s subsets: %w", fetch.kind, err)
 		}
 		result.Subsets = subsets
 		return nil
@@ -69,34 +199,115 @@ func (s *BricklinkService) GetMinifigComplete(ctx context.Context, minifigID str
 	// Fetch price
 	g.Go(func() error {
 		startPrice := time.Now()
-		price, err := s.GetMinifigPrice(gCtx, minifigID)
-		result.IndividualFetchTimeMs["price"] = time.Since(startPrice).Milliseconds()
+		if query.BothConditions {
+			newPrice, usedPrice, err := fetchPriceBoth(gCtx, fetch.price, itemID, query.Currency)
+			priceTime = time.Since(startPrice)
+			if errors.Is(err, ErrEndpointDisabled) {
+				result.PriceUnavailable = true
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			result.Price = newPrice
+			result.UsedPrice = usedPrice
+			return nil
+		}
+
+		price, err := fetch.price(gCtx, itemID, query.Condition, query.Currency)
+		priceTime = time.Since(startPrice)
+		if errors.Is(err, ErrEndpointDisabled) {
+			result.PriceUnavailable = true
+			return nil
+		}
 		if err != nil {
-			return fmt.Errorf("failed to fetch minifig price: %w", err)
+			return fmt.Errorf("failed to fetch %s price: %w", fetch.kind, err)
 		}
 		result.Price = price
 		return nil
 	})
 
-	if err := g.Wait(); err != nil {
-		return nil, err
+	err := g.Wait()
+
+	totalTime := time.Since(startTime)
+	result.FetchTimeMs = totalTime.Milliseconds()
+	result.IndividualFetchTimeMs = map[string]int64{
+		"info":    infoTime.Milliseconds(),
+		"subsets": subsetsTime.Milliseconds(),
+		"price":   priceTime.Milliseconds(),
 	}
 
-	result.FetchTimeMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		log.Debug("BrickLink item fetch failed",
+			"kind", fetch.kind,
+			"item_id", itemID,
+			"total_time_ms", result.FetchTimeMs,
+			"info_time_ms", result.IndividualFetchTimeMs["info"],
+			"subsets_time_ms", result.IndividualFetchTimeMs["subsets"],
+			"price_time_ms", result.IndividualFetchTimeMs["price"],
+			"error", err)
+		return nil, err
+	}
 
-	log.Info("Minifig data fetched",
-		"minifig_id", minifigID,
+	fields := []any{
+		"kind", fetch.kind,
+		"item_id", itemID,
 		"total_time_ms", result.FetchTimeMs,
 		"info_time_ms", result.IndividualFetchTimeMs["info"],
 		"subsets_time_ms", result.IndividualFetchTimeMs["subsets"],
-		"price_time_ms", result.IndividualFetchTimeMs["price"])
+		"price_time_ms", result.IndividualFetchTimeMs["price"],
+	}
+
+	slow := slowEndpoints(slowAfter, map[string]time.Duration{
+		"info":    infoTime,
+		"subsets": subsetsTime,
+		"price":   priceTime,
+	})
+	if len(slow) > 0 || (slowAfter > 0 && totalTime > slowAfter) {
+		log.Warn("Slow BrickLink item fetch", append(fields,
+			"threshold_ms", slowAfter.Milliseconds(),
+			"slow_endpoints", strings.Join(slow, ","))...)
+		return result, nil
+	}
+
+	log.Info("BrickLink item fetched", fields...)
 
 	return result, nil
 }
 
+// slowEndpoints returns the sorted names of the endpoints that took longer than threshold, none when it is 0
+func slowEndpoints(threshold time.Duration, timings map[string]time.Duration) []string {
+	if threshold <= 0 {
+		return nil
+	}
+
+	var slow []string
+	for endpoint, took := range timings {
+		if took > threshold {
+			slow = append(slow, endpoint)
+		}
+	}
+	sort.Strings(slow)
+	return slow
+}
+
 // GetMinifigInfo fetches minifig basic info
 func (s *BricklinkService) GetMinifigInfo(ctx context.Context, minifigID string) (*MinifigInfo, error) {
-	endpoint := fmt.Sprintf("/items/MINIFIG/%s", minifigID)
+	return s.getItemInfo(ctx, s.credentials.MinifigInfoPath, minifigID)
+}
+
+// GetSetInfo fetches set basic info
+func (s *BricklinkService) GetSetInfo(ctx context.Context, setID string) (*SetInfo, error) {
+	return s.getItemInfo(ctx, s.credentials.SetInfoPath, setID)
+}
+
+// getItemInfo fetches catalog info for the item at the given path template
+func (s *BricklinkService) getItemInfo(ctx context.Context, pathTemplate, itemID string) (*MinifigInfo, error) {
+	if s.EndpointDisabled(EndpointInfo) {
+		return nil, ErrEndpointDisabled
+	}
+
+	endpoint := fmt.Sprintf(pathTemplate, itemID)
 
 	var resp BricklinkResponse[MinifigInfo]
 	if err := s.makeRequest(ctx, "GET", endpoint, nil, &resp); err != nil {
@@ -106,9 +317,76 @@ func (s *BricklinkService) GetMinifigInfo(ctx context.Context, minifigID string)
 	return &resp.Data, nil
 }
 
+// MinifigExists checks a minifig id with a single info call, skipping the slower subsets/price calls
+// Ids BrickLink reported as missing are remembered briefly so repeated checks don't hit the API
+func (s *BricklinkService) MinifigExists(ctx context.Context, minifigID string) (*MinifigInfo, error) {
+	s.notFoundMu.Lock()
+	expiresAt, cached := s.notFound[minifigID]
+	if cached && s.clock.Now().After(expiresAt) {
+		delete(s.notFound, minifigID)
+		cached = false
+	}
+	s.notFoundMu.Unlock()
+
+	if cached {
+		return nil, fmt.Errorf("%w: minifig %s", ErrNotFound, minifigID)
+	}
+
+	info, err := s.GetMinifigInfo(ctx, minifigID)
+	if errors.Is(err, ErrNotFound) {
+		s.rememberNotFound(minifigID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// rememberNotFound adds a missing minifig id to the negative cache
+// A full cache first drops its expired entries and, if that frees nothing, the entry expiring soonest
+func (s *BricklinkService) rememberNotFound(minifigID string) {
+	s.notFoundMu.Lock()
+	defer s.notFoundMu.Unlock()
+
+	now := s.clock.Now()
+	if _, ok := s.notFound[minifigID]; !ok && len(s.notFound) >= s.notFoundLimit {
+		var oldestID string
+		var oldest time.Time
+		for id, expiresAt := range s.notFound {
+			if now.After(expiresAt) {
+				delete(s.notFound, id)
+				continue
+			}
+			if oldestID == "" || expiresAt.Before(oldest) {
+				oldestID, oldest = id, expiresAt
+			}
+		}
+		if len(s.notFound) >= s.notFoundLimit {
+			delete(s.notFound, oldestID)
+		}
+	}
+
+	s.notFound[minifigID] = now.Add(s.credentials.NotFoundCacheTTL)
+}
+
 // GetMinifigSubsets fetches minifig subsets
 func (s *BricklinkService) GetMinifigSubsets(ctx context.Context, minifigID string) (MinifigSubsets, error) {
-	endpoint := fmt.Sprintf("/items/MINIFIG/%s/subsets", minifigID)
+	return s.getItemSubsets(ctx, s.credentials.MinifigSubsetsPath, minifigID)
+}
+
+// GetSetSubsets fetches set subsets, which mix parts, minifigs and other items
+func (s *BricklinkService) GetSetSubsets(ctx context.Context, setID string) (SetSubsets, error) {
+	return s.getItemSubsets(ctx, s.credentials.SetSubsetsPath, setID)
+}
+
+// getItemSubsets fetches the subsets of the item at the given path template
+func (s *BricklinkService) getItemSubsets(ctx context.Context, pathTemplate, itemID string) (MinifigSubsets, error) {
+	if s.EndpointDisabled(EndpointSubsets) {
+		return nil, ErrEndpointDisabled
+	}
+
+	endpoint := fmt.Sprintf(pathTemplate, itemID)
 
 	var resp BricklinkResponse[MinifigSubsets]
 	if err := s.makeRequest(ctx, "GET", endpoint, nil, &resp); err != nil {
@@ -118,14 +396,73 @@ func (s *BricklinkService) GetMinifigSubsets(ctx context.Context, minifigID stri
 	return resp.Data, nil
 }
 
-// GetMinifigPrice fetches minifig price data
-func (s *BricklinkService) GetMinifigPrice(ctx context.Context, minifigID string) (*MinifigPrice, error) {
-	endpoint := fmt.Sprintf("/items/MINIFIG/%s/price", minifigID)
+// GetMinifigPrice fetches minifig price data for a condition ("N" or "U") and currency
+func (s *BricklinkService) GetMinifigPrice(ctx context.Context, minifigID, condition, currency string) (*MinifigPrice, error) {
+	return s.getMinifigPrice(ctx, minifigID, condition, currency)
+}
+
+// GetMinifigPriceBoth fetches new and used minifig price data concurrently
+func (s *BricklinkService) GetMinifigPriceBoth(ctx context.Context, minifigID, currency string) (*MinifigPrice, *MinifigPrice, error) {
+	return fetchPriceBoth(ctx, s.getMinifigPrice, minifigID, currency)
+}
+
+// fetchPriceBoth runs the new and used price lookups concurrently
+func fetchPriceBoth(
+	ctx context.Context,
+	price func(ctx context.Context, itemID, condition, currency string) (*MinifigPrice, error),
+	itemID, currency string,
+) (*MinifigPrice, *MinifigPrice, error) {
+	var newPrice, usedPrice *MinifigPrice
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		p, err := price(gCtx, itemID, "N", currency)
+		if err != nil {
+			return fmt.Errorf("failed to fetch new price: %w", err)
+		}
+		newPrice = p
+		return nil
+	})
+
+	g.Go(func() error {
+		p, err := price(gCtx, itemID, "U", currency)
+		if err != nil {
+			return fmt.Errorf("failed to fetch used price: %w", err)
+		}
+		usedPrice = p
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return newPrice, usedPrice, nil
+}
+
+// getMinifigPrice fetches minifig price data for a single condition ("N" or "U") and currency
+func (s *BricklinkService) getMinifigPrice(ctx context.Context, minifigID, condition, currency string) (*MinifigPrice, error) {
+	return s.getItemPrice(ctx, s.credentials.MinifigPricePath, minifigID, condition, currency)
+}
+
+// getSetPrice fetches set price data for a single condition ("N" or "U") and currency
+func (s *BricklinkService) getSetPrice(ctx context.Context, setID, condition, currency string) (*SetPrice, error) {
+	return s.getItemPrice(ctx, s.credentials.SetPricePath, setID, condition, currency)
+}
+
+// getItemPrice fetches the price guide of the item at the given path template
+func (s *BricklinkService) getItemPrice(ctx context.Context, pathTemplate, itemID, condition, currency string) (*MinifigPrice, error) {
+	if s.EndpointDisabled(EndpointPrice) {
+		return nil, ErrEndpointDisabled
+	}
+
+	endpoint := fmt.Sprintf(pathTemplate, itemID)
 
 	// Price endpoint needs query params
 	params := url.Values{}
-	params.Set("new_or_used", "N")
-	params.Set("currency_code", "USD")
+	params.Set("new_or_used", condition)
+	params.Set("currency_code", currency)
 
 	var resp BricklinkResponse[MinifigPrice]
 	if err := s.makeRequest(ctx, "GET", endpoint, params, &resp); err != nil {
@@ -135,8 +472,53 @@ func (s *BricklinkService) GetMinifigPrice(ctx context.Context, minifigID string
 	return &resp.Data, nil
 }
 
-// makeRequest handles OAuth1 signing and HTTP request
+// RequestObserver is told about every call made to the BrickLink API, e.g. to record metrics
+// endpoint is a low-cardinality label such as minifig_price or colors, see endpointLabel
+type RequestObserver func(endpoint string, duration time.Duration, err error)
+
+// ObserveRequests registers observer for all later BrickLink calls, calls refused by the rate limiter aren't reported
+func (s *BricklinkService) ObserveRequests(observer RequestObserver) {
+	s.observer = observer
+}
+
+// endpointLabel turns a request path into its endpoint without the item id, e.g. /items/SET/75192-1/price is set_price
+func endpointLabel(endpoint string) string {
+	parts := strings.Split(strings.Trim(endpoint, "/"), "/")
+	if parts[0] != "items" || len(parts) < 3 {
+		return parts[0]
+	}
+
+	kind := strings.ToLower(parts[1])
+	if len(parts) > 3 {
+		return kind + "_" + parts[3]
+	}
+	return kind + "_info"
+}
+
+// makeRequest handles OAuth1 signing and HTTP request with the current credentials, tracking whether BrickLink accepts them
+// Returns a *RateLimitError without calling BrickLink when the request budget is used up
 func (s *BricklinkService) makeRequest(ctx context.Context, method, endpoint string, params url.Values, result interface{}) error {
+	err := s.makeSignedRequest(ctx, s.oauthCredentials(), method, endpoint, params, result)
+	switch {
+	case errors.Is(err, ErrBrickLinkAuth):
+		s.authFailed.Store(true)
+	case err == nil:
+		s.authFailed.Store(false)
+	}
+	return err
+}
+
+// makeSignedRequest is makeRequest signing with creds, it leaves the auth status alone
+func (s *BricklinkService) makeSignedRequest(ctx context.Context, creds Credentials, method, endpoint string, params url.Values, result interface{}) (err error) {
+	if err := s.limiter.allow(ctx); err != nil {
+		return err
+	}
+
+	if s.observer != nil {
+		start := time.Now()
+		defer func() { s.observer(endpointLabel(endpoint), time.Since(start), err) }()
+	}
+
 	fullURL := s.baseURL + endpoint
 
 	// Add OAuth1 parameters
@@ -145,8 +527,8 @@ func (s *BricklinkService) makeRequest(ctx context.Context, method, endpoint str
 	}
 
 	// Generate OAuth1 signature
-	oauthParams := s.generateOAuthParams()
-	signedURL, err := s.signRequest(method, fullURL, params, oauthParams)
+	oauthParams := s.generateOAuthParams(creds)
+	signedURL, err := s.signRequest(method, fullURL, params, oauthParams, creds)
 	if err != nil {
 		return fmt.Errorf("failed to sign request: %w", err)
 	}
@@ -174,9 +556,26 @@ func (s *BricklinkService) makeRequest(ctx context.Context, method, endpoint str
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// BrickLink reports errors in the meta block, sometimes with a 200 HTTP status
+	var envelope struct {
+		Meta BricklinkMeta `json:"meta"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	status := resp.StatusCode
+	if status == http.StatusOK && envelope.Meta.Code != 0 {
+		status = envelope.Meta.Code
+	}
+
 	// Check status
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	switch status {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s %s", ErrBrickLinkAuth, envelope.Meta.Message, envelope.Meta.Description)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, endpoint)
+	default:
+		return fmt.Errorf("API error: status %d, body: %s", status, string(body))
 	}
 
 	// Decode JSON
@@ -188,13 +587,13 @@ func (s *BricklinkService) makeRequest(ctx context.Context, method, endpoint str
 }
 
 // OAuth1 helper functions
-func (s *BricklinkService) generateOAuthParams() map[string]string {
+func (s *BricklinkService) generateOAuthParams(creds Credentials) map[string]string {
 	nonce := make([]byte, 16)
 	rand.Read(nonce)
 
 	return map[string]string{
-		"oauth_consumer_key":     s.credentials.ConsumerKey,
-		"oauth_token":            s.credentials.AccessToken,
+		"oauth_consumer_key":     creds.ConsumerKey,
+		"oauth_token":            creds.AccessToken,
 		"oauth_signature_method": s.credentials.SignatureMethod,
 		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
 		"oauth_nonce":            base64.StdEncoding.EncodeToString(nonce),
@@ -202,7 +601,7 @@ func (s *BricklinkService) generateOAuthParams() map[string]string {
 	}
 }
 
-func (s *BricklinkService) signRequest(method, baseURL string, params url.Values, oauthParams map[string]string) (string, error) {
+func (s *BricklinkService) signRequest(method, baseURL string, params url.Values, oauthParams map[string]string, creds Credentials) (string, error) {
 	// Combine all parameters
 	allParams := url.Values{}
 	for k, v := range params {
@@ -221,8 +620,8 @@ func (s *BricklinkService) signRequest(method, baseURL string, params url.Values
 
 	// Create signing key
 	signingKey := fmt.Sprintf("%s&%s",
-		url.QueryEscape(s.credentials.ConsumerSecret),
-		url.QueryEscape(s.credentials.AccessTokenSecret))
+		url.QueryEscape(creds.ConsumerSecret),
+		url.QueryEscape(creds.AccessTokenSecret))
 
 	// Generate signature
 	mac := hmac.New(sha1.New, []byte(signingKey))
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/clock"
+)
+
+func TestMinifigExists_CachesNotFound(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"meta":{"code":404,"message":"RESOURCE_NOT_FOUND"},"data":{}}`))
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := newTestService(server.URL)
+	s.credentials.NotFoundCacheTTL = time.Minute
+	s.clock = fake
+
+	for i := 0; i < 3; i++ {
+		info, err := s.MinifigExists(context.Background(), "sw9999")
+		require.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, info)
+	}
+
+	assert.Equal(t, int32(1), hits.Load(), "missing ids should be served from the negative cache")
+
+	fake.Advance(time.Minute + time.Second)
+	_, err := s.MinifigExists(context.Background(), "sw9999")
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(2), hits.Load(), "expired entries should be checked again")
+}
+
+func TestMinifigExists_NotFoundCacheIsBounded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"meta":{"code":404,"message":"RESOURCE_NOT_FOUND"},"data":{}}`))
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := newTestService(server.URL)
+	s.credentials.NotFoundCacheTTL = time.Minute
+	s.clock = fake
+	s.notFoundLimit = 3
+
+	for _, id := range []string{"sw9001", "sw9002", "sw9003"} {
+		fake.Advance(time.Second)
+		_, err := s.MinifigExists(context.Background(), id)
+		require.ErrorIs(t, err, ErrNotFound)
+	}
+
+	fake.Advance(time.Second)
+	_, err := s.MinifigExists(context.Background(), "sw9004")
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Len(t, s.notFound, 3)
+	assert.NotContains(t, s.notFound, "sw9001", "the entry expiring soonest makes room")
+
+	// sw9003 has now expired, sw9004 expires this very moment and stays
+	fake.Advance(time.Minute)
+	_, err = s.MinifigExists(context.Background(), "sw9005")
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, []string{"sw9004", "sw9005"}, slices.Sorted(maps.Keys(s.notFound)), "expired entries are swept")
+}
+
+func TestMakeRequest_DetectsAuthFailure(t *testing.T) {
+	authFailure := atomic.Bool{}
+	authFailure.Store(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authFailure.Load() {
+			w.Write([]byte(`{"meta":{"code":401,"message":"BAD_OAUTH_REQUEST","description":"SIGNATURE_INVALID"},"data":{}}`))
+			return
+		}
+		w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001","name":"Battle Droid"}}`))
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+
+	_, err := s.GetMinifigInfo(context.Background(), "sw0001")
+	require.ErrorIs(t, err, ErrBrickLinkAuth)
+	assert.ErrorIs(t, s.AuthStatus(), ErrBrickLinkAuth)
+
+	authFailure.Store(false)
+	_, err = s.GetMinifigInfo(context.Background(), "sw0001")
+	require.NoError(t, err)
+	assert.NoError(t, s.AuthStatus(), "a successful call should clear the auth failure")
+}
+
+func TestGetMinifigComplete_DisabledPriceIsServedPartial(t *testing.T) {
+	var priceHits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/price"):
+			priceHits.Add(1)
+			w.Write([]byte(`{"meta":{"code":200},"data":{}}`))
+		case strings.HasSuffix(r.URL.Path, "/subsets"):
+			w.Write([]byte(`{"meta":{"code":200},"data":[]}`))
+		default:
+			w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001","name":"Battle Droid"}}`))
+		}
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+	s.credentials.DisabledEndpoints = []string{"price"}
+
+	result, err := s.GetMinifigComplete(context.Background(), "sw0001", PriceQuery{BothConditions: true})
+	require.NoError(t, err)
+	assert.True(t, result.PriceUnavailable)
+	assert.Nil(t, result.Price)
+	assert.Equal(t, "Battle Droid", result.Info.Name)
+	assert.Equal(t, int32(0), priceHits.Load(), "a disabled price lookup should not reach BrickLink")
+}
+
+func TestGetMinifigComplete_UsesRequestedCurrencyAndCondition(t *testing.T) {
+	var priceQuery atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/price"):
+			priceQuery.Store(r.URL.RawQuery)
+			w.Write([]byte(`{"meta":{"code":200},"data":{"currency_code":"EUR","new_or_used":"U"}}`))
+		case strings.HasSuffix(r.URL.Path, "/subsets"):
+			w.Write([]byte(`{"meta":{"code":200},"data":[]}`))
+		default:
+			w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001"}}`))
+		}
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+
+	result, err := s.GetMinifigComplete(context.Background(), "sw0001", PriceQuery{Currency: "EUR", Condition: "U"})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", result.Price.CurrencyCode)
+
+	query, _ := priceQuery.Load().(string)
+	assert.Contains(t, query, "currency_code=EUR")
+	assert.Contains(t, query, "new_or_used=U")
+}
+
+func TestGetMinifigComplete_CancelledMidFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/subsets") {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001","name":"Battle Droid"}}`))
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := s.GetMinifigComplete(ctx, "sw0001", PriceQuery{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, result)
+}
+
+// Run with -race, the three lookups finish concurrently and each records its timing
+func TestGetMinifigComplete_RecordsEveryTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/price"):
+			w.Write([]byte(`{"meta":{"code":200},"data":{"currency_code":"USD","new_or_used":"N"}}`))
+		case strings.HasSuffix(r.URL.Path, "/subsets"):
+			w.Write([]byte(`{"meta":{"code":200},"data":[]}`))
+		default:
+			w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001"}}`))
+		}
+	}))
+	defer server.Close()
+
+	s := newTestService(server.URL)
+
+	for i := 0; i < 20; i++ {
+		result, err := s.GetMinifigComplete(context.Background(), "sw0001", PriceQuery{BothConditions: true})
+		require.NoError(t, err)
+		assert.Len(t, result.IndividualFetchTimeMs, 3)
+		for _, endpoint := range []string{"info", "subsets", "price"} {
+			assert.Contains(t, result.IndividualFetchTimeMs, endpoint)
+		}
+	}
+}
+
+func TestGetMinifigComplete_WarnsOnSlowFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/price"):
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"meta":{"code":200},"data":{"currency_code":"USD","new_or_used":"N"}}`))
+		case strings.HasSuffix(r.URL.Path, "/subsets"):
+			w.Write([]byte(`{"meta":{"code":200},"data":[]}`))
+		default:
+			w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001"}}`))
+		}
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	s := newTestService(server.URL)
+
+	t.Run("under the threshold logs at info", func(t *testing.T) {
+		logs.Reset()
+		s.credentials.SlowFetchThreshold = time.Minute
+
+		_, err := s.GetMinifigComplete(context.Background(), "sw0001", PriceQuery{})
+		require.NoError(t, err)
+		assert.Contains(t, logs.String(), "BrickLink item fetched")
+		assert.NotContains(t, logs.String(), "Slow BrickLink item fetch")
+	})
+
+	t.Run("over the threshold warns with the slow endpoint", func(t *testing.T) {
+		logs.Reset()
+		s.credentials.SlowFetchThreshold = 20 * time.Millisecond
+
+		_, err := s.GetMinifigComplete(context.Background(), "sw0001", PriceQuery{})
+		require.NoError(t, err)
+		assert.Contains(t, logs.String(), "WARN")
+		assert.Contains(t, logs.String(), "Slow BrickLink item fetch")
+		assert.Contains(t, logs.String(), "slow_endpoints=price")
+	})
+}
+
+func TestSlowEndpoints(t *testing.T) {
+	timings := map[string]time.Duration{
+		"info":    6 * time.Second,
+		"subsets": time.Second,
+		"price":   7 * time.Second,
+	}
+
+	assert.Equal(t, []string{"info", "price"}, slowEndpoints(5*time.Second, timings))
+	assert.Empty(t, slowEndpoints(10*time.Second, timings))
+	assert.Empty(t, slowEndpoints(0, timings))
+}
+
+func TestEndpointLabel(t *testing.T) {
+	assert.Equal(t, "minifig_info", endpointLabel("/items/MINIFIG/sw0001"))
+	assert.Equal(t, "set_price", endpointLabel("/items/SET/75192-1/price"))
+	assert.Equal(t, "minifig_subsets", endpointLabel("/items/MINIFIG/sw0001/subsets"))
+	assert.Equal(t, "colors", endpointLabel("/colors"))
+}
+
+func TestObserveRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"meta":{"code":404,"message":"RESOURCE_NOT_FOUND"},"data":{}}`))
+	}))
+	defer server.Close()
+
+	var endpoints []string
+	var errs []error
+	s := newTestService(server.URL)
+	s.ObserveRequests(func(endpoint string, duration time.Duration, err error) {
+		endpoints = append(endpoints, endpoint)
+		errs = append(errs, err)
+	})
+
+	_, err := s.GetMinifigInfo(context.Background(), "sw9999")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, []string{"minifig_info"}, endpoints)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrNotFound)
+}
@@ -0,0 +1,97 @@
+package service
+
+// Sets share the catalog item, subset and price guide shapes with minifigs
+type (
+	SetInfo    = MinifigInfo
+	SetSubsets = MinifigSubsets
+	SetPrice   = MinifigPrice
+)
+
+// SetComplete holds the raw set data fetched by GetSetComplete
+type SetComplete MinifigComplete
+
+// BrickLink item types found in set subsets
+const (
+	itemTypePart    = "PART"
+	itemTypeMinifig = "MINIFIG"
+)
+
+// Structured combined set response
+type SetCompleteResponse struct {
+	SetID     string            `json:"set_id"`
+	BasicInfo MinifigBasicInfo  `json:"basic_info"`
+	Contents  SetContents       `json:"contents"`
+	Market    MinifigMarketData `json:"market_data"`
+	Images    MinifigImages     `json:"images"`
+	Metadata  ResponseMetadata  `json:"metadata"`
+}
+
+// SetContents splits a set's inventory into parts, minifigs and anything else (gear, instructions...)
+// Totals leave out alternate entries, which are substitutes rather than extra pieces
+type SetContents struct {
+	TotalParts    int             `json:"total_parts"`
+	TotalMinifigs int             `json:"total_minifigs"`
+	Parts         []ComponentPart `json:"parts"`
+	Minifigs      []SetMinifig    `json:"minifigs"`
+	Other         []ComponentPart `json:"other"`
+}
+
+type SetMinifig struct {
+	MinifigID    string `json:"minifig_id"`
+	Name         string `json:"name"`
+	Quantity     int    `json:"quantity"`
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `json:"category_name,omitempty"`
+}
+
+// ToStructuredResponse converts raw set data to the structured response
+// The catalog resolves color and category names and may be nil
+func (sc *SetComplete) ToStructuredResponse(catalog *Catalog) *SetCompleteResponse {
+	mc := (*MinifigComplete)(sc)
+	marketData := toAvailableMarketData(sc.Price, sc.UsedPrice, sc.PriceUnavailable)
+
+	return &SetCompleteResponse{
+		SetID:     itemNo(sc.Info),
+		BasicInfo: toBasicInfo(sc.Info, catalog),
+		Contents:  toSetContents(sc.Subsets, catalog),
+		Market:    marketData,
+		Images:    toImages(sc.Info),
+		Metadata:  toMetadata(mc, sc.Info != nil && marketData.Available),
+	}
+}
+
+// toSetContents sorts subset entries by item type
+func toSetContents(subsets SetSubsets, catalog *Catalog) SetContents {
+	contents := SetContents{
+		Parts:    []ComponentPart{},
+		Minifigs: []SetMinifig{},
+		Other:    []ComponentPart{},
+	}
+
+	for _, group := range subsets {
+		for _, entry := range group.Entries {
+			switch entry.Item.Type {
+			case itemTypeMinifig:
+				contents.Minifigs = append(contents.Minifigs, SetMinifig{
+					MinifigID:    entry.Item.No,
+					Name:         entry.Item.Name,
+					Quantity:     entry.Quantity,
+					CategoryID:   entry.Item.CategoryID,
+					CategoryName: catalog.CategoryName(entry.Item.CategoryID),
+				})
+				if !entry.IsAlternate {
+					contents.TotalMinifigs += entry.Quantity
+				}
+			case itemTypePart:
+				contents.Parts = append(contents.Parts, toComponentPart(entry, catalog))
+				if !entry.IsAlternate {
+					contents.TotalParts += entry.Quantity
+				}
+			default:
+				contents.Other = append(contents.Other, toComponentPart(entry, catalog))
+			}
+		}
+	}
+
+	return contents
+}
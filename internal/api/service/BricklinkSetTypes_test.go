@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSetContents_SplitsPartsAndMinifigs(t *testing.T) {
+	subsets := SetSubsets{
+		{Entries: []SubsetEntry{{Item: SubsetItem{No: "3001", Type: "PART"}, Quantity: 4}}},
+		{Entries: []SubsetEntry{{Item: SubsetItem{No: "sw0001", Name: "Battle Droid", Type: "MINIFIG"}, Quantity: 2}}},
+		{Entries: []SubsetEntry{
+			{Item: SubsetItem{No: "3002", Type: "PART"}, Quantity: 1},
+			{Item: SubsetItem{No: "3003", Type: "PART"}, Quantity: 1, IsAlternate: true},
+		}},
+		{Entries: []SubsetEntry{{Item: SubsetItem{No: "75192-1", Type: "INSTRUCTION"}, Quantity: 1}}},
+	}
+
+	contents := toSetContents(subsets, nil)
+
+	assert.Len(t, contents.Parts, 3)
+	assert.Equal(t, 5, contents.TotalParts, "alternates should not be counted")
+	require.Len(t, contents.Minifigs, 1)
+	assert.Equal(t, "sw0001", contents.Minifigs[0].MinifigID)
+	assert.Equal(t, 2, contents.TotalMinifigs)
+	require.Len(t, contents.Other, 1)
+	assert.Equal(t, "INSTRUCTION", contents.Other[0].PartType)
+}
+
+func TestGetSetComplete_UsesSetEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/items/SET/75192-1") {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/price"):
+			w.Write([]byte(`{"meta":{"code":200},"data":{"currency_code":"USD","avg_price":"800.00"}}`))
+		case strings.HasSuffix(r.URL.Path, "/subsets"):
+			w.Write([]byte(`{"meta":{"code":200},"data":[{"entries":[{"item":{"no":"sw0001","type":"MINIFIG"},"quantity":1}]}]}`))
+		default:
+			w.Write([]byte(`{"meta":{"code":200},"data":{"no":"75192-1","name":"Millennium Falcon","type":"SET"}}`))
+		}
+	}))
+	defer server.Close()
+
+	result, err := newTestService(server.URL).GetSetComplete(context.Background(), "75192-1", PriceQuery{})
+	require.NoError(t, err)
+
+	resp := result.ToStructuredResponse(nil)
+	assert.Equal(t, "75192-1", resp.SetID)
+	assert.Equal(t, "Millennium Falcon", resp.BasicInfo.Name)
+	assert.Equal(t, 1, resp.Contents.TotalMinifigs)
+	assert.Equal(t, Money{minor: 80000, decimals: 2}, resp.Market.PriceSummary.Average)
+}
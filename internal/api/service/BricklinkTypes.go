@@ -3,15 +3,50 @@ package service
 import (
 	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"golang.org/x/sync/singleflight"
+
+	"LegoManagerAPI/internal/clock"
 	"LegoManagerAPI/internal/config/bricklink"
 )
 
 type BricklinkService struct {
-	credentials bricklink.BricklinkConfig
-	baseURL     string
-	httpClient  *http.Client
+	// The OAuth secrets in credentials change at runtime, access them only through oauthCredentials and setCredentials
+	// The other fields are never written after construction and can be read directly
+	credentialsMu sync.RWMutex
+	credentials   bricklink.BricklinkConfig
+
+	baseURL    string
+	httpClient *http.Client
+
+	// In-memory color/category catalog, loaded lazily on first use
+	// catalogFailedAt is when the last load failed, zero after a success, loads back off from it
+	catalogMu         sync.RWMutex
+	catalog           *Catalog
+	catalogFailedAt   time.Time
+	catalogGroup      singleflight.Group
+	catalogRefreshing atomic.Bool
+
+	// Minifig ids BrickLink reported as missing, mapped to when the entry expires, at most notFoundLimit of them
+	notFoundMu    sync.Mutex
+	notFound      map[string]time.Time
+	notFoundLimit int
+
+	// Set when BrickLink rejected our credentials, cleared on the next successful call
+	authFailed atomic.Bool
+
+	// Keeps us within BrickLink's per-second and daily request caps
+	limiter requestLimiter
+
+	// Time source for the negative cache expiry and the catalog refresh and backoff
+	clock clock.Clock
+
+	// Optional hook reporting every BrickLink call, see ObserveRequests
+	observer RequestObserver
 }
 
 // Common response wrapper
@@ -66,6 +101,21 @@ type SubsetItem struct {
 	CategoryID int    `json:"category_id"`
 }
 
+// Color response
+type Color struct {
+	ColorID   int    `json:"color_id"`
+	ColorName string `json:"color_name"`
+	ColorCode string `json:"color_code"`
+	ColorType string `json:"color_type"`
+}
+
+// Category response
+type Category struct {
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	ParentID     int    `json:"parent_id"`
+}
+
 // Price response
 type MinifigPrice struct {
 	Item          PriceItem     `json:"item"`
@@ -105,6 +155,7 @@ type MinifigBasicInfo struct {
 	Name         string     `json:"name"`
 	Type         string     `json:"type"`
 	CategoryID   int        `json:"category_id"`
+	CategoryName string     `json:"category_name,omitempty"`
 	YearReleased int        `json:"year_released"`
 	IsObsolete   bool       `json:"is_obsolete"`
 	Dimensions   Dimensions `json:"dimensions"`
@@ -118,33 +169,40 @@ type Dimensions struct {
 }
 
 type MinifigComponents struct {
-	TotalParts int             `json:"total_parts"`
-	Parts      []ComponentPart `json:"parts"`
+	HasComponents bool            `json:"has_components"`
+	TotalParts    int             `json:"total_parts"`
+	Parts         []ComponentPart `json:"parts"`
 }
 
 type ComponentPart struct {
-	PartNumber  string `json:"part_number"`
-	PartName    string `json:"part_name"`
-	PartType    string `json:"part_type"`
-	ColorID     int    `json:"color_id"`
-	Quantity    int    `json:"quantity"`
-	IsAlternate bool   `json:"is_alternate"`
-	CategoryID  int    `json:"category_id"`
+	PartNumber   string `json:"part_number"`
+	PartName     string `json:"part_name"`
+	PartType     string `json:"part_type"`
+	ColorID      int    `json:"color_id"`
+	ColorName    string `json:"color_name,omitempty"`
+	Quantity     int    `json:"quantity"`
+	IsAlternate  bool   `json:"is_alternate"`
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `json:"category_name,omitempty"`
 }
 
 type MinifigMarketData struct {
-	Currency       string                `json:"currency"`
-	Condition      string                `json:"condition"`
-	PriceSummary   PriceSummary          `json:"price_summary"`
-	Availability   AvailabilitySummary   `json:"availability"`
-	PriceBreakdown []PriceBreakdownEntry `json:"price_breakdown"`
+	Available      bool                    `json:"available"`
+	Reason         string                  `json:"unavailable_reason,omitempty"`
+	Currency       string                  `json:"currency"`
+	Condition      string                  `json:"condition"`
+	PriceSummary   PriceSummary            `json:"price_summary"`
+	ByCondition    map[string]PriceSummary `json:"price_summary_by_condition,omitempty"`
+	Availability   AvailabilitySummary     `json:"availability"`
+	PriceBreakdown []PriceBreakdownEntry   `json:"price_breakdown"`
 }
 
+// PriceSummary amounts are in the market data's currency, rounded to its decimals
 type PriceSummary struct {
-	Minimum         float64 `json:"minimum_usd"`
-	Maximum         float64 `json:"maximum_usd"`
-	Average         float64 `json:"average_usd"`
-	WeightedAverage float64 `json:"weighted_average_usd"`
+	Minimum         Money `json:"minimum"`
+	Maximum         Money `json:"maximum"`
+	Average         Money `json:"average"`
+	WeightedAverage Money `json:"weighted_average"`
 }
 
 type AvailabilitySummary struct {
@@ -155,9 +213,9 @@ type AvailabilitySummary struct {
 }
 
 type PriceBreakdownEntry struct {
-	Quantity          int     `json:"quantity"`
-	PricePerUnit      float64 `json:"price_per_unit_usd"`
-	ShippingAvailable bool    `json:"shipping_available"`
+	Quantity          int   `json:"quantity"`
+	PricePerUnit      Money `json:"price_per_unit"`
+	ShippingAvailable bool  `json:"shipping_available"`
 }
 
 type MinifigImages struct {
@@ -170,6 +228,9 @@ type ResponseMetadata struct {
 	TotalFetchTimeMs int64           `json:"total_fetch_time_ms"`
 	EndpointTimings  EndpointTimings `json:"endpoint_timings_ms"`
 	DataSources      []string        `json:"data_sources"`
+	Partial          bool            `json:"partial"`
+	Cached           bool            `json:"cached"`
+	AgeSeconds       int64           `json:"age_seconds"`
 }
 
 type EndpointTimings struct {
@@ -182,65 +243,159 @@ type MinifigComplete struct {
 	Info                  *MinifigInfo     `json:"info"`
 	Subsets               MinifigSubsets   `json:"subsets"`
 	Price                 *MinifigPrice    `json:"price"`
+	UsedPrice             *MinifigPrice    `json:"used_price,omitempty"`
 	FetchTimeMs           int64            `json:"fetch_time_ms"`
 	IndividualFetchTimeMs map[string]int64 `json:"individual_fetch_time_ms"`
+	CachedAt              *time.Time       `json:"cached_at,omitempty"`
+	PriceUnavailable      bool             `json:"price_unavailable,omitempty"`
 }
 
 // Helper to convert raw response to structured response
-func (mc *MinifigComplete) ToStructuredResponse() *MinifigCompleteResponse {
-	// Extract basic info
-	basicInfo := MinifigBasicInfo{
-		Name:         mc.Info.Name,
-		Type:         mc.Info.Type,
-		CategoryID:   mc.Info.CategoryID,
-		YearReleased: mc.Info.YearReleased,
-		IsObsolete:   mc.Info.IsObsolete,
-		Dimensions: Dimensions{
-			Weight: mc.Info.Weight,
-			Length: mc.Info.DimX,
-			Width:  mc.Info.DimY,
-			Height: mc.Info.DimZ,
-		},
+// The catalog resolves color and category names and may be nil
+func (mc *MinifigComplete) ToStructuredResponse(catalog *Catalog) *MinifigCompleteResponse {
+	marketData := toAvailableMarketData(mc.Price, mc.UsedPrice, mc.PriceUnavailable)
+
+	return &MinifigCompleteResponse{
+		MinifigID:  itemNo(mc.Info),
+		BasicInfo:  toBasicInfo(mc.Info, catalog),
+		Components: toComponents(mc.Subsets, catalog),
+		Market:     marketData,
+		Images:     toImages(mc.Info),
+		Metadata:   toMetadata(mc, mc.Info != nil && marketData.Available),
 	}
+}
 
-	// Extract components
-	var parts []ComponentPart
+// toComponents flattens subset groups into parts, some minifigs (e.g. solid-piece figs) have no breakdown
+func toComponents(subsets MinifigSubsets, catalog *Catalog) MinifigComponents {
+	parts := []ComponentPart{}
 	totalParts := 0
-	for _, group := range mc.Subsets {
+	for _, group := range subsets {
 		for _, entry := range group.Entries {
-			parts = append(parts, ComponentPart{
-				PartNumber:  entry.Item.No,
-				PartName:    entry.Item.Name,
-				PartType:    entry.Item.Type,
-				ColorID:     entry.ColorID,
-				Quantity:    entry.Quantity,
-				IsAlternate: entry.IsAlternate,
-				CategoryID:  entry.Item.CategoryID,
-			})
+			parts = append(parts, toComponentPart(entry, catalog))
 			totalParts += entry.Quantity
 		}
 	}
 
-	components := MinifigComponents{
-		TotalParts: totalParts,
-		Parts:      parts,
+	return MinifigComponents{
+		HasComponents: len(parts) > 0,
+		TotalParts:    totalParts,
+		Parts:         parts,
+	}
+}
+
+// toComponentPart converts a subset entry, resolving color and category names from the catalog
+func toComponentPart(entry SubsetEntry, catalog *Catalog) ComponentPart {
+	return ComponentPart{
+		PartNumber:   entry.Item.No,
+		PartName:     entry.Item.Name,
+		PartType:     entry.Item.Type,
+		ColorID:      entry.ColorID,
+		ColorName:    catalog.ColorName(entry.ColorID),
+		Quantity:     entry.Quantity,
+		IsAlternate:  entry.IsAlternate,
+		CategoryID:   entry.Item.CategoryID,
+		CategoryName: catalog.CategoryName(entry.Item.CategoryID),
+	}
+}
+
+// itemNo returns the item number, or an empty string when info is missing
+func itemNo(info *MinifigInfo) string {
+	if info == nil {
+		return ""
+	}
+	return info.No
+}
+
+// toBasicInfo summarizes catalog info, the catalog resolves the category name and may be nil
+// Missing info yields an empty summary so the rest of the response is still served
+func toBasicInfo(info *MinifigInfo, catalog *Catalog) MinifigBasicInfo {
+	if info == nil {
+		return MinifigBasicInfo{}
+	}
+
+	return MinifigBasicInfo{
+		Name:         info.Name,
+		Type:         info.Type,
+		CategoryID:   info.CategoryID,
+		CategoryName: catalog.CategoryName(info.CategoryID),
+		YearReleased: info.YearReleased,
+		IsObsolete:   info.IsObsolete,
+		Dimensions: Dimensions{
+			Weight: info.Weight,
+			Length: info.DimX,
+			Width:  info.DimY,
+			Height: info.DimZ,
+		},
+	}
+}
+
+// toImages returns the item's image URLs, BrickLink omits the scheme so https: is added
+func toImages(info *MinifigInfo) MinifigImages {
+	if info == nil {
+		return MinifigImages{}
+	}
+
+	imageURL := info.ImageURL
+	thumbnailURL := info.ThumbnailURL
+	if strings.HasPrefix(imageURL, "//") {
+		imageURL = "https:" + imageURL
+	}
+	if strings.HasPrefix(thumbnailURL, "//") {
+		thumbnailURL = "https:" + thumbnailURL
 	}
 
-	// Extract market data with proper float parsing
-	minPrice, _ := strconv.ParseFloat(mc.Price.MinPrice, 64)
-	maxPrice, _ := strconv.ParseFloat(mc.Price.MaxPrice, 64)
-	avgPrice, _ := strconv.ParseFloat(mc.Price.AvgPrice, 64)
-	qtyAvgPrice, _ := strconv.ParseFloat(mc.Price.QtyAvgPrice, 64)
+	return MinifigImages{
+		FullSize:  imageURL,
+		Thumbnail: thumbnailURL,
+	}
+}
 
+// toMetadata reports fetch timings and cache age, complete is false when part of the data was left out
+func toMetadata(mc *MinifigComplete, complete bool) ResponseMetadata {
+	metadata := ResponseMetadata{
+		FetchedAt:        fmt.Sprintf("%d", mc.FetchTimeMs),
+		TotalFetchTimeMs: mc.FetchTimeMs,
+		EndpointTimings: EndpointTimings{
+			BasicInfo:  mc.IndividualFetchTimeMs["info"],
+			Components: mc.IndividualFetchTimeMs["subsets"],
+			MarketData: mc.IndividualFetchTimeMs["price"],
+		},
+		DataSources: []string{"Bricklink API v1"},
+		Partial:     !complete,
+	}
+
+	// Let clients show how old cached data is
+	if mc.CachedAt != nil {
+		metadata.Cached = true
+		metadata.AgeSeconds = int64(time.Since(*mc.CachedAt).Seconds())
+	}
+
+	return metadata
+}
+
+// toAvailableMarketData summarizes prices, or marks them unavailable when the lookup was switched off
+// to conserve quota, the rest of the response is still served
+func toAvailableMarketData(price, usedPrice *MinifigPrice, unavailable bool) MinifigMarketData {
+	if unavailable || price == nil {
+		return MinifigMarketData{
+			Available: false,
+			Reason:    "price temporarily unavailable",
+		}
+	}
+	return toMarketData(price, usedPrice)
+}
+
+// toMarketData summarizes the price guide, bracketing it with used prices when they were fetched too
+func toMarketData(price, usedPrice *MinifigPrice) MinifigMarketData {
 	var priceBreakdown []PriceBreakdownEntry
 	withShipping := 0
 	withoutShipping := 0
 
-	for _, detail := range mc.Price.PriceDetail {
-		price, _ := strconv.ParseFloat(detail.UnitPrice, 64)
+	for _, detail := range price.PriceDetail {
+		unitPrice, _ := ParseMoney(detail.UnitPrice, price.CurrencyCode)
 		priceBreakdown = append(priceBreakdown, PriceBreakdownEntry{
 			Quantity:          detail.Quantity,
-			PricePerUnit:      price,
+			PricePerUnit:      unitPrice,
 			ShippingAvailable: detail.ShippingAvailable,
 		})
 
@@ -252,56 +407,41 @@ func (mc *MinifigComplete) ToStructuredResponse() *MinifigCompleteResponse {
 	}
 
 	marketData := MinifigMarketData{
-		Currency:  mc.Price.CurrencyCode,
-		Condition: mc.Price.NewOrUsed,
-		PriceSummary: PriceSummary{
-			Minimum:         minPrice,
-			Maximum:         maxPrice,
-			Average:         avgPrice,
-			WeightedAverage: qtyAvgPrice,
-		},
+		Available:    true,
+		Currency:     price.CurrencyCode,
+		Condition:    price.NewOrUsed,
+		PriceSummary: toPriceSummary(price),
 		Availability: AvailabilitySummary{
-			TotalListings:   mc.Price.UnitQuantity,
-			TotalQuantity:   mc.Price.TotalQuantity,
+			TotalListings:   price.UnitQuantity,
+			TotalQuantity:   price.TotalQuantity,
 			WithShipping:    withShipping,
 			WithoutShipping: withoutShipping,
 		},
 		PriceBreakdown: priceBreakdown,
 	}
 
-	// Fix image URLs (add https:)
-	imageURL := mc.Info.ImageURL
-	thumbnailURL := mc.Info.ThumbnailURL
-	if imageURL != "" && imageURL[:2] == "//" {
-		imageURL = "https:" + imageURL
-	}
-	if thumbnailURL != "" && thumbnailURL[:2] == "//" {
-		thumbnailURL = "https:" + thumbnailURL
-	}
-
-	images := MinifigImages{
-		FullSize:  imageURL,
-		Thumbnail: thumbnailURL,
+	if usedPrice != nil {
+		marketData.ByCondition = map[string]PriceSummary{
+			"new":  marketData.PriceSummary,
+			"used": toPriceSummary(usedPrice),
+		}
 	}
 
-	// Metadata
-	metadata := ResponseMetadata{
-		FetchedAt:        fmt.Sprintf("%d", mc.FetchTimeMs),
-		TotalFetchTimeMs: mc.FetchTimeMs,
-		EndpointTimings: EndpointTimings{
-			BasicInfo:  mc.IndividualFetchTimeMs["info"],
-			Components: mc.IndividualFetchTimeMs["subsets"],
-			MarketData: mc.IndividualFetchTimeMs["price"],
-		},
-		DataSources: []string{"Bricklink API v1"},
-	}
+	return marketData
+}
 
-	return &MinifigCompleteResponse{
-		MinifigID:  mc.Info.No,
-		BasicInfo:  basicInfo,
-		Components: components,
-		Market:     marketData,
-		Images:     images,
-		Metadata:   metadata,
+// toPriceSummary parses the string prices returned by BrickLink into a PriceSummary
+// Unparseable prices are reported as zero
+func toPriceSummary(price *MinifigPrice) PriceSummary {
+	minPrice, _ := ParseMoney(price.MinPrice, price.CurrencyCode)
+	maxPrice, _ := ParseMoney(price.MaxPrice, price.CurrencyCode)
+	avgPrice, _ := ParseMoney(price.AvgPrice, price.CurrencyCode)
+	qtyAvgPrice, _ := ParseMoney(price.QtyAvgPrice, price.CurrencyCode)
+
+	return PriceSummary{
+		Minimum:         minPrice,
+		Maximum:         maxPrice,
+		Average:         avgPrice,
+		WeightedAverage: qtyAvgPrice,
 	}
 }
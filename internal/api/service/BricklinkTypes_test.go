@@ -0,0 +1,65 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToStructuredResponse_EmptySubsets(t *testing.T) {
+	mc := &MinifigComplete{
+		Info:                  &MinifigInfo{No: "sw0001", Name: "Battle Droid"},
+		Subsets:               MinifigSubsets{},
+		Price:                 &MinifigPrice{},
+		IndividualFetchTimeMs: map[string]int64{},
+	}
+
+	resp := mc.ToStructuredResponse(nil)
+
+	assert.False(t, resp.Components.HasComponents)
+	assert.Equal(t, 0, resp.Components.TotalParts)
+	assert.NotNil(t, resp.Components.Parts)
+
+	body, err := json.Marshal(resp.Components)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"has_components":false,"total_parts":0,"parts":[]}`, string(body))
+}
+
+func TestToStructuredResponse_PriceUnavailable(t *testing.T) {
+	mc := &MinifigComplete{
+		Info:                  &MinifigInfo{No: "sw0001", Name: "Battle Droid"},
+		Subsets:               MinifigSubsets{},
+		PriceUnavailable:      true,
+		IndividualFetchTimeMs: map[string]int64{},
+	}
+
+	resp := mc.ToStructuredResponse(nil)
+
+	assert.Equal(t, "Battle Droid", resp.BasicInfo.Name)
+	assert.False(t, resp.Market.Available)
+	assert.Equal(t, "price temporarily unavailable", resp.Market.Reason)
+	assert.True(t, resp.Metadata.Partial)
+}
+
+func TestToStructuredResponse_MissingInfoAndPrice(t *testing.T) {
+	mc := &MinifigComplete{
+		Subsets:               MinifigSubsets{},
+		IndividualFetchTimeMs: map[string]int64{},
+	}
+
+	resp := mc.ToStructuredResponse(nil)
+
+	assert.Empty(t, resp.MinifigID)
+	assert.Empty(t, resp.Images.FullSize)
+	assert.False(t, resp.Market.Available)
+	assert.True(t, resp.Metadata.Partial)
+}
+
+func TestToImages_ShortURLs(t *testing.T) {
+	images := toImages(&MinifigInfo{ImageURL: "/", ThumbnailURL: "//img.bricklink.com/sw0001.png"})
+
+	assert.Equal(t, "/", images.FullSize)
+	assert.Equal(t, "https://img.bricklink.com/sw0001.png", images.Thumbnail)
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+
+	"LegoManagerAPI/internal/cache"
+)
+
+// CachedBricklinkClient wraps BricklinkService with a Redis cache, single-flight
+// deduplication and a cap on concurrent upstream calls
+// Features fanning out to BrickLink should go through this client rather than the raw service
+type CachedBricklinkClient struct {
+	service *BricklinkService
+	redis   *cache.RedisClient
+	ttl     time.Duration
+
+	group     singleflight.Group
+	semaphore chan struct{}
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	upstreamCalls atomic.Int64
+	errors        atomic.Int64
+}
+
+// ClientStats is a snapshot of the CachedBricklinkClient counters
+type ClientStats struct {
+	CacheHits     int64 `json:"cache_hits"`
+	CacheMisses   int64 `json:"cache_misses"`
+	UpstreamCalls int64 `json:"upstream_calls"`
+	Errors        int64 `json:"errors"`
+}
+
+// NewCachedBricklinkClient creates a client around the given service
+// A nil redisClient disables caching, maxConcurrency below 1 is treated as 1
+func NewCachedBricklinkClient(bricklinkService *BricklinkService, redisClient *cache.RedisClient, ttl time.Duration, maxConcurrency int) *CachedBricklinkClient {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
+	return &CachedBricklinkClient{
+		service:   bricklinkService,
+		redis:     redisClient,
+		ttl:       ttl,
+		semaphore: make(chan struct{}, maxConcurrency),
+	}
+}
+
+// Service returns the wrapped BricklinkService
+func (c *CachedBricklinkClient) Service() *BricklinkService {
+	return c.service
+}
+
+// Stats returns the current cache and upstream counters
+func (c *CachedBricklinkClient) Stats() ClientStats {
+	return ClientStats{
+		CacheHits:     c.hits.Load(),
+		CacheMisses:   c.misses.Load(),
+		UpstreamCalls: c.upstreamCalls.Load(),
+		Errors:        c.errors.Load(),
+	}
+}
+
+// Minifig returns the basic info for a minifig
+func (c *CachedBricklinkClient) Minifig(ctx context.Context, minifigID string) (*MinifigInfo, error) {
+	info, _, err := c.info(ctx, c.service.minifigFetchers(), minifigID)
+	return info, err
+}
+
+// Subsets returns the parts a minifig is made of
+func (c *CachedBricklinkClient) Subsets(ctx context.Context, minifigID string) (MinifigSubsets, error) {
+	subsets, _, err := c.subsets(ctx, c.service.minifigFetchers(), minifigID)
+	return subsets, err
+}
+
+// Price returns the price guide for a minifig in the given condition (N or U) and currency
+func (c *CachedBricklinkClient) Price(ctx context.Context, minifigID, condition, currency string) (*MinifigPrice, error) {
+	price, _, err := c.price(ctx, c.service.minifigFetchers(), minifigID, condition, currency)
+	return price, err
+}
+
+// ItemPrice returns the price guide for an item of any enabled type in the given condition (N or U) and currency
+// Returns ErrUnsupportedItemType for types that aren't enabled
+func (c *CachedBricklinkClient) ItemPrice(ctx context.Context, itemType ItemType, itemID, condition, currency string) (*MinifigPrice, error) {
+	fetch, err := c.service.fetchersFor(itemType)
+	if err != nil {
+		return nil, err
+	}
+	price, _, err := c.price(ctx, fetch, itemID, condition, currency)
+	return price, err
+}
+
+// MinifigComplete fetches info, subsets and prices concurrently through the cached lookups
+// CachedAt is set to the oldest fetch time when any part was served from the cache
+func (c *CachedBricklinkClient) MinifigComplete(ctx context.Context, minifigID string, query PriceQuery) (*MinifigComplete, error) {
+	return c.itemComplete(ctx, c.service.minifigFetchers(), minifigID, query)
+}
+
+// SetComplete fetches set info, subsets and prices concurrently through the cached lookups
+// CachedAt is set to the oldest fetch time when any part was served from the cache
+func (c *CachedBricklinkClient) SetComplete(ctx context.Context, setID string, query PriceQuery) (*SetComplete, error) {
+	result, err := c.itemComplete(ctx, c.service.setFetchers(), setID, query)
+	if err != nil {
+		return nil, err
+	}
+	return (*SetComplete)(result), nil
+}
+
+// ItemComplete fetches info, subsets and prices for an item of any enabled type through the cached lookups
+// Returns ErrUnsupportedItemType for types that aren't enabled
+func (c *CachedBricklinkClient) ItemComplete(ctx context.Context, itemType ItemType, itemID string, query PriceQuery) (*MinifigComplete, error) {
+	fetch, err := c.service.fetchersFor(itemType)
+	if err != nil {
+		return nil, err
+	}
+	return c.itemComplete(ctx, fetch, itemID, query)
+}
+
+// itemComplete runs fetchItemComplete with each of the given lookups served through the cache
+func (c *CachedBricklinkClient) itemComplete(ctx context.Context, fetch itemFetchers, itemID string, query PriceQuery) (*MinifigComplete, error) {
+	var fresh freshness
+
+	result, err := fetchItemComplete(ctx, itemFetchers{
+		kind: fetch.kind,
+		info: func(ctx context.Context, itemID string) (*MinifigInfo, error) {
+			info, fetchedAt, err := c.info(ctx, fetch, itemID)
+			fresh.observe(fetchedAt)
+			return info, err
+		},
+		subsets: func(ctx context.Context, itemID string) (MinifigSubsets, error) {
+			subsets, fetchedAt, err := c.subsets(ctx, fetch, itemID)
+			fresh.observe(fetchedAt)
+			return subsets, err
+		},
+		price: func(ctx context.Context, itemID, condition, currency string) (*MinifigPrice, error) {
+			price, fetchedAt, err := c.price(ctx, fetch, itemID, condition, currency)
+			fresh.observe(fetchedAt)
+			return price, err
+		},
+	}, itemID, query, c.service.credentials.SlowFetchThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	result.CachedAt = fresh.cachedAt()
+	return result, nil
+}
+
+func (c *CachedBricklinkClient) info(ctx context.Context, fetch itemFetchers, itemID string) (*MinifigInfo, time.Time, error) {
+	key := fmt.Sprintf("bricklink:%s:%s:info", fetch.kind, itemID)
+	return cached(ctx, c, key, func(ctx context.Context) (*MinifigInfo, error) {
+		return fetch.info(ctx, itemID)
+	})
+}
+
+func (c *CachedBricklinkClient) subsets(ctx context.Context, fetch itemFetchers, itemID string) (MinifigSubsets, time.Time, error) {
+	key := fmt.Sprintf("bricklink:%s:%s:subsets", fetch.kind, itemID)
+	return cached(ctx, c, key, func(ctx context.Context) (MinifigSubsets, error) {
+		return fetch.subsets(ctx, itemID)
+	})
+}
+
+func (c *CachedBricklinkClient) price(ctx context.Context, fetch itemFetchers, itemID, condition, currency string) (*MinifigPrice, time.Time, error) {
+	key := fmt.Sprintf("bricklink:%s:%s:price:%s:%s", fetch.kind, itemID, condition, currency)
+	return cached(ctx, c, key, func(ctx context.Context) (*MinifigPrice, error) {
+		return fetch.price(ctx, itemID, condition, currency)
+	})
+}
+
+// MinifigExists reports whether BrickLink knows the minifig, see BricklinkService.MinifigExists
+func (c *CachedBricklinkClient) MinifigExists(ctx context.Context, minifigID string) (*MinifigInfo, error) {
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer c.release()
+
+	return c.service.MinifigExists(ctx, minifigID)
+}
+
+// Catalog returns the color/category catalog of the wrapped service
+func (c *CachedBricklinkClient) Catalog(ctx context.Context) *Catalog {
+	return c.service.Catalog(ctx)
+}
+
+// acquire takes a concurrency slot, giving up when ctx is done
+func (c *CachedBricklinkClient) acquire(ctx context.Context) error {
+	select {
+	case c.semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release returns a concurrency slot
+func (c *CachedBricklinkClient) release() {
+	<-c.semaphore
+}
+
+// cacheEntry is the stored form of a cached value, stamped with when it was fetched from BrickLink
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// freshness tracks the oldest cache entry used to build a combined response
+type freshness struct {
+	mu     sync.Mutex
+	oldest time.Time
+}
+
+// observe records a fetch time, zero times come from live fetches and are ignored
+func (f *freshness) observe(fetchedAt time.Time) {
+	if fetchedAt.IsZero() {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.oldest.IsZero() || fetchedAt.Before(f.oldest) {
+		f.oldest = fetchedAt
+	}
+}
+
+// cachedAt returns the oldest observed fetch time, or nil when everything was fetched live
+func (f *freshness) cachedAt() *time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.oldest.IsZero() {
+		return nil
+	}
+
+	oldest := f.oldest
+	return &oldest
+}
+
+// cached looks key up in Redis and otherwise calls fetch once per key across concurrent
+// callers, bounded by the client's concurrency cap, storing the result for the client's ttl
+// The returned time is when a cached value was fetched, and zero for a live fetch
+func cached[T any](ctx context.Context, c *CachedBricklinkClient, key string, fetch func(ctx context.Context) (T, error)) (T, time.Time, error) {
+	var value T
+	if fetchedAt, ok := c.get(ctx, key, &value); ok {
+		c.hits.Add(1)
+		return value, fetchedAt, nil
+	}
+	c.misses.Add(1)
+
+	result, err, _ := c.group.Do(key, func() (interface{}, error) {
+		if err := c.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer c.release()
+
+		c.upstreamCalls.Add(1)
+		v, err := fetch(ctx)
+		if err != nil {
+			c.errors.Add(1)
+			return nil, err
+		}
+
+		c.set(ctx, key, v)
+		return v, nil
+	})
+	if err != nil {
+		return value, time.Time{}, err
+	}
+
+	return result.(T), time.Time{}, nil
+}
+
+// get decodes a cached value into dest, returning its fetch time and whether it was found
+func (c *CachedBricklinkClient) get(ctx context.Context, key string, dest interface{}) (time.Time, bool) {
+	if c.redis == nil {
+		return time.Time{}, false
+	}
+
+	raw, err := c.redis.Client().Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Warn("BrickLink cache read failed", "key", key, "error", err)
+		}
+		return time.Time{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil || json.Unmarshal(entry.Value, dest) != nil {
+		log.Warn("BrickLink cache entry is corrupt", "key", key)
+		return time.Time{}, false
+	}
+
+	return entry.FetchedAt, true
+}
+
+// set stores a value in the cache, failures are logged and otherwise ignored
+func (c *CachedBricklinkClient) set(ctx context.Context, key string, value interface{}) {
+	if c.redis == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.Warn("BrickLink cache encode failed", "key", key, "error", err)
+		return
+	}
+
+	raw, err := json.Marshal(cacheEntry{FetchedAt: time.Now().UTC(), Value: encoded})
+	if err != nil {
+		log.Warn("BrickLink cache encode failed", "key", key, "error", err)
+		return
+	}
+
+	if err := c.redis.Client().Set(ctx, key, raw, c.ttl).Err(); err != nil {
+		log.Warn("BrickLink cache write failed", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/cache"
+	cacheConfig "LegoManagerAPI/internal/config/cache"
+)
+
+// newTestRedis returns a RedisClient backed by an in-memory miniredis server
+func newTestRedis(t *testing.T) *cache.RedisClient {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	client, err := cache.NewRedisClient(cacheConfig.CacheConfig{Host: mr.Host(), Port: port})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestCachedClient_ServesRepeatCallsFromCache(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001","name":"Battle Droid"}}`))
+	}))
+	defer server.Close()
+
+	client := NewCachedBricklinkClient(newTestService(server.URL), newTestRedis(t), time.Minute, 2)
+
+	for i := 0; i < 3; i++ {
+		info, err := client.Minifig(context.Background(), "sw0001")
+		require.NoError(t, err)
+		assert.Equal(t, "Battle Droid", info.Name)
+	}
+
+	assert.Equal(t, int32(1), hits.Load())
+	stats := client.Stats()
+	assert.Equal(t, int64(2), stats.CacheHits)
+	assert.Equal(t, int64(1), stats.UpstreamCalls)
+}
+
+func TestCachedClient_ConcurrentMissesShareOneCall(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001","name":"Battle Droid"}}`))
+	}))
+	defer server.Close()
+
+	client := NewCachedBricklinkClient(newTestService(server.URL), nil, time.Minute, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Minifig(context.Background(), "sw0001")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), hits.Load(), "concurrent misses for the same key should be deduplicated")
+}
+
+func TestCachedClient_CapsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"meta":{"code":200},"data":{"no":"x","name":"Minifig"}}`))
+	}))
+	defer server.Close()
+
+	client := NewCachedBricklinkClient(newTestService(server.URL), nil, time.Minute, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := client.Minifig(context.Background(), "sw"+strconv.Itoa(i))
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestCachedClient_MarksCachedResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/subsets"):
+			w.Write([]byte(`{"meta":{"code":200},"data":[]}`))
+		case strings.HasSuffix(r.URL.Path, "/price"):
+			w.Write([]byte(`{"meta":{"code":200},"data":{"currency_code":"USD","new_or_used":"N"}}`))
+		default:
+			w.Write([]byte(`{"meta":{"code":200},"data":{"no":"sw0001","name":"Battle Droid"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewCachedBricklinkClient(newTestService(server.URL), newTestRedis(t), time.Minute, 2)
+
+	live, err := client.MinifigComplete(context.Background(), "sw0001", PriceQuery{})
+	require.NoError(t, err)
+	assert.False(t, live.ToStructuredResponse(nil).Metadata.Cached)
+
+	fromCache, err := client.MinifigComplete(context.Background(), "sw0001", PriceQuery{})
+	require.NoError(t, err)
+	metadata := fromCache.ToStructuredResponse(nil).Metadata
+	assert.True(t, metadata.Cached)
+	assert.GreaterOrEqual(t, metadata.AgeSeconds, int64(0))
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+
+	"LegoManagerAPI/internal/models"
+)
+
+// errNoListings explains a missing market price for items nobody currently sells
+var errNoListings = errors.New("no listings on BrickLink")
+
+// PriceLookup fetches the price guide of one item, CachedBricklinkClient.ItemPrice serves it from the cache
+type PriceLookup func(ctx context.Context, itemType ItemType, itemID, condition, currency string) (*MinifigPrice, error)
+
+// PortfolioItemValue is the valuation of one collection entry, amounts that aren't known are left out
+type PortfolioItemValue struct {
+	ID        int64  `json:"id"`
+	ItemType  string `json:"item_type"`
+	ItemID    string `json:"item_id"`
+	Condition string `json:"condition"`
+	Quantity  int    `json:"quantity"`
+
+	// UnitPrice is BrickLink's quantity weighted average price, CurrentValue is it times the quantity
+	UnitPrice    *Money `json:"unit_price,omitempty"`
+	CurrentValue *Money `json:"current_value,omitempty"`
+
+	// PurchaseCost is the purchase price times the quantity
+	PurchaseCost *Money `json:"purchase_cost,omitempty"`
+
+	// GainLoss is CurrentValue minus PurchaseCost, only set when both are known
+	GainLoss *Money `json:"gain_loss,omitempty"`
+}
+
+// PortfolioValue is the valuation of a user's whole collection in one currency
+// Totals only cover the items whose amounts are known, Warnings says which prices are missing
+type PortfolioValue struct {
+	Currency          string `json:"currency"`
+	TotalCurrentValue Money  `json:"total_current_value"`
+	TotalPurchaseCost Money  `json:"total_purchase_cost"`
+
+	// TotalGainLoss sums GainLoss, i.e. only items with both a market price and a purchase price
+	TotalGainLoss Money `json:"total_gain_loss"`
+
+	Items    []PortfolioItemValue `json:"items"`
+	Warnings []string             `json:"warnings"`
+}
+
+// ValuePortfolio values items at their current BrickLink prices in currency, looking prices up concurrently
+// A failed lookup doesn't fail the valuation, the item is left out of the market totals and a warning is added
+// Purchase prices are taken to be in currency too
+func ValuePortfolio(ctx context.Context, items []*models.Collection, currency string, lookup PriceLookup) *PortfolioValue {
+	requests := make([]priceRequest, len(items))
+	for i, item := range items {
+		requests[i] = priceRequest{itemType: ItemType(item.ItemType), itemID: item.ItemID, condition: item.Condition}
+	}
+	prices, errs := lookupPrices(ctx, requests, currency, lookup)
+
+	zero, _ := ParseMoney("", currency)
+	value := &PortfolioValue{
+		Currency:          currency,
+		TotalCurrentValue: zero,
+		TotalPurchaseCost: zero,
+		TotalGainLoss:     zero,
+		Items:             make([]PortfolioItemValue, 0, len(items)),
+		Warnings:          []string{},
+	}
+
+	for i, item := range items {
+		itemValue := PortfolioItemValue{
+			ID:        item.ID,
+			ItemType:  item.ItemType,
+			ItemID:    item.ItemID,
+			Condition: item.Condition,
+			Quantity:  item.Quantity,
+		}
+		label := fmt.Sprintf("%s %s (%s)", item.ItemType, item.ItemID, item.Condition)
+
+		if item.PurchasePrice != nil {
+			unitCost, err := ParseMoney(strconv.FormatFloat(*item.PurchasePrice, 'f', -1, 64), currency)
+			if err == nil {
+				cost := unitCost.Times(item.Quantity)
+				itemValue.PurchaseCost = &cost
+				value.TotalPurchaseCost = SumMoney(value.TotalPurchaseCost, cost)
+			}
+		}
+
+		unitPrice, err := marketUnitPrice(prices[i], errs[i], currency)
+		if err != nil {
+			value.Warnings = append(value.Warnings, fmt.Sprintf("No market price for %s: %v", label, err))
+		} else {
+			current := unitPrice.Times(item.Quantity)
+			itemValue.UnitPrice = &unitPrice
+			itemValue.CurrentValue = &current
+			value.TotalCurrentValue = SumMoney(value.TotalCurrentValue, current)
+
+			if itemValue.PurchaseCost != nil {
+				gainLoss := current.Minus(*itemValue.PurchaseCost)
+				itemValue.GainLoss = &gainLoss
+				value.TotalGainLoss = SumMoney(value.TotalGainLoss, gainLoss)
+			}
+		}
+
+		value.Items = append(value.Items, itemValue)
+	}
+
+	return value
+}
+
+// priceRequest identifies one price guide lookup
+type priceRequest struct {
+	itemType  ItemType
+	itemID    string
+	condition string
+}
+
+// lookupPrices runs lookup for all requests concurrently, results and errors keep the request order
+// Every lookup runs to completion, failures are collected rather than cancelling the others
+func lookupPrices(ctx context.Context, requests []priceRequest, currency string, lookup PriceLookup) ([]*MinifigPrice, []error) {
+	prices := make([]*MinifigPrice, len(requests))
+	errs := make([]error, len(requests))
+
+	g, gCtx := errgroup.WithContext(ctx)
+	for i, request := range requests {
+		g.Go(func() error {
+			prices[i], errs[i] = lookup(gCtx, request.itemType, request.itemID, request.condition, currency)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return prices, errs
+}
+
+// marketUnitPrice returns the weighted average price of a price guide lookup, or why there is none
+// BrickLink reports an average of zero when the item has no listings, that is no usable price either
+func marketUnitPrice(price *MinifigPrice, lookupErr error, currency string) (Money, error) {
+	if lookupErr != nil {
+		return Money{}, lookupErr
+	}
+
+	unitPrice, err := ParseMoney(price.QtyAvgPrice, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if unitPrice.MinorUnits() == 0 {
+		return Money{}, errNoListings
+	}
+	return unitPrice, nil
+}
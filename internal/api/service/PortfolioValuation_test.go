@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestValuePortfolio_PartialTotalsWithWarnings(t *testing.T) {
+	paid := 10.0
+	items := []*models.Collection{
+		{BaseModel: models.BaseModel{ID: 1}, ItemType: "MINIFIG", ItemID: "sw0001", Condition: "N", Quantity: 2, PurchasePrice: &paid},
+		{BaseModel: models.BaseModel{ID: 2}, ItemType: "SET", ItemID: "75192-1", Condition: "U", Quantity: 1},
+		{BaseModel: models.BaseModel{ID: 3}, ItemType: "MINIFIG", ItemID: "sw9999", Condition: "N", Quantity: 1, PurchasePrice: &paid},
+		{BaseModel: models.BaseModel{ID: 4}, ItemType: "MINIFIG", ItemID: "sw0002", Condition: "U", Quantity: 1},
+	}
+
+	lookup := func(ctx context.Context, itemType ItemType, itemID, condition, currency string) (*MinifigPrice, error) {
+		assert.Equal(t, "EUR", currency)
+		switch itemID {
+		case "sw0001":
+			return &MinifigPrice{QtyAvgPrice: "12.5050"}, nil
+		case "75192-1":
+			return &MinifigPrice{QtyAvgPrice: "600"}, nil
+		case "sw0002":
+			return &MinifigPrice{QtyAvgPrice: "0.0000"}, nil
+		}
+		return nil, fmt.Errorf("%w: /items/MINIFIG/%s/price", ErrNotFound, itemID)
+	}
+
+	value := ValuePortfolio(context.Background(), items, "EUR", lookup)
+
+	assert.Equal(t, "625.02", value.TotalCurrentValue.String())
+	assert.Equal(t, "30.00", value.TotalPurchaseCost.String())
+	assert.Equal(t, "5.02", value.TotalGainLoss.String(), "only items with a market and a purchase price count towards gain/loss")
+
+	require.Len(t, value.Items, 4)
+	assert.Equal(t, "12.51", value.Items[0].UnitPrice.String())
+	assert.Equal(t, "5.02", value.Items[0].GainLoss.String())
+	assert.Nil(t, value.Items[1].PurchaseCost)
+	assert.Nil(t, value.Items[1].GainLoss)
+	assert.Nil(t, value.Items[2].CurrentValue)
+	assert.Equal(t, "10.00", value.Items[2].PurchaseCost.String())
+
+	require.Len(t, value.Warnings, 2)
+	assert.Contains(t, value.Warnings[0], "MINIFIG sw9999 (N)")
+	assert.Contains(t, value.Warnings[1], "MINIFIG sw0002 (U)")
+}
+
+func TestValuePortfolio_EmptyCollection(t *testing.T) {
+	value := ValuePortfolio(context.Background(), nil, "USD", nil)
+
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"currency":"USD","total_current_value":0.00,"total_purchase_cost":0.00,"total_gain_loss":0.00,"items":[],"warnings":[]}`, string(data))
+}
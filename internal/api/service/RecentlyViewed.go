@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/redis/go-redis/v9"
+
+	"LegoManagerAPI/internal/cache"
+)
+
+// recordTimeout bounds the Redis write behind Record, which runs after the request has been answered
+const recordTimeout = 2 * time.Second
+
+// RecentlyViewed keeps a capped list per user of the minifigs they looked at, newest first, in Redis
+type RecentlyViewed struct {
+	redis *cache.RedisClient
+	limit int
+}
+
+// NewRecentlyViewed creates a RecentlyViewed keeping up to limit minifigs per user
+// A nil redisClient or a limit below 1 disables it, Record then does nothing and List returns no minifigs
+func NewRecentlyViewed(redisClient *cache.RedisClient, limit int) *RecentlyViewed {
+	return &RecentlyViewed{
+		redis: redisClient,
+		limit: limit,
+	}
+}
+
+// Limit returns how many minifigs are kept per user
+func (r *RecentlyViewed) Limit() int {
+	return r.limit
+}
+
+// Record remembers that userID viewed minifigID without blocking the caller
+// It is best effort, a failed write is logged and otherwise ignored
+func (r *RecentlyViewed) Record(userID int64, minifigID string) {
+	if r.redis == nil || r.limit < 1 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+		defer cancel()
+
+		if err := r.record(ctx, userID, minifigID); err != nil {
+			log.Warn("Failed to record recently viewed minifig", "user_id", userID, "minifig_id", minifigID, "error", err)
+		}
+	}()
+}
+
+// record moves minifigID to the front of the user's list and trims it to the limit
+func (r *RecentlyViewed) record(ctx context.Context, userID int64, minifigID string) error {
+	key := recentlyViewedKey(userID)
+
+	_, err := r.redis.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Viewing a minifig again moves it to the front instead of listing it twice
+		pipe.LRem(ctx, key, 0, minifigID)
+		pipe.LPush(ctx, key, minifigID)
+		pipe.LTrim(ctx, key, 0, int64(r.limit-1))
+		return nil
+	})
+	return err
+}
+
+// List returns the ids of the last n minifigs userID viewed, newest first
+func (r *RecentlyViewed) List(ctx context.Context, userID int64, n int) ([]string, error) {
+	if r.redis == nil || r.limit < 1 || n < 1 {
+		return []string{}, nil
+	}
+
+	ids, err := r.redis.Client().LRange(ctx, recentlyViewedKey(userID), 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recently viewed minifigs: %w", err)
+	}
+	return ids, nil
+}
+
+func recentlyViewedKey(userID int64) string {
+	return fmt.Sprintf("recent:minifig:%d", userID)
+}
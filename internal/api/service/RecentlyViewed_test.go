@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentlyViewed_KeepsNewestFirstWithinLimit(t *testing.T) {
+	recent := NewRecentlyViewed(newTestRedis(t), 3)
+	ctx := context.Background()
+
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, recent.record(ctx, 7, fmt.Sprintf("sw000%d", i)))
+	}
+
+	ids, err := recent.List(ctx, 7, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sw0004", "sw0003", "sw0002"}, ids, "the oldest view is trimmed")
+
+	ids, err = recent.List(ctx, 7, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sw0004", "sw0003"}, ids)
+
+	ids, err = recent.List(ctx, 8, 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids, "lists are per user")
+}
+
+func TestRecentlyViewed_RepeatViewMovesToFront(t *testing.T) {
+	recent := NewRecentlyViewed(newTestRedis(t), 5)
+	ctx := context.Background()
+
+	for _, id := range []string{"sw0001", "sw0002", "sw0001"} {
+		require.NoError(t, recent.record(ctx, 7, id))
+	}
+
+	ids, err := recent.List(ctx, 7, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sw0001", "sw0002"}, ids)
+}
+
+func TestRecentlyViewed_RecordIsAsync(t *testing.T) {
+	recent := NewRecentlyViewed(newTestRedis(t), 5)
+
+	recent.Record(7, "sw0001")
+
+	assert.Eventually(t, func() bool {
+		ids, err := recent.List(context.Background(), 7, 5)
+		return err == nil && len(ids) == 1 && ids[0] == "sw0001"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRecentlyViewed_WithoutRedis(t *testing.T) {
+	recent := NewRecentlyViewed(nil, 5)
+
+	recent.Record(7, "sw0001")
+
+	ids, err := recent.List(context.Background(), 7, 5)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"LegoManagerAPI/internal/models"
+)
+
+// WishlistDeal is the current market price of one wishlist item
+type WishlistDeal struct {
+	ID          int64  `json:"id"`
+	ItemType    string `json:"item_type"`
+	ItemID      string `json:"item_id"`
+	Condition   string `json:"condition"`
+	TargetPrice *Money `json:"target_price,omitempty"`
+
+	// MinPrice is the cheapest current BrickLink listing, left out when there is none or the lookup failed
+	MinPrice *Money `json:"current_min_price,omitempty"`
+
+	// Deal is set when MinPrice is at or below TargetPrice
+	Deal bool `json:"deal"`
+}
+
+// WishlistDeals lists a wishlist with current prices in one currency, Warnings says which lookups failed
+type WishlistDeals struct {
+	Currency string         `json:"currency"`
+	Items    []WishlistDeal `json:"items"`
+	Warnings []string       `json:"warnings"`
+}
+
+// FindDeals looks up the current minimum price of every wishlist item in currency concurrently and flags
+// the items listed at or below their target price. Target prices are taken to be in currency too
+// A failed lookup leaves the item unflagged and adds a warning instead of failing the whole list
+func FindDeals(ctx context.Context, items []*models.Wishlist, currency string, lookup PriceLookup) *WishlistDeals {
+	requests := make([]priceRequest, len(items))
+	for i, item := range items {
+		requests[i] = priceRequest{itemType: ItemType(item.ItemType), itemID: item.ItemID, condition: item.Condition}
+	}
+	prices, errs := lookupPrices(ctx, requests, currency, lookup)
+
+	deals := &WishlistDeals{
+		Currency: currency,
+		Items:    make([]WishlistDeal, 0, len(items)),
+		Warnings: []string{},
+	}
+
+	for i, item := range items {
+		deal := WishlistDeal{
+			ID:        item.ID,
+			ItemType:  item.ItemType,
+			ItemID:    item.ItemID,
+			Condition: item.Condition,
+		}
+
+		if item.TargetPrice != nil {
+			target, err := ParseMoney(strconv.FormatFloat(*item.TargetPrice, 'f', -1, 64), currency)
+			if err == nil {
+				deal.TargetPrice = &target
+			}
+		}
+
+		if errs[i] != nil {
+			deals.Warnings = append(deals.Warnings, fmt.Sprintf("No market price for %s %s (%s): %v", item.ItemType, item.ItemID, item.Condition, errs[i]))
+			deals.Items = append(deals.Items, deal)
+			continue
+		}
+
+		// BrickLink reports a minimum of zero when nobody currently sells the item
+		minPrice, err := ParseMoney(prices[i].MinPrice, currency)
+		if err == nil && minPrice.MinorUnits() > 0 {
+			deal.MinPrice = &minPrice
+			deal.Deal = deal.TargetPrice != nil && minPrice.MinorUnits() <= deal.TargetPrice.MinorUnits()
+		}
+
+		deals.Items = append(deals.Items, deal)
+	}
+
+	return deals
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestFindDeals(t *testing.T) {
+	target := 10.0
+	items := []*models.Wishlist{
+		{BaseModel: models.BaseModel{ID: 1}, ItemType: "MINIFIG", ItemID: "sw0001", Condition: "N", TargetPrice: &target},
+		{BaseModel: models.BaseModel{ID: 2}, ItemType: "MINIFIG", ItemID: "sw0002", Condition: "N", TargetPrice: &target},
+		{BaseModel: models.BaseModel{ID: 3}, ItemType: "SET", ItemID: "75192-1", Condition: "U"},
+		{BaseModel: models.BaseModel{ID: 4}, ItemType: "MINIFIG", ItemID: "sw0003", Condition: "N", TargetPrice: &target},
+		{BaseModel: models.BaseModel{ID: 5}, ItemType: "MINIFIG", ItemID: "sw9999", Condition: "N", TargetPrice: &target},
+	}
+
+	minPrices := map[string]string{"sw0001": "10.0000", "sw0002": "10.0100", "75192-1": "550", "sw0003": "0.0000"}
+	lookup := func(ctx context.Context, itemType ItemType, itemID, condition, currency string) (*MinifigPrice, error) {
+		if minPrice, ok := minPrices[itemID]; ok {
+			return &MinifigPrice{MinPrice: minPrice}, nil
+		}
+		return nil, fmt.Errorf("%w: /items/MINIFIG/%s/price", ErrNotFound, itemID)
+	}
+
+	deals := FindDeals(context.Background(), items, "USD", lookup)
+	require.Len(t, deals.Items, 5)
+
+	assert.True(t, deals.Items[0].Deal, "a price equal to the target is a deal")
+	assert.False(t, deals.Items[1].Deal)
+	assert.Equal(t, "10.01", deals.Items[1].MinPrice.String())
+	assert.False(t, deals.Items[2].Deal, "items without a target are only tracked")
+	assert.Equal(t, "550.00", deals.Items[2].MinPrice.String())
+	assert.Nil(t, deals.Items[3].MinPrice, "no listings means no current price")
+	assert.False(t, deals.Items[4].Deal)
+
+	require.Len(t, deals.Warnings, 1)
+	assert.Contains(t, deals.Warnings[0], "MINIFIG sw9999 (N)")
+}
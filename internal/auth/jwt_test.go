@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/clock"
+	authConfig "LegoManagerAPI/internal/config/auth"
+)
+
+func TestTokenManager_RoundTrip(t *testing.T) {
+	m := NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour})
+
+	token, expiresAt, err := m.Issue(42)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+	claims, err := m.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), claims.UserID)
+	assert.False(t, claims.Impersonated())
+}
+
+func TestTokenManager_Impersonation(t *testing.T) {
+	m := NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour, ImpersonationTTL: 15 * time.Minute})
+
+	token, expiresAt, err := m.IssueImpersonation(42, 1)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, time.Minute)
+
+	claims, err := m.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, Claims{UserID: 42, ImpersonatedBy: 1}, claims)
+	assert.True(t, claims.Impersonated())
+}
+
+func TestTokenManager_RejectsBadTokens(t *testing.T) {
+	m := NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour})
+
+	t.Run("other secret", func(t *testing.T) {
+		other := NewTokenManager(authConfig.AuthConfig{JWTSecret: "other", TokenTTL: time.Hour})
+		token, _, err := other.Issue(42)
+		require.NoError(t, err)
+
+		_, err = m.Parse(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+		expiring := NewTokenManager(authConfig.AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour})
+		expiring.clock = fake
+
+		token, _, err := expiring.Issue(42)
+		require.NoError(t, err)
+
+		fake.Advance(59 * time.Minute)
+		_, err = expiring.Parse(token)
+		require.NoError(t, err, "the token is still valid just before expiry")
+
+		fake.Advance(2 * time.Minute)
+		_, err = expiring.Parse(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		_, err := m.Parse("not-a-token")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"LegoManagerAPI/internal/cache"
+	"LegoManagerAPI/internal/clock"
+)
+
+var (
+	// ErrStaleRequest is returned for signed requests whose timestamp is outside the accepted window
+	ErrStaleRequest = errors.New("request timestamp outside the accepted window")
+
+	// ErrReplayedRequest is returned when a signed request's nonce was already used within the window
+	ErrReplayedRequest = errors.New("request nonce already used")
+)
+
+// ReplayGuard rejects signed requests that are too old or were seen before, the same
+// nonce+timestamp scheme BrickLink's OAuth uses. It runs after the signature check,
+// so the timestamp and nonce can be trusted to come from the signer
+type ReplayGuard struct {
+	redis  *cache.RedisClient
+	maxAge time.Duration
+	clock  clock.Clock
+}
+
+// NewReplayGuard creates a ReplayGuard accepting timestamps up to maxAge away from now in
+// either direction, tolerating clock skew between us and the signer
+func NewReplayGuard(redisClient *cache.RedisClient, maxAge time.Duration) *ReplayGuard {
+	return &ReplayGuard{
+		redis:  redisClient,
+		maxAge: maxAge,
+		clock:  clock.Real(),
+	}
+}
+
+// Check accepts a request signed at timestamp with nonce exactly once
+// Nonces are remembered in Redis until their timestamp leaves the window, after which the
+// timestamp check alone rejects them. Redis errors are returned rather than letting the request through
+func (g *ReplayGuard) Check(ctx context.Context, timestamp time.Time, nonce string) error {
+	if nonce == "" {
+		return fmt.Errorf("%w: missing nonce", ErrReplayedRequest)
+	}
+
+	now := g.clock.Now()
+	age := now.Sub(timestamp)
+	if age > g.maxAge || age < -g.maxAge {
+		return fmt.Errorf("%w: timestamp is %s away from now", ErrStaleRequest, age.Round(time.Second))
+	}
+
+	ttl := timestamp.Add(g.maxAge).Sub(now)
+	if ttl <= 0 {
+		ttl = time.Millisecond
+	}
+
+	fresh, err := g.redis.Client().SetNX(ctx, "auth:nonce:"+nonce, 1, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record request nonce: %w", err)
+	}
+	if !fresh {
+		return ErrReplayedRequest
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/cache"
+	"LegoManagerAPI/internal/clock"
+	cacheConfig "LegoManagerAPI/internal/config/cache"
+)
+
+func newTestReplayGuard(t *testing.T, maxAge time.Duration) (*ReplayGuard, *clock.Fake, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	client, err := cache.NewRedisClient(cacheConfig.CacheConfig{Host: mr.Host(), Port: port})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	fake := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	g := NewReplayGuard(client, maxAge)
+	g.clock = fake
+
+	return g, fake, mr
+}
+
+func TestReplayGuard_StaleTimestamp(t *testing.T) {
+	g, fake, _ := newTestReplayGuard(t, 5*time.Minute)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, g.Check(ctx, fake.Now().Add(-6*time.Minute), "old"), ErrStaleRequest)
+	assert.ErrorIs(t, g.Check(ctx, fake.Now().Add(6*time.Minute), "future"), ErrStaleRequest)
+	assert.NoError(t, g.Check(ctx, fake.Now().Add(-4*time.Minute), "skewed-behind"))
+	assert.NoError(t, g.Check(ctx, fake.Now().Add(4*time.Minute), "skewed-ahead"))
+}
+
+func TestReplayGuard_ReplayedNonce(t *testing.T) {
+	g, fake, mr := newTestReplayGuard(t, 5*time.Minute)
+	ctx := context.Background()
+	signedAt := fake.Now()
+
+	require.NoError(t, g.Check(ctx, signedAt, "abc"))
+	assert.ErrorIs(t, g.Check(ctx, signedAt, "abc"), ErrReplayedRequest)
+	assert.NoError(t, g.Check(ctx, signedAt, "def"), "other nonces are unaffected")
+
+	// Once the nonce expires the timestamp has left the window, so the capture stays useless
+	fake.Advance(5*time.Minute + time.Second)
+	mr.FastForward(5*time.Minute + time.Second)
+	assert.ErrorIs(t, g.Check(ctx, signedAt, "abc"), ErrStaleRequest)
+}
+
+func TestReplayGuard_MissingNonce(t *testing.T) {
+	g, fake, _ := newTestReplayGuard(t, 5*time.Minute)
+	assert.ErrorIs(t, g.Check(context.Background(), fake.Now(), ""), ErrReplayedRequest)
+}
+
+func TestReplayGuard_RedisUnavailable(t *testing.T) {
+	g, fake, mr := newTestReplayGuard(t, 5*time.Minute)
+	mr.Close()
+
+	err := g.Check(context.Background(), fake.Now(), "abc")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrReplayedRequest)
+}
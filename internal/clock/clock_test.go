@@ -0,0 +1,24 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+	assert.Equal(t, start, c.Now())
+
+	c.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
+
+func TestReal(t *testing.T) {
+	assert.WithinDuration(t, time.Now(), Real().Now(), time.Second)
+}
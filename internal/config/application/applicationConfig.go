@@ -1,9 +1,13 @@
 package application
 
 import (
+	"net"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"golang.org/x/crypto/bcrypt"
 
 	"LegoManagerAPI/internal/config/configUtilities"
 )
@@ -14,16 +18,123 @@ type ApplicationConfig struct {
 	ApplicationName string
 	LogLVL          string
 	Environment     string
+	BcryptCost      int
+	AllowPrettyJSON bool
+
+	// ExposeEnvironment adds an X-Environment header to every response
+	ExposeEnvironment bool
+
+	// MaxInFlightRequests caps concurrently served requests, excess requests get a 503, 0 disables the cap
+	MaxInFlightRequests int
+
+	// RequestBudget caps the total time any request may take, a request over it gets a 503, 0 disables it
+	// Keep it below the server's 15s write timeout, otherwise the connection is cut before the 503 is sent
+	RequestBudget time.Duration
+
+	// AllowedOrigins are the browser origins allowed to call the API through CORS, "*" allows any, empty disables CORS
+	AllowedOrigins []string
+
+	// CORSAllowCredentials lets browsers send cookies and auth headers on cross-origin requests
+	CORSAllowCredentials bool
+
+	// HealthToken and HealthAllowedNetworks restrict /health, /health/ready and /health/detailed to callers sending
+	// the token in X-Health-Token or connecting from one of the networks. With neither set they stay open,
+	// /health/live is always open
+	HealthToken           string
+	HealthAllowedNetworks []*net.IPNet
+
+	// HealthCacheTTL is how long a /health result is reused before the dependencies are checked again, 0 disables it
+	HealthCacheTTL time.Duration
+
+	// HealthCheckTimeout bounds each individual health check, a slower one is reported as timed out, 0 disables it
+	HealthCheckTimeout time.Duration
+
+	// PortfolioSnapshotInterval is how often every user's portfolio value is stored for the value history, 0 disables it
+	PortfolioSnapshotInterval time.Duration
+
+	// PortfolioSnapshotQuotaReserve is how many BrickLink calls a day the snapshot job leaves for interactive requests
+	PortfolioSnapshotQuotaReserve int
 }
 
 // LoadApplicationConfig initializes and returns an ApplicationConfig struct populated with values from environment variables.
 func LoadApplicationConfig() ApplicationConfig {
+	environment := configUtilities.GetEnvAsString("APP_ENV", "development")
+
 	return ApplicationConfig{
 		Port:            configUtilities.GetEnvAsInt("PORT", 8080),
 		ApplicationName: configUtilities.GetEnvAsString("APP_NAME", "Lego Manager API"),
 		LogLVL:          configUtilities.GetEnvAsString("LOG_LEVEL", "info"),
-		Environment:     configUtilities.GetEnvAsString("APP_ENV", "development"),
+		Environment:     environment,
+		BcryptCost:      loadBcryptCost(environment),
+		AllowPrettyJSON: configUtilities.GetEnvAsBool("ALLOW_PRETTY_JSON", environment != "production"),
+
+		ExposeEnvironment: configUtilities.GetEnvAsBool("EXPOSE_ENVIRONMENT_HEADER", environment != "production"),
+
+		MaxInFlightRequests: configUtilities.GetEnvAsInt("MAX_IN_FLIGHT_REQUESTS", 500),
+		RequestBudget:       configUtilities.GetEnvAsDuration("REQUEST_BUDGET", 12*time.Second),
+
+		AllowedOrigins:       normalizeOrigins(configUtilities.GetEnvAsStringSlice("ALLOWED_ORIGINS", []string{})),
+		CORSAllowCredentials: configUtilities.GetEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
+
+		HealthToken:           configUtilities.GetEnvAsString("HEALTH_TOKEN", ""),
+		HealthAllowedNetworks: parseNetworks(configUtilities.GetEnvAsStringSlice("HEALTH_ALLOWED_CIDRS", []string{})),
+		HealthCacheTTL:        configUtilities.GetEnvAsDuration("HEALTH_CACHE_TTL", 5*time.Second),
+		HealthCheckTimeout:    configUtilities.GetEnvAsDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+
+		PortfolioSnapshotInterval:     configUtilities.GetEnvAsDuration("PORTFOLIO_SNAPSHOT_INTERVAL", 24*time.Hour),
+		PortfolioSnapshotQuotaReserve: configUtilities.GetEnvAsInt("PORTFOLIO_SNAPSHOT_QUOTA_RESERVE", 2500),
+	}
+}
+
+// parseNetworks converts HEALTH_ALLOWED_CIDRS entries to networks, a plain IP allows just that address
+// Invalid entries are skipped with a warning
+func parseNetworks(values []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			log.Warn("Ignoring invalid health CIDR", "value", value)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// normalizeOrigins drops trailing slashes from ALLOWED_ORIGINS entries, browsers send the Origin header without one
+func normalizeOrigins(origins []string) []string {
+	normalized := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		if origin = strings.TrimRight(origin, "/"); origin != "" {
+			normalized = append(normalized, origin)
+		}
+	}
+	return normalized
+}
+
+// loadBcryptCost reads BCRYPT_COST within bcrypt's supported range.
+// The test environment always uses the minimum cost to keep tests fast.
+func loadBcryptCost(environment string) int {
+	if environment == "test" {
+		return bcrypt.MinCost
 	}
+
+	cost := configUtilities.GetEnvAsInt("BCRYPT_COST", bcrypt.DefaultCost)
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		log.Warn("BCRYPT_COST is out of range (" + strconv.Itoa(bcrypt.MinCost) + "-" + strconv.Itoa(bcrypt.MaxCost) + "). Using default value: " + strconv.Itoa(bcrypt.DefaultCost))
+		return bcrypt.DefaultCost
+	}
+
+	return cost
 }
 
 // SetupLogger sets the global log level according to the application's configuration.
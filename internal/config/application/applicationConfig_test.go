@@ -0,0 +1,40 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoadBcryptCost(t *testing.T) {
+	t.Run("uses configured cost", func(t *testing.T) {
+		t.Setenv("BCRYPT_COST", "12")
+		assert.Equal(t, 12, loadBcryptCost("production"))
+	})
+
+	t.Run("falls back to default when out of range", func(t *testing.T) {
+		t.Setenv("BCRYPT_COST", "99")
+		assert.Equal(t, bcrypt.DefaultCost, loadBcryptCost("production"))
+	})
+
+	t.Run("uses minimum cost in test environment", func(t *testing.T) {
+		t.Setenv("BCRYPT_COST", "12")
+		assert.Equal(t, bcrypt.MinCost, loadBcryptCost("test"))
+	})
+}
+
+func TestLoadApplicationConfig_AllowedOrigins(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "https://portfolio.example.com/, http://localhost:5173")
+	assert.Equal(t, []string{"https://portfolio.example.com", "http://localhost:5173"}, LoadApplicationConfig().AllowedOrigins)
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks := parseNetworks([]string{"10.0.0.0/8", "192.168.1.5", "::1", "not-a-cidr"})
+
+	require.Len(t, networks, 3, "invalid entries are skipped")
+	assert.Equal(t, "10.0.0.0/8", networks[0].String())
+	assert.Equal(t, "192.168.1.5/32", networks[1].String())
+	assert.Equal(t, "::1/128", networks[2].String())
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"LegoManagerAPI/internal/config/configUtilities"
+)
+
+// defaultJWTSecret is the development placeholder used when JWT_SECRET is unset
+// It is public, so Validate only accepts it in the environments listed in placeholderSecretEnvironments
+const defaultJWTSecret = "jwt_secret"
+
+// placeholderSecretEnvironments are the environments allowed to run with defaultJWTSecret
+var placeholderSecretEnvironments = []string{"development", "test"}
+
+// AuthConfig holds the settings used to issue and verify login tokens
+type AuthConfig struct {
+	JWTSecret string
+
+	// TokenTTL controls how long an issued token stays valid
+	TokenTTL time.Duration
+
+	// ImpersonationTTL controls how long a support impersonation token stays valid, kept short on purpose
+	ImpersonationTTL time.Duration
+
+	// SignedRequestMaxAge is how far a signed request's timestamp may be from now before it is rejected,
+	// nonces are remembered for as long
+	SignedRequestMaxAge time.Duration
+
+	// AdminUserIDs are the users allowed to call the admin endpoints
+	AdminUserIDs []int64
+}
+
+// LoadAuthConfig initializes and returns an AuthConfig struct populated with values from environment variables.
+func LoadAuthConfig() AuthConfig {
+	return AuthConfig{
+		JWTSecret:           configUtilities.GetEnvAsString("JWT_SECRET", defaultJWTSecret),
+		TokenTTL:            configUtilities.GetEnvAsDuration("JWT_TOKEN_TTL", 24*time.Hour),
+		ImpersonationTTL:    configUtilities.GetEnvAsDuration("JWT_IMPERSONATION_TTL", 15*time.Minute),
+		SignedRequestMaxAge: configUtilities.GetEnvAsDuration("SIGNED_REQUEST_MAX_AGE", 5*time.Minute),
+		AdminUserIDs:        parseUserIDs(configUtilities.GetEnvAsStringSlice("ADMIN_USER_IDS", []string{})),
+	}
+}
+
+// parseUserIDs converts ADMIN_USER_IDS entries to ids, skipping anything that isn't a number
+func parseUserIDs(values []string) []int64 {
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Warn("Ignoring invalid admin user id", "value", value)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Validate rejects the placeholder secret outside development and test, e.g. in staging, since anyone
+// reading the repository could forge tokens signed with it, admin ones included
+func (c AuthConfig) Validate(environment string) error {
+	if c.JWTSecret == defaultJWTSecret && !slices.Contains(placeholderSecretEnvironments, environment) {
+		return errors.New("JWT_SECRET must be set outside development and test")
+	}
+	if c.TokenTTL <= 0 {
+		return errors.New("JWT_TOKEN_TTL must be positive")
+	}
+	if c.ImpersonationTTL <= 0 {
+		return errors.New("JWT_IMPERSONATION_TTL must be positive")
+	}
+	if c.SignedRequestMaxAge <= 0 {
+		return errors.New("SIGNED_REQUEST_MAX_AGE must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_PlaceholderSecretOnlyInDevelopmentAndTest(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	cfg := LoadAuthConfig()
+	assert.Equal(t, defaultJWTSecret, cfg.JWTSecret)
+
+	assert.NoError(t, cfg.Validate("development"))
+	assert.NoError(t, cfg.Validate("test"))
+	for _, environment := range []string{"staging", "production", ""} {
+		assert.Error(t, cfg.Validate(environment), environment)
+	}
+
+	cfg.JWTSecret = "a-real-secret"
+	assert.NoError(t, cfg.Validate("staging"))
+}
+
+func TestValidate_RequiresPositiveDurations(t *testing.T) {
+	cfg := AuthConfig{JWTSecret: "secret", TokenTTL: time.Hour, ImpersonationTTL: time.Minute, SignedRequestMaxAge: time.Minute}
+	assert.NoError(t, cfg.Validate("production"))
+
+	cfg.TokenTTL = 0
+	assert.ErrorContains(t, cfg.Validate("production"), "JWT_TOKEN_TTL")
+}
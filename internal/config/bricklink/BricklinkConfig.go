@@ -1,9 +1,17 @@
 package bricklink
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"LegoManagerAPI/internal/config/configUtilities"
 )
 
+// ItemTypes are the BrickLink catalog item types the integration knows how to look up
+var ItemTypes = []string{"MINIFIG", "SET", "PART", "GEAR", "BOOK", "CATALOG", "INSTRUCTION", "ORIGINAL_BOX"}
+
 // BricklinkConfig hold the Bricklink API credentials
 type BricklinkConfig struct {
 	SignatureMethod   string
@@ -11,15 +19,153 @@ type BricklinkConfig struct {
 	ConsumerSecret    string
 	AccessToken       string
 	AccessTokenSecret string
+
+	// Required makes missing credentials a startup error, otherwise the API runs without BrickLink
+	Required bool
+
+	// CatalogRefreshInterval controls how long the in-memory color/category catalog is kept before reloading
+	CatalogRefreshInterval time.Duration
+
+	// NotFoundCacheTTL controls how long ids BrickLink reported as missing are remembered
+	NotFoundCacheTTL time.Duration
+
+	// CacheTTL controls how long minifig info, subsets and prices are cached in Redis
+	CacheTTL time.Duration
+
+	// RecentlyViewedLimit caps how many recently viewed minifigs are remembered per user, 0 disables it
+	RecentlyViewedLimit int
+
+	// SlowFetchThreshold escalates the item fetch log to a warning when the whole fetch or any endpoint takes longer, 0 disables it
+	SlowFetchThreshold time.Duration
+
+	// MaxConcurrency caps the number of BrickLink calls in flight at once
+	MaxConcurrency int
+
+	// RateLimitPerSecond and RateLimitPerDay cap outbound BrickLink calls, 0 disables a limit
+	RateLimitPerSecond int
+	RateLimitPerDay    int
+
+	// RateLimitBackend is "memory" for per-instance limits or "redis" to share them across instances
+	RateLimitBackend string
+
+	// DisabledEndpoints lists lookups ("info", "subsets", "price") that are skipped to conserve API quota
+	DisabledEndpoints []string
+
+	// EnabledItemTypes lists the item types clients may look up, a subset of ItemTypes
+	EnabledItemTypes []string
+
+	// Endpoint path templates relative to the API base URL, each with a single %s for the item id
+	// Overridable so a relocated BrickLink endpoint can be patched without a release
+	MinifigInfoPath    string
+	MinifigSubsetsPath string
+	MinifigPricePath   string
+	SetInfoPath        string
+	SetSubsetsPath     string
+	SetPricePath       string
 }
 
 // LoadBricklinkConifg initializes and returns a BricklinkConfig struct populated with values from env vars.
 func LoadBricklinkConifg() BricklinkConfig {
 	return BricklinkConfig{
 		SignatureMethod:   "HMAC-SHA1",
-		ConsumerSecret:    configUtilities.GetEnvAsString("BRICKLINK_CONSUMER_SECRET", "consumer_secret"),
-		ConsumerKey:       configUtilities.GetEnvAsString("BRICKLINK_CONSUMER_KEY", "consumer_key"),
-		AccessToken:       configUtilities.GetEnvAsString("BRICKLINK_ACCESS_TOKEN", "access_token"),
-		AccessTokenSecret: configUtilities.GetEnvAsString("BRICKLINK_ACCESS_TOKEN_SECRET", "access_token_secret"),
+		ConsumerSecret:    configUtilities.GetEnvAsString("BRICKLINK_CONSUMER_SECRET", placeholderCredentials["BRICKLINK_CONSUMER_SECRET"]),
+		ConsumerKey:       configUtilities.GetEnvAsString("BRICKLINK_CONSUMER_KEY", placeholderCredentials["BRICKLINK_CONSUMER_KEY"]),
+		AccessToken:       configUtilities.GetEnvAsString("BRICKLINK_ACCESS_TOKEN", placeholderCredentials["BRICKLINK_ACCESS_TOKEN"]),
+		AccessTokenSecret: configUtilities.GetEnvAsString("BRICKLINK_ACCESS_TOKEN_SECRET", placeholderCredentials["BRICKLINK_ACCESS_TOKEN_SECRET"]),
+		Required:          configUtilities.GetEnvAsBool("BRICKLINK_REQUIRED", false),
+
+		CatalogRefreshInterval: configUtilities.GetEnvAsDuration("BRICKLINK_CATALOG_REFRESH_INTERVAL", 24*time.Hour),
+		NotFoundCacheTTL:       configUtilities.GetEnvAsDuration("BRICKLINK_NOT_FOUND_TTL", 5*time.Minute),
+		CacheTTL:               configUtilities.GetEnvAsDuration("BRICKLINK_CACHE_TTL", time.Hour),
+		SlowFetchThreshold:     configUtilities.GetEnvAsDuration("BRICKLINK_SLOW_FETCH_THRESHOLD", 5*time.Second),
+		RecentlyViewedLimit:    configUtilities.GetEnvAsInt("BRICKLINK_RECENTLY_VIEWED_LIMIT", 20),
+		MaxConcurrency:         configUtilities.GetEnvAsInt("BRICKLINK_MAX_CONCURRENCY", 5),
+		RateLimitPerSecond:     configUtilities.GetEnvAsInt("BRICKLINK_RATE_LIMIT_PER_SECOND", 5),
+		RateLimitPerDay:        configUtilities.GetEnvAsInt("BRICKLINK_RATE_LIMIT_PER_DAY", 5000),
+		RateLimitBackend:       configUtilities.GetEnvAsString("BRICKLINK_RATE_LIMIT_BACKEND", "memory"),
+		DisabledEndpoints:      configUtilities.GetEnvAsStringSlice("BRICKLINK_DISABLED_ENDPOINTS", []string{}),
+		EnabledItemTypes:       configUtilities.GetEnvAsStringSlice("BRICKLINK_ITEM_TYPES", []string{"MINIFIG", "SET"}),
+
+		MinifigInfoPath:    configUtilities.GetEnvAsString("BRICKLINK_MINIFIG_INFO_PATH", "/items/MINIFIG/%s"),
+		MinifigSubsetsPath: configUtilities.GetEnvAsString("BRICKLINK_MINIFIG_SUBSETS_PATH", "/items/MINIFIG/%s/subsets"),
+		MinifigPricePath:   configUtilities.GetEnvAsString("BRICKLINK_MINIFIG_PRICE_PATH", "/items/MINIFIG/%s/price"),
+		SetInfoPath:        configUtilities.GetEnvAsString("BRICKLINK_SET_INFO_PATH", "/items/SET/%s"),
+		SetSubsetsPath:     configUtilities.GetEnvAsString("BRICKLINK_SET_SUBSETS_PATH", "/items/SET/%s/subsets"),
+		SetPricePath:       configUtilities.GetEnvAsString("BRICKLINK_SET_PRICE_PATH", "/items/SET/%s/price"),
 	}
 }
+
+// placeholderCredentials are the defaults used when a credential is not set, they are never valid
+var placeholderCredentials = map[string]string{
+	"BRICKLINK_CONSUMER_KEY":        "consumer_key",
+	"BRICKLINK_CONSUMER_SECRET":     "consumer_secret",
+	"BRICKLINK_ACCESS_TOKEN":        "access_token",
+	"BRICKLINK_ACCESS_TOKEN_SECRET": "access_token_secret",
+}
+
+// MissingCredentials returns the env vars of the credentials that are empty or still the placeholder default
+func (c BricklinkConfig) MissingCredentials() []string {
+	credentials := []struct {
+		env   string
+		value string
+	}{
+		{"BRICKLINK_CONSUMER_KEY", c.ConsumerKey},
+		{"BRICKLINK_CONSUMER_SECRET", c.ConsumerSecret},
+		{"BRICKLINK_ACCESS_TOKEN", c.AccessToken},
+		{"BRICKLINK_ACCESS_TOKEN_SECRET", c.AccessTokenSecret},
+	}
+
+	var missing []string
+	for _, credential := range credentials {
+		if credential.value == "" || credential.value == placeholderCredentials[credential.env] {
+			missing = append(missing, credential.env)
+		}
+	}
+	return missing
+}
+
+// Configured reports whether all BrickLink credentials are set
+func (c BricklinkConfig) Configured() bool {
+	return len(c.MissingCredentials()) == 0
+}
+
+// Validate checks the rate limit backend, the enabled item types and that the endpoint path templates are usable
+// When BrickLink is required it also checks that every credential is set
+func (c BricklinkConfig) Validate() error {
+	if missing := c.MissingCredentials(); c.Required && len(missing) > 0 {
+		return fmt.Errorf("BRICKLINK_REQUIRED is set but %s are not configured", strings.Join(missing, ", "))
+	}
+
+	if c.RateLimitBackend != "memory" && c.RateLimitBackend != "redis" {
+		return fmt.Errorf("BRICKLINK_RATE_LIMIT_BACKEND must be memory or redis, got %q", c.RateLimitBackend)
+	}
+
+	for _, itemType := range c.EnabledItemTypes {
+		if !slices.Contains(ItemTypes, itemType) {
+			return fmt.Errorf("BRICKLINK_ITEM_TYPES must only contain %s, got %q", strings.Join(ItemTypes, ", "), itemType)
+		}
+	}
+
+	paths := []struct {
+		env      string
+		template string
+	}{
+		{"BRICKLINK_MINIFIG_INFO_PATH", c.MinifigInfoPath},
+		{"BRICKLINK_MINIFIG_SUBSETS_PATH", c.MinifigSubsetsPath},
+		{"BRICKLINK_MINIFIG_PRICE_PATH", c.MinifigPricePath},
+		{"BRICKLINK_SET_INFO_PATH", c.SetInfoPath},
+		{"BRICKLINK_SET_SUBSETS_PATH", c.SetSubsetsPath},
+		{"BRICKLINK_SET_PRICE_PATH", c.SetPricePath},
+	}
+
+	for _, path := range paths {
+		if !strings.HasPrefix(path.template, "/") {
+			return fmt.Errorf("%s must start with a slash, got %q", path.env, path.template)
+		}
+		if strings.Count(path.template, "%s") != 1 || strings.Count(path.template, "%") != 1 {
+			return fmt.Errorf("%s must contain exactly one %%s placeholder, got %q", path.env, path.template)
+		}
+	}
+
+	return nil
+}
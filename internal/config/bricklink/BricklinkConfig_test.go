@@ -0,0 +1,77 @@
+package bricklink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	valid := BricklinkConfig{
+		MinifigInfoPath:    "/items/MINIFIG/%s",
+		MinifigSubsetsPath: "/items/MINIFIG/%s/subsets",
+		MinifigPricePath:   "/items/MINIFIG/%s/price",
+		SetInfoPath:        "/items/SET/%s",
+		SetSubsetsPath:     "/items/SET/%s/subsets",
+		SetPricePath:       "/items/SET/%s/price",
+		RateLimitBackend:   "memory",
+		EnabledItemTypes:   []string{"MINIFIG", "SET"},
+	}
+
+	t.Run("accepts default paths", func(t *testing.T) {
+		assert.NoError(t, valid.Validate())
+	})
+
+	t.Run("rejects missing placeholder", func(t *testing.T) {
+		cfg := valid
+		cfg.MinifigPricePath = "/items/MINIFIG/price"
+		assert.ErrorContains(t, cfg.Validate(), "BRICKLINK_MINIFIG_PRICE_PATH")
+	})
+
+	t.Run("rejects extra format verbs", func(t *testing.T) {
+		cfg := valid
+		cfg.MinifigInfoPath = "/items/%s/%d"
+		assert.ErrorContains(t, cfg.Validate(), "BRICKLINK_MINIFIG_INFO_PATH")
+	})
+
+	t.Run("rejects unknown rate limit backend", func(t *testing.T) {
+		cfg := valid
+		cfg.RateLimitBackend = "memcached"
+		assert.ErrorContains(t, cfg.Validate(), "BRICKLINK_RATE_LIMIT_BACKEND")
+	})
+
+	t.Run("rejects missing credentials when required", func(t *testing.T) {
+		cfg := valid
+		cfg.Required = true
+		assert.ErrorContains(t, cfg.Validate(), "BRICKLINK_CONSUMER_KEY")
+	})
+
+	t.Run("rejects relative path", func(t *testing.T) {
+		cfg := valid
+		cfg.MinifigSubsetsPath = "items/MINIFIG/%s/subsets"
+		assert.ErrorContains(t, cfg.Validate(), "BRICKLINK_MINIFIG_SUBSETS_PATH")
+	})
+
+	t.Run("rejects unknown item type", func(t *testing.T) {
+		cfg := valid
+		cfg.EnabledItemTypes = []string{"MINIFIG", "SPACESHIP"}
+		assert.ErrorContains(t, cfg.Validate(), "BRICKLINK_ITEM_TYPES")
+	})
+}
+
+func TestMissingCredentials(t *testing.T) {
+	cfg := BricklinkConfig{
+		ConsumerKey:       "key",
+		ConsumerSecret:    "consumer_secret",
+		AccessToken:       "token",
+		AccessTokenSecret: "",
+	}
+
+	assert.Equal(t, []string{"BRICKLINK_CONSUMER_SECRET", "BRICKLINK_ACCESS_TOKEN_SECRET"}, cfg.MissingCredentials())
+	assert.False(t, cfg.Configured())
+
+	cfg.ConsumerSecret = "secret"
+	cfg.AccessTokenSecret = "token_secret"
+	assert.Empty(t, cfg.MissingCredentials())
+	assert.True(t, cfg.Configured())
+}
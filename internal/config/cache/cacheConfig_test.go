@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCacheConfig_ReadsEnvironment(t *testing.T) {
+	t.Setenv("REDIS_HOST", "redis.internal")
+	t.Setenv("REDIS_PORT", "6380")
+	t.Setenv("REDIS_PASSWORD", "hunter2")
+	t.Setenv("REDIS_DB", "3")
+
+	assert.Equal(t, CacheConfig{
+		Host:     "redis.internal",
+		Port:     6380,
+		Password: "hunter2",
+		DB:       3,
+	}, LoadCacheConfig())
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
+
 	"LegoManagerAPI/internal/config/application"
+	"LegoManagerAPI/internal/config/auth"
 	"LegoManagerAPI/internal/config/bricklink"
 	"LegoManagerAPI/internal/config/cache"
 	"LegoManagerAPI/internal/config/database"
@@ -13,6 +16,7 @@ type Config struct {
 	Cache     cache.CacheConfig
 	App       application.ApplicationConfig
 	Bricklink bricklink.BricklinkConfig
+	Auth      auth.AuthConfig
 }
 
 // Load creates and populates Config from env vars
@@ -22,6 +26,15 @@ func Load() (*Config, error) {
 		Cache:     cache.LoadCacheConfig(),
 		App:       application.LoadApplicationConfig(),
 		Bricklink: bricklink.LoadBricklinkConifg(),
+		Auth:      auth.LoadAuthConfig(),
+	}
+
+	if err := cfg.Bricklink.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bricklink config: %w", err)
+	}
+
+	if err := cfg.Auth.Validate(cfg.App.Environment); err != nil {
+		return nil, fmt.Errorf("invalid auth config: %w", err)
 	}
 
 	return cfg, nil
@@ -3,6 +3,8 @@ package configUtilities
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
@@ -36,3 +38,55 @@ func GetEnvAsString(key string, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// GetEnvAsDuration retrieves the environment variable value by key and parses it as a time.Duration (e.g. "30s", "24h"), returning the defaultValue if unset or invalid.
+func GetEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+
+	if valueStr == "" {
+		log.Warn("Environment variable " + key + " is not set. Using default value: " + defaultValue.String())
+		return defaultValue
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		log.Warn("Environment variable " + key + " is not a valid duration. Using default value: " + defaultValue.String())
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvAsBool retrieves the environment variable value by key and parses it as a bool, returning the defaultValue if unset or invalid.
+func GetEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+
+	if valueStr == "" {
+		log.Warn("Environment variable " + key + " is not set. Using default value: " + strconv.FormatBool(defaultValue))
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Warn("Environment variable " + key + " is not a valid bool. Using default value: " + strconv.FormatBool(defaultValue))
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvAsStringSlice retrieves the environment variable value by key and splits it on commas, trimming blanks, returning the defaultValue if unset.
+func GetEnvAsStringSlice(key string, defaultValue []string) []string {
+	valueStr := os.Getenv(key)
+
+	if valueStr == "" {
+		log.Warn("Environment variable " + key + " is not set. Using default value: " + strings.Join(defaultValue, ","))
+		return defaultValue
+	}
+
+	values := []string{}
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
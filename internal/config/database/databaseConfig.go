@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"LegoManagerAPI/internal/config/configUtilities"
 )
 
@@ -14,6 +16,18 @@ type DatabaseConfig struct {
 	Port     int
 	MaxConns int
 	MinConns int
+
+	// WarmPool opens MinConns connections during startup so the first requests don't pay connection setup
+	WarmPool bool
+
+	// HealthQuery is run by the readiness check in addition to a ping, empty means ping only
+	HealthQuery string
+
+	// RetryAttempts caps how often queries opting into retries try on transient errors, 1 disables retries
+	// RetryBaseDelay is the wait before the first retry, it doubles on every further retry up to RetryMaxDelay
+	RetryAttempts  int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
 }
 
 // LoadDatabaseConfig initializes and returns a DatabaseConfig struct populated with values from environment variables.
@@ -27,5 +41,12 @@ func LoadDatabaseConfig() DatabaseConfig {
 		SSLMode:  configUtilities.GetEnvAsString("POSTGRES_SSL_MODE", "disable"),
 		MaxConns: configUtilities.GetEnvAsInt("POSTGRES_MAX_CONNS", 100),
 		MinConns: configUtilities.GetEnvAsInt("POSTGRES_MIN_CONNS", 1),
+		WarmPool: configUtilities.GetEnvAsBool("POSTGRES_WARM_POOL", false),
+
+		HealthQuery: configUtilities.GetEnvAsString("POSTGRES_HEALTH_QUERY", ""),
+
+		RetryAttempts:  configUtilities.GetEnvAsInt("POSTGRES_RETRY_ATTEMPTS", 3),
+		RetryBaseDelay: configUtilities.GetEnvAsDuration("POSTGRES_RETRY_BASE_DELAY", 50*time.Millisecond),
+		RetryMaxDelay:  configUtilities.GetEnvAsDuration("POSTGRES_RETRY_MAX_DELAY", time.Second),
 	}
 }
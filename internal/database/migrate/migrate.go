@@ -0,0 +1,210 @@
+package migrate
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// files holds the migrations, named <version>_<name>.sql with a positive version like 0001_create_users.sql
+//
+//go:embed migrations/*.sql
+var files embed.FS
+
+// lockID is the Postgres advisory lock held while migrating so instances starting together don't race
+const lockID = 7_441_230_001
+
+// Migration is one embedded schema change
+type Migration struct {
+	Version int64
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus reports whether a migration has been applied, AppliedAt is nil for pending ones
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Up applies every pending migration in version order, each in its own transaction
+// A failing migration is rolled back and stops the run, the ones before it stay applied
+func Up(ctx context.Context, pool *pgxpool.Pool) error {
+	migrations, err := load(files)
+	if err != nil {
+		return err
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			log.Warn("Failed to release migration lock", "error", err)
+		}
+	}()
+
+	if err := ensureTable(ctx, conn.Conn()); err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, conn.Conn())
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := apply(ctx, conn.Conn(), migration); err != nil {
+			return err
+		}
+		log.Info("Applied database migration", "version", migration.Version, "name", migration.Name)
+	}
+
+	return nil
+}
+
+// Status lists every embedded migration in version order and when it was applied
+func Status(ctx context.Context, pool *pgxpool.Pool) ([]MigrationStatus, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if err := ensureTable(ctx, conn.Conn()); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(ctx, conn.Conn())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// load reads the migrations from fsys sorted by version, rejecting malformed names and duplicate versions
+func load(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(paths))
+	seen := make(map[int64]string, len(paths))
+	for _, file := range paths {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+
+		versionPart, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if !ok || name == "" || err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %q must be named <version>_<name>.sql", file)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", other, file, version)
+		}
+		seen[version] = file
+
+		sql, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", file, err)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(sql)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// ensureTable creates the schema_migrations tracking table if it does not exist yet
+func ensureTable(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions returns when each applied migration ran, keyed by version
+func appliedVersions(ctx context.Context, conn *pgx.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// apply runs a migration and records it in one transaction, so it is either fully applied or not at all
+func apply(ctx context.Context, conn *pgx.Conn, migration Migration) error {
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, migration.SQL); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/config/database"
+	dbpkg "LegoManagerAPI/internal/database"
+)
+
+// baselineInitScript is scripts/db/init.sql as it was before migrations existed, long lived databases such as
+// the docker postgres_data volume were created from it
+//
+//go:embed testdata/baseline_init.sql
+var baselineInitScript string
+
+// integrationConfig reads the test database settings, skipping the test when POSTGRES_HOST is not set
+func integrationConfig(t *testing.T) database.DatabaseConfig {
+	t.Helper()
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST not set, skipping database test")
+	}
+
+	port := 5432
+	if p := os.Getenv("POSTGRES_PORT"); p != "" {
+		fmt.Sscanf(p, "%d", &port)
+	}
+
+	return database.DatabaseConfig{
+		Host:     os.Getenv("POSTGRES_HOST"),
+		Port:     port,
+		User:     os.Getenv("POSTGRES_USER"),
+		Password: os.Getenv("POSTGRES_PASSWORD"),
+		DBName:   os.Getenv("POSTGRES_DB"),
+		SSLMode:  "disable",
+		MaxConns: 5,
+		MinConns: 1,
+	}
+}
+
+func TestUp_IsIdempotent(t *testing.T) {
+	db, err := dbpkg.NewPostgresDB(integrationConfig(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	require.NoError(t, Up(ctx, db.Pool))
+	require.NoError(t, Up(ctx, db.Pool), "a second run has nothing left to apply")
+
+	statuses, err := Status(ctx, db.Pool)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "migration %d_%s should be applied", status.Version, status.Name)
+	}
+}
+
+func TestUp_FromBaselineInitScript(t *testing.T) {
+	cfg := integrationConfig(t)
+	ctx := context.Background()
+
+	db, err := dbpkg.NewPostgresDB(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// A schema of its own keeps the baseline tables apart from the ones other tests migrate
+	schema := fmt.Sprintf("migrate_baseline_%d", time.Now().UnixNano())
+	_, err = db.Pool.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Pool.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+	})
+
+	poolConfig, err := pgxpool.ParseConfig(fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
+	))
+	require.NoError(t, err)
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(ctx, baselineInitScript)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `INSERT INTO users (username, password_hash, first_name, last_name) VALUES ('legacy', 'hash', 'Old', 'User')`)
+	require.NoError(t, err)
+
+	require.NoError(t, Up(ctx, pool))
+
+	rows, err := pool.Query(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = 'users'`, schema)
+	require.NoError(t, err)
+	var columns []string
+	for rows.Next() {
+		var column string
+		require.NoError(t, rows.Scan(&column))
+		columns = append(columns, column)
+	}
+	require.NoError(t, rows.Err())
+	assert.Subset(t, columns, []string{"preferred_currency", "timezone", "email", "external_id", "deleted_at"})
+
+	var currency, timezone string
+	require.NoError(t, pool.QueryRow(ctx, "SELECT preferred_currency, timezone FROM users WHERE username = 'legacy'").Scan(&currency, &timezone))
+	assert.Equal(t, "USD", currency, "existing users get the column default")
+	assert.Equal(t, "", timezone)
+
+	_, err = pool.Exec(ctx, `UPDATE users SET email = 'legacy@example.com', external_id = 'ext-1' WHERE username = 'legacy'`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `INSERT INTO users (username, password_hash, first_name, last_name, email) VALUES ('other', 'hash', 'New', 'User', 'legacy@example.com')`)
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr), "a duplicate email must be rejected, got %v", err)
+	assert.Equal(t, "users_email_key", pgErr.ConstraintName, "the repository maps this constraint to ErrEmailTaken")
+
+	// UpsertByExternalID relies on external_id being unique
+	_, err = pool.Exec(ctx, `
+		INSERT INTO users (username, password_hash, first_name, last_name, external_id) VALUES ('synced', 'hash', 'Synced', 'User', 'ext-1')
+		ON CONFLICT (external_id) DO UPDATE SET first_name = EXCLUDED.first_name`)
+	require.NoError(t, err)
+	var firstName string
+	require.NoError(t, pool.QueryRow(ctx, "SELECT first_name FROM users WHERE username = 'legacy'").Scan(&firstName))
+	assert.Equal(t, "Synced", firstName)
+}
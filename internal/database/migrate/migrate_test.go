@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_SortsByVersion(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"migrations/0010_add_index.sql":    {Data: []byte("CREATE INDEX idx ON t(a);")},
+		"migrations/0002_create_table.sql": {Data: []byte("CREATE TABLE t (a INT);")},
+		"migrations/README.md":             {Data: []byte("not a migration")},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, migrations, 2)
+	assert.Equal(t, Migration{Version: 2, Name: "create_table", SQL: "CREATE TABLE t (a INT);"}, migrations[0])
+	assert.Equal(t, int64(10), migrations[1].Version)
+	assert.Equal(t, "add_index", migrations[1].Name)
+}
+
+func TestLoad_RejectsBadNames(t *testing.T) {
+	for _, name := range []string{"migrations/create_table.sql", "migrations/0000_zero.sql", "migrations/0001_.sql", "migrations/0001.sql"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(fstest.MapFS{name: {Data: []byte("SELECT 1;")}})
+			assert.ErrorContains(t, err, "must be named")
+		})
+	}
+}
+
+func TestLoad_RejectsDuplicateVersions(t *testing.T) {
+	_, err := load(fstest.MapFS{
+		"migrations/0001_one.sql": {Data: []byte("SELECT 1;")},
+		"migrations/1_other.sql":  {Data: []byte("SELECT 1;")},
+	})
+	assert.ErrorContains(t, err, "share version 1")
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	migrations, err := load(files)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, Migration{Version: 1, Name: "create_users", SQL: migrations[0].SQL}, migrations[0])
+	assert.Contains(t, migrations[0].SQL, "CREATE TABLE IF NOT EXISTS users")
+}
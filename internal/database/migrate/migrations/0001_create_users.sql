@@ -0,0 +1,44 @@
+
+-- Create users table, the schema of the original init script
+-- Columns added since then have their own migrations, so databases created by that script catch up
+CREATE TABLE IF NOT EXISTS users (
+                                     id BIGSERIAL PRIMARY KEY,
+                                     username VARCHAR(50) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
+CREATE INDEX IF NOT EXISTS idx_users_name ON users(first_name, last_name);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at DESC);
+
+-- Create a function to automatically update updated_at timestamp
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create trigger to auto-update updated_at on row updates
+-- Dropped first so databases set up from the old init script can be migrated
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+CREATE TRIGGER update_users_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE users IS 'User accounts with authentication credentials';
+COMMENT ON COLUMN users.id IS 'Primary key, auto-incrementing';
+COMMENT ON COLUMN users.username IS 'Unique username for login';
+COMMENT ON COLUMN users.password_hash IS 'Bcrypt hashed password';
+COMMENT ON COLUMN users.first_name IS 'User first name';
+COMMENT ON COLUMN users.last_name IS 'User last name';
+COMMENT ON COLUMN users.created_at IS 'Timestamp when user was created';
+COMMENT ON COLUMN users.updated_at IS 'Timestamp when user was last updated';
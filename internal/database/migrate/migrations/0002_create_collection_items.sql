@@ -0,0 +1,36 @@
+-- Create collection_items table, the items each user owns
+CREATE TABLE IF NOT EXISTS collection_items (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_type VARCHAR(20) NOT NULL,
+    item_id VARCHAR(50) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    condition CHAR(1) NOT NULL DEFAULT 'N' CHECK (condition IN ('N', 'U')),
+    purchase_price NUMERIC(12, 2) CHECK (purchase_price >= 0),
+    purchase_date DATE,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT collection_items_user_item_key UNIQUE (user_id, item_type, item_id, condition)
+);
+
+-- The unique constraint's index also serves lookups by user, this one keeps a user's listing ordered
+CREATE INDEX IF NOT EXISTS idx_collection_items_user_created_at ON collection_items(user_id, created_at DESC);
+
+-- Reuse the updated_at trigger function from 0001_create_users
+DROP TRIGGER IF EXISTS update_collection_items_updated_at ON collection_items;
+CREATE TRIGGER update_collection_items_updated_at
+    BEFORE UPDATE ON collection_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE collection_items IS 'BrickLink catalog items owned by users, one row per item and condition';
+COMMENT ON COLUMN collection_items.user_id IS 'Owning user';
+COMMENT ON COLUMN collection_items.item_type IS 'BrickLink item type, e.g. MINIFIG or SET';
+COMMENT ON COLUMN collection_items.item_id IS 'BrickLink item number, e.g. sw0001';
+COMMENT ON COLUMN collection_items.quantity IS 'Number of copies owned';
+COMMENT ON COLUMN collection_items.condition IS 'N for new, U for used, as BrickLink encodes new_or_used';
+COMMENT ON COLUMN collection_items.purchase_price IS 'Optional price paid per unit, in the owner''s preferred currency';
+COMMENT ON COLUMN collection_items.purchase_date IS 'Optional day the item was bought';
+COMMENT ON COLUMN collection_items.notes IS 'Free-form notes of the owner';
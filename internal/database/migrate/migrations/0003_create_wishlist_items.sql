@@ -0,0 +1,31 @@
+-- Create wishlist_items table, the items each user wants to buy
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_type VARCHAR(20) NOT NULL,
+    item_id VARCHAR(50) NOT NULL,
+    condition CHAR(1) NOT NULL DEFAULT 'N' CHECK (condition IN ('N', 'U')),
+    target_price NUMERIC(12, 2) CHECK (target_price > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT wishlist_items_user_item_key UNIQUE (user_id, item_type, item_id, condition)
+);
+
+-- The unique constraint's index also serves lookups by user, this one keeps a user's wishlist ordered
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_user_created_at ON wishlist_items(user_id, created_at DESC);
+
+-- Reuse the updated_at trigger function from 0001_create_users
+DROP TRIGGER IF EXISTS update_wishlist_items_updated_at ON wishlist_items;
+CREATE TRIGGER update_wishlist_items_updated_at
+    BEFORE UPDATE ON wishlist_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE wishlist_items IS 'BrickLink catalog items users want to buy, one row per item and condition';
+COMMENT ON COLUMN wishlist_items.user_id IS 'Owning user';
+COMMENT ON COLUMN wishlist_items.item_type IS 'BrickLink item type, e.g. MINIFIG or SET';
+COMMENT ON COLUMN wishlist_items.item_id IS 'BrickLink item number, e.g. sw0001';
+COMMENT ON COLUMN wishlist_items.condition IS 'N for new, U for used, as BrickLink encodes new_or_used';
+COMMENT ON COLUMN wishlist_items.target_price IS 'Optional most the user wants to pay per unit, in the owner''s preferred currency';
+COMMENT ON COLUMN wishlist_items.created_at IS 'Timestamp when the item was added to the wishlist';
@@ -0,0 +1,11 @@
+-- Let collection items be archived instead of deleted, archived items are kept read-only
+ALTER TABLE collection_items ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE collection_items ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+-- archived_at is set exactly while the item is archived
+ALTER TABLE collection_items DROP CONSTRAINT IF EXISTS collection_items_archived_at_check;
+ALTER TABLE collection_items ADD CONSTRAINT collection_items_archived_at_check CHECK (archived = (archived_at IS NOT NULL));
+
+-- Add comments for documentation
+COMMENT ON COLUMN collection_items.archived IS 'Archived items are read-only and hidden from listings unless asked for';
+COMMENT ON COLUMN collection_items.archived_at IS 'When the item was archived, NULL while it is active';
@@ -0,0 +1,25 @@
+-- Create portfolio_snapshots table, the value history of each user's portfolio
+CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    total_value NUMERIC(14, 2) NOT NULL,
+    total_purchase_cost NUMERIC(14, 2) NOT NULL,
+    item_count INTEGER NOT NULL CHECK (item_count >= 0),
+    unpriced_count INTEGER NOT NULL CHECK (unpriced_count >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Serves a user's history in a time range
+CREATE INDEX IF NOT EXISTS idx_portfolio_snapshots_user_created_at ON portfolio_snapshots(user_id, created_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE portfolio_snapshots IS 'Periodic valuations of each user''s portfolio, written by the snapshot job and never updated';
+COMMENT ON COLUMN portfolio_snapshots.user_id IS 'Owning user';
+COMMENT ON COLUMN portfolio_snapshots.currency IS 'The owner''s preferred currency when the snapshot was taken';
+COMMENT ON COLUMN portfolio_snapshots.total_value IS 'Market value of the items with a known BrickLink price';
+COMMENT ON COLUMN portfolio_snapshots.total_purchase_cost IS 'Purchase cost of the items with a known purchase price';
+COMMENT ON COLUMN portfolio_snapshots.item_count IS 'Number of collection entries valued, archived ones excluded';
+COMMENT ON COLUMN portfolio_snapshots.unpriced_count IS 'Number of entries without a market price, left out of total_value';
+COMMENT ON COLUMN portfolio_snapshots.created_at IS 'Timestamp when the snapshot was taken';
@@ -0,0 +1,4 @@
+-- Add the currency valuations are shown in by default
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+COMMENT ON COLUMN users.preferred_currency IS 'ISO 4217 currency used for valuations by default';
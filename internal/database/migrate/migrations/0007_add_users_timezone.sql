@@ -0,0 +1,4 @@
+-- Add the timezone timestamps in responses are localized to
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN users.timezone IS 'Optional IANA timezone used to localize timestamps in responses, empty keeps UTC';
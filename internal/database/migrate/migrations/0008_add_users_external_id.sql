@@ -0,0 +1,7 @@
+-- Add the id of the user in an external system, syncs upsert on it
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+-- Named like the constraint an inline UNIQUE creates, so databases that already have it are left alone
+CREATE UNIQUE INDEX IF NOT EXISTS users_external_id_key ON users(external_id);
+
+COMMENT ON COLUMN users.external_id IS 'Optional id of the user in an external system, used to make syncs idempotent';
@@ -0,0 +1,7 @@
+-- Add the optional contact email
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+
+-- Named like the constraint an inline UNIQUE creates, the repository maps violations of users_email_key to ErrEmailTaken
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users(email);
+
+COMMENT ON COLUMN users.email IS 'Optional lowercase contact email, unique across users';
@@ -0,0 +1,4 @@
+-- Add soft deletion of users
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+COMMENT ON COLUMN users.deleted_at IS 'Timestamp when user was soft deleted, NULL for active users';
@@ -13,8 +13,14 @@ import (
 
 type PostgresDB struct {
 	Pool *pgxpool.Pool
+
+	// healthQuery is the statement HealthQuery runs, empty when none was configured
+	healthQuery string
 }
 
+// defaultHealthQuery is run by HealthQuery when no query was configured
+const defaultHealthQuery = "SELECT 1"
+
 // NewPostgresDB initializes and returns a PostgresDB instance with a connection pool configured using the provided DatabaseConfig.
 func NewPostgresDB(cfg database.DatabaseConfig) (*PostgresDB, error) {
 	// Build the connection string
@@ -46,7 +52,36 @@ func NewPostgresDB(cfg database.DatabaseConfig) (*PostgresDB, error) {
 
 	log.Info("Database connection pool created")
 
-	return &PostgresDB{Pool: pool}, nil
+	if cfg.WarmPool {
+		warmed := warmPool(ctx, pool, cfg.MinConns)
+		log.Info("Database connection pool warmed", "connections", warmed, "requested", cfg.MinConns)
+	}
+
+	return &PostgresDB{Pool: pool, healthQuery: cfg.HealthQuery}, nil
+}
+
+// warmPool establishes up to n connections by acquiring them all at once and then releasing them to the pool
+// Failures are logged rather than returned, a cold pool still works, only slower at first
+// Returns the number of connections acquired
+func warmPool(ctx context.Context, pool *pgxpool.Pool, n int) int {
+	conns := make([]*pgxpool.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Release()
+		}
+	}()
+
+	// Holding every connection until the end forces the pool to open a new one on each acquire
+	for i := 0; i < n; i++ {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			log.Warn("Failed to warm database connection", "warmed", len(conns), "requested", n, "error", err)
+			break
+		}
+		conns = append(conns, conn)
+	}
+
+	return len(conns)
 }
 
 // Ping checks the connection to the database by pinging the connection pool. Returns an error if the ping fails.
@@ -57,6 +92,25 @@ func (db *PostgresDB) Ping(ctx context.Context) error {
 	return nil
 }
 
+// HasHealthQuery reports whether a health query was configured through POSTGRES_HEALTH_QUERY
+func (db *PostgresDB) HasHealthQuery() bool {
+	return db.healthQuery != ""
+}
+
+// HealthQuery runs the configured health query, or SELECT 1, to check the database can actually serve queries.
+// Unlike Ping this catches a database that accepts connections but fails on e.g. a locked or missing table.
+func (db *PostgresDB) HealthQuery(ctx context.Context) error {
+	query := db.healthQuery
+	if query == "" {
+		query = defaultHealthQuery
+	}
+
+	if _, err := db.Pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("health query failed: %w", err)
+	}
+	return nil
+}
+
 // Close gracefully closes all active connections in the pool.
 func (db *PostgresDB) Close() error {
 	log.Info("Closing database connection pool")
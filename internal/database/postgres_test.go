@@ -76,3 +76,42 @@ func TestPostgresDB_Close(t *testing.T) {
 	err = db.Close()
 	assert.NoError(t, err, "close should not return error")
 }
+
+func TestPostgresDB_HealthQuery(t *testing.T) {
+	cfg := setupTestConfig()
+	cfg.HealthQuery = "SELECT 1"
+	db, err := dbpkg.NewPostgresDB(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.True(t, db.HasHealthQuery())
+	assert.NoError(t, db.HealthQuery(ctx), "health query should succeed on healthy DB")
+}
+
+func TestPostgresDB_HealthQueryReportsBrokenSchema(t *testing.T) {
+	cfg := setupTestConfig()
+	cfg.HealthQuery = "SELECT COUNT(*) FROM table_that_does_not_exist"
+	db, err := dbpkg.NewPostgresDB(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.Error(t, db.HealthQuery(ctx), "a failing query should be reported even when ping succeeds")
+}
+
+func TestNewPostgresDB_WarmPool(t *testing.T) {
+	cfg := setupTestConfig()
+	cfg.MinConns = 3
+	cfg.WarmPool = true
+
+	db, err := dbpkg.NewPostgresDB(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.GreaterOrEqual(t, db.Stats().TotalConns(), int32(3), "warmup should open MinConns connections")
+}
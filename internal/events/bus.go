@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// handlerTimeout bounds how long a single subscriber may take to handle an event
+const handlerTimeout = 10 * time.Second
+
+// Event is implemented by every event published on the bus
+type Event interface {
+	// Name identifies the event type subscribers register for
+	Name() string
+}
+
+// handler is the untyped form of a subscriber stored by the bus
+type handler func(ctx context.Context, event Event)
+
+// Bus is a bounded, in-process publish/subscribe bus
+// Events are queued and handled asynchronously by a fixed pool of workers
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]handler
+	queue       chan Event
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// NewBus creates a bus with a queue of bufferSize events drained by the given number of workers
+func NewBus(bufferSize, workers int) *Bus {
+	if workers < 1 {
+		workers = 1
+	}
+
+	b := &Bus{
+		subscribers: make(map[string][]handler),
+		queue:       make(chan Event, bufferSize),
+	}
+
+	for i := 0; i < workers; i++ {
+		b.wg.Add(1)
+		go b.worker()
+	}
+
+	return b
+}
+
+// Subscribe registers a typed handler for events of type E
+// Subscribers should be registered at startup, before events are published
+func Subscribe[E Event](b *Bus, fn func(ctx context.Context, event E)) {
+	var zero E
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[zero.Name()] = append(b.subscribers[zero.Name()], func(ctx context.Context, event Event) {
+		if typed, ok := event.(E); ok {
+			fn(ctx, typed)
+		}
+	})
+}
+
+// Publish queues an event without blocking the caller
+// Returns false if the event was dropped because the queue is full or the bus is closed
+func (b *Bus) Publish(event Event) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return false
+	}
+
+	select {
+	case b.queue <- event:
+		return true
+	default:
+		log.Warn("Event bus queue full, dropping event", "event", event.Name())
+		return false
+	}
+}
+
+// Close stops accepting events and waits for queued events to be handled
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	log.Info("Event bus stopped")
+}
+
+// worker handles queued events until the queue is closed
+func (b *Bus) worker() {
+	defer b.wg.Done()
+
+	for event := range b.queue {
+		b.mu.RLock()
+		handlers := b.subscribers[event.Name()]
+		b.mu.RUnlock()
+
+		for _, h := range handlers {
+			b.dispatch(h, event)
+		}
+	}
+}
+
+// dispatch runs a single handler, recovering from panics so one bad subscriber can't stop the bus
+func (b *Bus) dispatch(h handler, event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), handlerTimeout)
+	defer cancel()
+
+	defer func() {
+		if p := recover(); p != nil {
+			log.Error("Event subscriber panicked", "event", event.Name(), "panic", fmt.Sprint(p))
+		}
+	}()
+
+	h(ctx, event)
+}
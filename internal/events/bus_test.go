@@ -0,0 +1,54 @@
+package events_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"LegoManagerAPI/internal/events"
+)
+
+func TestBus_DeliversToSubscribers(t *testing.T) {
+	bus := events.NewBus(10, 2)
+
+	var mu sync.Mutex
+	var received []int64
+
+	events.Subscribe(bus, func(ctx context.Context, event events.UserCreated) {
+		mu.Lock()
+		received = append(received, event.UserID)
+		mu.Unlock()
+	})
+
+	assert.True(t, bus.Publish(events.UserCreated{UserID: 1}))
+	assert.True(t, bus.Publish(events.UserCreated{UserID: 2}))
+	bus.Close()
+
+	assert.ElementsMatch(t, []int64{1, 2}, received)
+}
+
+func TestBus_RecoversFromPanickingSubscriber(t *testing.T) {
+	bus := events.NewBus(10, 1)
+
+	delivered := false
+	events.Subscribe(bus, func(ctx context.Context, event events.UserCreated) {
+		panic("boom")
+	})
+	events.Subscribe(bus, func(ctx context.Context, event events.UserCreated) {
+		delivered = true
+	})
+
+	bus.Publish(events.UserCreated{UserID: 1})
+	bus.Close()
+
+	assert.True(t, delivered, "later subscribers should still run after a panic")
+}
+
+func TestBus_PublishAfterCloseIsDropped(t *testing.T) {
+	bus := events.NewBus(1, 1)
+	bus.Close()
+
+	assert.False(t, bus.Publish(events.UserCreated{UserID: 1}))
+}
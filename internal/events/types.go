@@ -0,0 +1,11 @@
+package events
+
+// UserCreated is published after a new user has been stored
+type UserCreated struct {
+	UserID   int64
+	Username string
+}
+
+func (UserCreated) Name() string {
+	return "user.created"
+}
@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/clock"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+// snapshotUserTimeout bounds valuing and storing a single user's portfolio
+const snapshotUserTimeout = time.Minute
+
+// PortfolioSnapshots periodically stores the value of every user's portfolio, building the value history
+// Items are valued as by the portfolio value endpoint, so prices come from the shared BrickLink cache
+type PortfolioSnapshots struct {
+	userIDs   func(ctx context.Context) ([]int64, error)
+	latest    func(ctx context.Context) (map[int64]time.Time, error)
+	findUser  func(ctx context.Context, id int64) (*models.User, error)
+	findItems func(ctx context.Context, userID int64, includeArchived bool) ([]*models.Collection, error)
+	save      func(ctx context.Context, snapshot *models.PortfolioSnapshot) error
+	lookup    service.PriceLookup
+	interval  time.Duration
+
+	// remainingQuota reports the BrickLink calls left today, -1 without a cap. A round stops before the next
+	// user once it is down to quotaReserve, so the job can't starve interactive requests
+	remainingQuota func(ctx context.Context) int
+	quotaReserve   int
+
+	clock clock.Clock
+}
+
+// NewPortfolioSnapshots creates the snapshot job, taking a snapshot of every collection each interval
+// quotaReserve is the part of the BrickLink daily quota the job leaves for interactive requests
+func NewPortfolioSnapshots(
+	userRepo *repos.UserRepository,
+	collectionRepo *repos.CollectionRepository,
+	snapshotRepo *repos.SnapshotRepository,
+	bricklinkClient *service.CachedBricklinkClient,
+	interval time.Duration,
+	quotaReserve int,
+) *PortfolioSnapshots {
+	return &PortfolioSnapshots{
+		userIDs:        collectionRepo.UserIDs,
+		latest:         snapshotRepo.LatestByUser,
+		findUser:       userRepo.FindByID,
+		findItems:      collectionRepo.FindByUser,
+		save:           snapshotRepo.Create,
+		lookup:         bricklinkClient.ItemPrice,
+		interval:       interval,
+		remainingQuota: bricklinkClient.Service().RemainingDailyQuota,
+		quotaReserve:   quotaReserve,
+		clock:          clock.Real(),
+	}
+}
+
+// Run catches up on users whose latest snapshot is older than the interval, then takes a round of snapshots
+// every interval until ctx is done. Restarts therefore neither add extra points nor push the next one back
+// A round in progress when ctx is done is abandoned
+func (j *PortfolioSnapshots) Run(ctx context.Context) {
+	log.Info("Portfolio snapshot job started", "interval", j.interval, "quota_reserve", j.quotaReserve)
+	j.CatchUp(ctx)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Portfolio snapshot job stopped")
+			return
+		case <-ticker.C:
+			j.SnapshotAll(ctx)
+		}
+	}
+}
+
+// SnapshotAll stores a snapshot for every user owning items that are not archived
+// Users are valued one after another, a failure is logged and doesn't stop the others
+func (j *PortfolioSnapshots) SnapshotAll(ctx context.Context) {
+	userIDs, err := j.userIDs(ctx)
+	if err != nil {
+		log.Error("Failed to list users for portfolio snapshots", "error", err)
+		return
+	}
+
+	j.snapshotUsers(ctx, userIDs)
+}
+
+// CatchUp stores a snapshot for the users SnapshotAll covers whose latest one is older than the interval or missing
+func (j *PortfolioSnapshots) CatchUp(ctx context.Context) {
+	userIDs, err := j.userIDs(ctx)
+	if err != nil {
+		log.Error("Failed to list users for portfolio snapshots", "error", err)
+		return
+	}
+
+	latest, err := j.latest(ctx)
+	if err != nil {
+		log.Error("Failed to find latest portfolio snapshots", "error", err)
+		return
+	}
+
+	now := j.clock.Now()
+	due := make([]int64, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if takenAt, ok := latest[userID]; !ok || now.Sub(takenAt) >= j.interval {
+			due = append(due, userID)
+		}
+	}
+
+	j.snapshotUsers(ctx, due)
+}
+
+// snapshotUsers stores a snapshot for each of the users in turn, stopping early when ctx is done or the
+// BrickLink quota is down to the reserve. A user's items are all valued once started, so the reserve can be
+// dipped into by up to one collection
+func (j *PortfolioSnapshots) snapshotUsers(ctx context.Context, userIDs []int64) {
+	start := time.Now()
+
+	taken := 0
+	for i, userID := range userIDs {
+		if ctx.Err() != nil {
+			log.Warn("Portfolio snapshots interrupted", "taken", taken, "users", len(userIDs))
+			return
+		}
+
+		if j.remainingQuota != nil {
+			if remaining := j.remainingQuota(ctx); remaining >= 0 && remaining <= j.quotaReserve {
+				log.Warn("Portfolio snapshots stopped to leave BrickLink quota for requests",
+					"taken", taken, "skipped", len(userIDs)-i, "remaining_quota", remaining)
+				return
+			}
+		}
+
+		if err := j.snapshot(ctx, userID); err != nil {
+			log.Warn("Failed to take portfolio snapshot", "user_id", userID, "error", err)
+			continue
+		}
+		taken++
+	}
+
+	log.Info("Portfolio snapshots taken", "taken", taken, "users", len(userIDs), "duration", time.Since(start))
+}
+
+// snapshot values one user's portfolio in their preferred currency and stores it
+// Users deleted since their ids were listed are skipped without an error
+func (j *PortfolioSnapshots) snapshot(ctx context.Context, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, snapshotUserTimeout)
+	defer cancel()
+
+	user, err := j.findUser(ctx, userID)
+	if errors.Is(err, repos.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	items, err := j.findItems(ctx, userID, false)
+	if err != nil {
+		return fmt.Errorf("failed to load collection: %w", err)
+	}
+
+	currency := user.PreferredCurrency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	value := service.ValuePortfolio(ctx, items, currency, j.lookup)
+
+	// A round cut short by shutdown would otherwise store a value missing every price
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return j.save(ctx, &models.PortfolioSnapshot{
+		UserID:            userID,
+		Currency:          currency,
+		TotalValue:        value.TotalCurrentValue.Float64(),
+		TotalPurchaseCost: value.TotalPurchaseCost.Float64(),
+		ItemCount:         len(value.Items),
+		UnpricedCount:     len(value.Warnings),
+	})
+}
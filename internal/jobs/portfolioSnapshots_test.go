@@ -0,0 +1,166 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/api/service"
+	"LegoManagerAPI/internal/clock"
+	"LegoManagerAPI/internal/models"
+	"LegoManagerAPI/internal/repos"
+)
+
+// testNow is the fake clock's time in job tests
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestJob returns a job over users 1 (EUR), 2 (deleted) and 3 (failing collection), recording saved snapshots
+// None of them has a snapshot yet and there is no BrickLink quota cap
+func newTestJob(saved *[]*models.PortfolioSnapshot) *PortfolioSnapshots {
+	var mu sync.Mutex
+	paid := 2.0
+
+	return &PortfolioSnapshots{
+		userIDs: func(ctx context.Context) ([]int64, error) {
+			return []int64{1, 2, 3}, nil
+		},
+		latest: func(ctx context.Context) (map[int64]time.Time, error) {
+			return map[int64]time.Time{}, nil
+		},
+		findUser: func(ctx context.Context, id int64) (*models.User, error) {
+			if id == 2 {
+				return nil, repos.ErrUserNotFound
+			}
+			return &models.User{BaseModel: models.BaseModel{ID: id}, PreferredCurrency: "EUR"}, nil
+		},
+		findItems: func(ctx context.Context, userID int64, includeArchived bool) ([]*models.Collection, error) {
+			if userID == 3 {
+				return nil, errors.New("connection reset")
+			}
+			return []*models.Collection{
+				{ItemType: "MINIFIG", ItemID: "sw0001", Condition: "N", Quantity: 2, PurchasePrice: &paid},
+				{ItemType: "MINIFIG", ItemID: "sw9999", Condition: "N", Quantity: 1},
+			}, nil
+		},
+		save: func(ctx context.Context, snapshot *models.PortfolioSnapshot) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*saved = append(*saved, snapshot)
+			return nil
+		},
+		lookup: func(ctx context.Context, itemType service.ItemType, itemID, condition, currency string) (*service.MinifigPrice, error) {
+			if itemID == "sw9999" {
+				return &service.MinifigPrice{QtyAvgPrice: "0.0000", CurrencyCode: currency}, nil
+			}
+			return &service.MinifigPrice{QtyAvgPrice: "5.25", CurrencyCode: currency}, nil
+		},
+		interval: 10 * time.Millisecond,
+		clock:    clock.NewFake(testNow),
+	}
+}
+
+func TestPortfolioSnapshots_SnapshotAll(t *testing.T) {
+	var saved []*models.PortfolioSnapshot
+	newTestJob(&saved).SnapshotAll(context.Background())
+
+	// The deleted user is skipped and the failing one doesn't stop the round
+	require.Len(t, saved, 1)
+	assert.Equal(t, &models.PortfolioSnapshot{
+		UserID:            1,
+		Currency:          "EUR",
+		TotalValue:        10.5,
+		TotalPurchaseCost: 4,
+		ItemCount:         2,
+		UnpricedCount:     1,
+	}, saved[0])
+}
+
+func TestPortfolioSnapshots_CatchUpTakesDueSnapshots(t *testing.T) {
+	var saved []*models.PortfolioSnapshot
+	job := newTestJob(&saved)
+	job.interval = 24 * time.Hour
+	job.userIDs = func(ctx context.Context) ([]int64, error) {
+		return []int64{1, 4, 5}, nil
+	}
+	job.latest = func(ctx context.Context) (map[int64]time.Time, error) {
+		return map[int64]time.Time{
+			1: testNow.Add(-25 * time.Hour),
+			4: testNow.Add(-time.Hour),
+		}, nil
+	}
+
+	job.CatchUp(context.Background())
+
+	// User 4 is recent enough, 5 never had a snapshot
+	require.Len(t, saved, 2)
+	assert.Equal(t, int64(1), saved[0].UserID)
+	assert.Equal(t, int64(5), saved[1].UserID)
+}
+
+func TestPortfolioSnapshots_LeavesQuotaReserve(t *testing.T) {
+	var saved []*models.PortfolioSnapshot
+	job := newTestJob(&saved)
+	job.userIDs = func(ctx context.Context) ([]int64, error) {
+		return []int64{1, 4, 5}, nil
+	}
+
+	// Prices are looked up concurrently, so the quota is counted atomically
+	var remaining atomic.Int64
+	remaining.Store(102)
+	job.quotaReserve = 100
+	job.remainingQuota = func(ctx context.Context) int {
+		return int(remaining.Load())
+	}
+	lookup := job.lookup
+	job.lookup = func(ctx context.Context, itemType service.ItemType, itemID, condition, currency string) (*service.MinifigPrice, error) {
+		remaining.Add(-1)
+		return lookup(ctx, itemType, itemID, condition, currency)
+	}
+
+	job.SnapshotAll(context.Background())
+
+	// Valuing user 1 spends two calls, which leaves only the reserve
+	require.Len(t, saved, 1)
+	assert.Equal(t, int64(1), saved[0].UserID)
+}
+
+func TestPortfolioSnapshots_RunStopsWithContext(t *testing.T) {
+	var saved []*models.PortfolioSnapshot
+	job := newTestJob(&saved)
+	job.userIDs = func(ctx context.Context) ([]int64, error) {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		job.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after its context was cancelled")
+	}
+}
+
+func TestPortfolioSnapshots_InterruptedRoundStoresNothing(t *testing.T) {
+	var saved []*models.PortfolioSnapshot
+	job := newTestJob(&saved)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job.SnapshotAll(ctx)
+
+	assert.Empty(t, saved)
+}
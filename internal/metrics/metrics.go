@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"LegoManagerAPI/internal/api/service"
+)
+
+// Metrics holds the Prometheus collectors exposed on /metrics
+// It uses its own registry so tests can create as many as they like
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	bricklinkRequests *prometheus.CounterVec
+	bricklinkDuration *prometheus.HistogramVec
+}
+
+// New creates the API metrics along with the Go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests served, by route pattern, method and status code.",
+		}, []string{"route", "method", "status"}),
+
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time spent serving HTTP requests, by route pattern and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+
+		bricklinkRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bricklink_requests_total",
+			Help: "Calls made to the BrickLink API, by endpoint and outcome.",
+		}, []string{"endpoint", "outcome"}),
+
+		bricklinkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bricklink_request_duration_seconds",
+			Help:    "Latency of BrickLink API calls, by endpoint.",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"endpoint"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.bricklinkRequests,
+		m.bricklinkDuration,
+	)
+
+	return m
+}
+
+// Handler serves the registered metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Register adds another collector, e.g. a PoolCollector, to the registry
+func (m *Metrics) Register(collector prometheus.Collector) {
+	m.registry.MustRegister(collector)
+}
+
+// ObserveHTTP records a served request, route must be the matched pattern rather than the raw path
+// so ids in URLs don't create a series per resource
+func (m *Metrics) ObserveHTTP(route, method string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveBricklink records a BrickLink API call, it matches service.RequestObserver
+func (m *Metrics) ObserveBricklink(endpoint string, duration time.Duration, err error) {
+	m.bricklinkRequests.WithLabelValues(endpoint, bricklinkOutcome(err)).Inc()
+	m.bricklinkDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+// bricklinkOutcome buckets a BrickLink call's error into a low-cardinality label
+func bricklinkOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, service.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, service.ErrBrickLinkAuth):
+		return "auth_error"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/api/service"
+)
+
+// scrape returns the /metrics output of m
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_HTTP(t *testing.T) {
+	m := New()
+	m.ObserveHTTP("/api/users/", http.MethodGet, http.StatusOK, 20*time.Millisecond)
+	m.ObserveHTTP("/api/users/", http.MethodGet, http.StatusOK, 30*time.Millisecond)
+	m.ObserveHTTP("/api/users/", http.MethodGet, http.StatusNotFound, time.Millisecond)
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/api/users/",status="200"} 2`)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/api/users/",status="404"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/api/users/"} 3`)
+	assert.Contains(t, out, "go_goroutines")
+}
+
+func TestMetrics_Bricklink(t *testing.T) {
+	m := New()
+	m.ObserveBricklink("minifig_price", time.Second, nil)
+	m.ObserveBricklink("minifig_info", time.Second, fmt.Errorf("%w: sw9999", service.ErrNotFound))
+	m.ObserveBricklink("minifig_info", time.Second, service.ErrBrickLinkAuth)
+	m.ObserveBricklink("colors", time.Second, fmt.Errorf("request failed"))
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `bricklink_requests_total{endpoint="minifig_price",outcome="success"} 1`)
+	assert.Contains(t, out, `bricklink_requests_total{endpoint="minifig_info",outcome="not_found"} 1`)
+	assert.Contains(t, out, `bricklink_requests_total{endpoint="minifig_info",outcome="auth_error"} 1`)
+	assert.Contains(t, out, `bricklink_requests_total{endpoint="colors",outcome="error"} 1`)
+	assert.Contains(t, out, `bricklink_request_duration_seconds_count{endpoint="minifig_info"} 2`)
+}
+
+func TestPoolCollector_ReadsStatsOnScrape(t *testing.T) {
+	// The pool connects lazily, so nothing has to listen on the address
+	pool, err := pgxpool.New(context.Background(), "postgres://user@127.0.0.1:1/db?pool_max_conns=4")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	m := New()
+
+	scrapes := 0
+	m.Register(NewPoolCollector(func() *pgxpool.Stat {
+		scrapes++
+		return pool.Stat()
+	}))
+
+	out := scrape(t, m)
+	scrape(t, m)
+
+	assert.Equal(t, 2, scrapes, "pool stats are refreshed on every scrape")
+	for _, name := range []string{"db_pool_total_conns", "db_pool_idle_conns", "db_pool_acquired_conns"} {
+		assert.Contains(t, out, name+" 0")
+	}
+	assert.Contains(t, out, "db_pool_max_conns 4")
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolTotalConns = prometheus.NewDesc("db_pool_total_conns",
+		"Connections currently in the pool, idle, acquired or being established.", nil, nil)
+	poolIdleConns = prometheus.NewDesc("db_pool_idle_conns",
+		"Idle connections in the pool.", nil, nil)
+	poolAcquiredConns = prometheus.NewDesc("db_pool_acquired_conns",
+		"Connections currently checked out of the pool.", nil, nil)
+	poolMaxConns = prometheus.NewDesc("db_pool_max_conns",
+		"Maximum size of the pool.", nil, nil)
+	poolAcquires = prometheus.NewDesc("db_pool_acquires_total",
+		"Successful connection acquires from the pool.", nil, nil)
+	poolEmptyAcquires = prometheus.NewDesc("db_pool_empty_acquires_total",
+		"Acquires that had to wait for a connection because none was idle.", nil, nil)
+	poolAcquireSeconds = prometheus.NewDesc("db_pool_acquire_duration_seconds_total",
+		"Total time spent waiting to acquire connections.", nil, nil)
+)
+
+// PoolCollector exports pgx pool stats, read fresh from the pool on every scrape
+type PoolCollector struct {
+	stats func() *pgxpool.Stat
+}
+
+// NewPoolCollector creates a PoolCollector reading stats, e.g. PostgresDB.Stats
+func NewPoolCollector(stats func() *pgxpool.Stat) *PoolCollector {
+	return &PoolCollector{stats: stats}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolTotalConns
+	ch <- poolIdleConns
+	ch <- poolAcquiredConns
+	ch <- poolMaxConns
+	ch <- poolAcquires
+	ch <- poolEmptyAcquires
+	ch <- poolAcquireSeconds
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stats.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stats.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stats.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stats.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stats.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stats.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireSeconds, prometheus.CounterValue, stats.AcquireDuration().Seconds())
+}
@@ -5,9 +5,18 @@ import (
 )
 
 // Model is the base interface that all models must implement
+// It is implemented by pointers to models, SetID has to write through to the model
 type Model interface {
 	GetID() int64
 	SetID(id int64)
+	GetCreatedAt() time.Time
+}
+
+// ModelPtr constrains PT to be a *T implementing Model, letting generic code
+// work with model values T while still calling SetID
+type ModelPtr[T any] interface {
+	*T
+	Model
 }
 
 // BaseModel contains common fields for all models
@@ -21,6 +30,10 @@ func (b BaseModel) GetID() int64 {
 	return b.ID
 }
 
-func (b BaseModel) SetID(id int64) {
+func (b BaseModel) GetCreatedAt() time.Time {
+	return b.CreatedAt
+}
+
+func (b *BaseModel) SetID(id int64) {
 	b.ID = id
 }
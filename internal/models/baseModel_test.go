@@ -0,0 +1,14 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseModel_SetIDPersists(t *testing.T) {
+	user := &User{}
+	user.SetID(42)
+
+	assert.Equal(t, int64(42), user.GetID())
+}
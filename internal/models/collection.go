@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Conditions a collection item can be in, matching BrickLink's new_or_used codes
+const (
+	ConditionNew  = "N"
+	ConditionUsed = "U"
+)
+
+// Collection is one entry of a user's portfolio, a quantity of a BrickLink catalog item in one condition
+type Collection struct {
+	BaseModel
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// ItemType and ItemID identify the BrickLink catalog item, e.g. MINIFIG and sw0001
+	ItemType  string `json:"item_type" db:"item_type"`
+	ItemID    string `json:"item_id" db:"item_id"`
+	Quantity  int    `json:"quantity" db:"quantity"`
+	Condition string `json:"condition" db:"condition"`
+
+	// PurchasePrice is the price paid per unit, in the owner's preferred currency, nil when unknown
+	PurchasePrice *float64 `json:"purchase_price,omitempty" db:"purchase_price"`
+
+	// PurchaseDate is the day the item was bought, nil when unknown
+	PurchaseDate *time.Time `json:"purchase_date,omitempty" db:"purchase_date"`
+	Notes        string     `json:"notes" db:"notes"`
+
+	// Archived items are kept but read-only and left out of listings, ArchivedAt is set while Archived is
+	Archived   bool       `json:"archived" db:"archived"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+}
+
+// Collection implements Model through its pointer, see BaseModel.SetID
+var _ Model = (*Collection)(nil)
+
+// TableName returns the database table name
+func (Collection) TableName() string {
+	return "collection_items"
+}
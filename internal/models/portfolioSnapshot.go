@@ -0,0 +1,27 @@
+package models
+
+// PortfolioSnapshot is the value of a user's portfolio at one point in time, CreatedAt is when it was taken
+// Snapshots are never changed after they are written
+type PortfolioSnapshot struct {
+	BaseModel
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// Currency is the owner's preferred currency when the snapshot was taken, all amounts are in it
+	Currency string `json:"currency" db:"currency"`
+
+	// TotalValue and TotalPurchaseCost are the portfolio totals, only covering items with a known amount
+	TotalValue        float64 `json:"total_value" db:"total_value"`
+	TotalPurchaseCost float64 `json:"total_purchase_cost" db:"total_purchase_cost"`
+
+	// ItemCount is the number of collection entries valued, UnpricedCount how many of them had no market price
+	ItemCount     int `json:"item_count" db:"item_count"`
+	UnpricedCount int `json:"unpriced_count" db:"unpriced_count"`
+}
+
+// PortfolioSnapshot implements Model through its pointer, see BaseModel.SetID
+var _ Model = (*PortfolioSnapshot)(nil)
+
+// TableName returns the database table name
+func (PortfolioSnapshot) TableName() string {
+	return "portfolio_snapshots"
+}
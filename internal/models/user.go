@@ -1,18 +1,48 @@
 package models
 
+import "time"
+
 type User struct {
 	BaseModel
-	Username     string `json:"username" db:"username"`
-	PasswordHash string `json:",omitempty" db:"password_hash"`
-	FirstName    string `json:"first_name" db:"first_name"`
-	LastName     string `json:"last_name" db:"last_name"`
+	Username          string `json:"username" db:"username"`
+	PasswordHash      string `json:"-" db:"password_hash"`
+	FirstName         string `json:"first_name" db:"first_name"`
+	LastName          string `json:"last_name" db:"last_name"`
+	PreferredCurrency string `json:"preferred_currency" db:"preferred_currency"`
+	Timezone          string `json:"timezone" db:"timezone"`
+
+	// Email is stored lowercase and unique, nil for users who haven't given one
+	Email *string `json:"email,omitempty" db:"email"`
+
+	// ExternalID identifies the user in an external system (e.g. an SSO provider), nil for local users
+	ExternalID *string `json:"external_id,omitempty" db:"external_id"`
+
+	// DeletedAt is set once the user was soft deleted, nil for active users
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// User implements Model through its pointer, see BaseModel.SetID
+var _ Model = (*User)(nil)
+
 // TableName returns the database table name
 func (User) TableName() string {
 	return "users"
 }
 
+// Location returns the user's timezone, or UTC when none is set or it no longer loads
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
 // FullName returns the user's full name
 func (u *User) FullName() string {
 	return u.FirstName + " " + u.LastName
@@ -0,0 +1,23 @@
+package models
+
+// Wishlist is an item a user wants to buy, CreatedAt is when it was added
+type Wishlist struct {
+	BaseModel
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// ItemType and ItemID identify the BrickLink catalog item, e.g. MINIFIG and sw0001
+	ItemType  string `json:"item_type" db:"item_type"`
+	ItemID    string `json:"item_id" db:"item_id"`
+	Condition string `json:"condition" db:"condition"`
+
+	// TargetPrice is the most the user wants to pay per unit, in their preferred currency, nil to only track the item
+	TargetPrice *float64 `json:"target_price,omitempty" db:"target_price"`
+}
+
+// Wishlist implements Model through its pointer, see BaseModel.SetID
+var _ Model = (*Wishlist)(nil)
+
+// TableName returns the database table name
+func (Wishlist) TableName() string {
+	return "wishlist_items"
+}
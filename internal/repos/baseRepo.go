@@ -2,44 +2,119 @@ package repos
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/sync/errgroup"
 
 	"LegoManagerAPI/internal/models"
 )
 
+// uniqueViolation is the Postgres error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err was caused by a unique constraint violation
+func isUniqueViolation(err error) bool {
+	_, ok := uniqueViolationConstraint(err)
+	return ok
+}
+
+// uniqueViolationConstraint returns the name of the unique constraint err violated, e.g. users_email_key
+func uniqueViolationConstraint(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+// ErrSoftDeleteUnsupported is returned by SoftDelete on repositories whose table has no deleted_at column
+var ErrSoftDeleteUnsupported = errors.New("soft delete not supported")
+
+// includeDeletedKey is the context key set by IncludeDeleted
+type includeDeletedKey struct{}
+
+// IncludeDeleted returns a context in which queries also return soft-deleted rows, e.g. for admin views
+func IncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// includesDeleted reports whether ctx was created with IncludeDeleted
+func includesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
 // BaseRepository provides common repository utilities and database access
 // specific repositories should embed this and implement their own crud operations
-type BaseRepository[T models.Model] struct {
+// PT is *T, see models.ModelPtr
+type BaseRepository[T any, PT models.ModelPtr[T]] struct {
 	db        *pgxpool.Pool
 	tableName string
+
+	// softDelete is set for tables with a nullable deleted_at column, see NewSoftDeleteRepository
+	softDelete bool
+
+	// retry is the policy queries wrapped in Retry use, see SetRetryPolicy
+	retry RetryPolicy
+
+	// immutable are the table's columns Update skips besides immutableColumns, see SetImmutableColumns
+	immutable map[string]bool
 }
 
 // NewBaseRepository creates a new BaseRepository
-func NewBaseRepository[T models.Model](db *pgxpool.Pool, tableName string) *BaseRepository[T] {
-	return &BaseRepository[T]{
+func NewBaseRepository[T any, PT models.ModelPtr[T]](db *pgxpool.Pool, tableName string) *BaseRepository[T, PT] {
+	return &BaseRepository[T, PT]{
 		db:        db,
 		tableName: tableName,
 	}
 }
 
+// NewSoftDeleteRepository creates a BaseRepository for a table with a nullable deleted_at column
+// Its generic queries skip soft-deleted rows unless the context comes from IncludeDeleted
+func NewSoftDeleteRepository[T any, PT models.ModelPtr[T]](db *pgxpool.Pool, tableName string) *BaseRepository[T, PT] {
+	r := NewBaseRepository[T, PT](db, tableName)
+	r.softDelete = true
+	return r
+}
+
+// whereNotDeleted returns a WHERE clause hiding soft-deleted rows, or "" when they should be returned
+// Embedding repositories append it to their own queries that have no WHERE clause
+func (r *BaseRepository[T, PT]) whereNotDeleted(ctx context.Context) string {
+	if !r.softDelete || includesDeleted(ctx) {
+		return ""
+	}
+	return " WHERE deleted_at IS NULL"
+}
+
+// andNotDeleted is whereNotDeleted for queries that already have a WHERE clause
+func (r *BaseRepository[T, PT]) andNotDeleted(ctx context.Context) string {
+	if !r.softDelete || includesDeleted(ctx) {
+		return ""
+	}
+	return " AND deleted_at IS NULL"
+}
+
 // DB returns the underlying database connection
-func (r *BaseRepository[T]) DB() *pgxpool.Pool {
+func (r *BaseRepository[T, PT]) DB() *pgxpool.Pool {
 	return r.db
 }
 
 // Tablename returns the table name for the model
-func (r *BaseRepository[T]) Tablename() string {
+func (r *BaseRepository[T, PT]) Tablename() string {
 	return r.tableName
 }
 
 // Count returns the total number of entities in the table
-func (r *BaseRepository[T]) Count(ctx context.Context) (int, error) {
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", r.tableName)
+func (r *BaseRepository[T, PT]) Count(ctx context.Context) (int, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", r.tableName, r.whereNotDeleted(ctx))
 	var count int64
 	err := r.db.QueryRow(ctx, query).Scan(&count)
 	if err != nil {
@@ -50,7 +125,7 @@ func (r *BaseRepository[T]) Count(ctx context.Context) (int, error) {
 }
 
 // Delete removes an entity by ID
-func (r *BaseRepository[T]) Delete(ctx context.Context, id int64) error {
+func (r *BaseRepository[T, PT]) Delete(ctx context.Context, id int64) error {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.tableName)
 
 	result, err := r.db.Exec(ctx, query, id)
@@ -66,9 +141,149 @@ func (r *BaseRepository[T]) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// immutableColumns are never written by Update, deleted_at only changes through SoftDelete
+// Repositories add their own with SetImmutableColumns
+var immutableColumns = map[string]bool{"id": true, "created_at": true, "deleted_at": true}
+
+// SetImmutableColumns adds columns of this repository's table that Update never writes,
+// e.g. state that only changes through dedicated queries
+func (r *BaseRepository[T, PT]) SetImmutableColumns(columns ...string) {
+	if r.immutable == nil {
+		r.immutable = make(map[string]bool, len(columns))
+	}
+	for _, column := range columns {
+		r.immutable[column] = true
+	}
+}
+
+// Update writes every db-tagged column of the entity except id and created_at,
+// sets updated_at to NOW() and scans the new value back into the entity
+// Returns an error wrapping pgx.ErrNoRows when no row has the entity's id
+func (r *BaseRepository[T, PT]) Update(ctx context.Context, entity *T) error {
+	query, args, updatedAt, err := buildUpdate(r.tableName, entity, r.andNotDeleted(ctx), r.immutable)
+	if err != nil {
+		return err
+	}
+
+	id := PT(entity).GetID()
+	err = r.db.QueryRow(ctx, query, append(args, id)...).Scan(updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("entity with id %d not found in %s: %w", id, r.tableName, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update entity in %s: %w", r.tableName, err)
+	}
+
+	log.Debug("Entity updated", "table", r.tableName, "id", id)
+	return nil
+}
+
+// buildUpdate builds a parameterized UPDATE for entity from its db tags
+// Column names come only from struct tags, never from entity values
+// The id placeholder is last, after args, and updatedAt points at the entity's updated_at field
+// extraWhere is appended to the id condition, e.g. " AND deleted_at IS NULL"
+// immutable lists the repository's columns to skip on top of immutableColumns, it may be nil
+func buildUpdate(tableName string, entity any, extraWhere string, immutable map[string]bool) (string, []any, *time.Time, error) {
+	value := reflect.ValueOf(entity)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return "", nil, nil, fmt.Errorf("update %s: entity must be a pointer to a struct, got %T", tableName, entity)
+	}
+
+	var (
+		sets      []string
+		args      []any
+		updatedAt *time.Time
+	)
+
+	for _, field := range dbFields(value.Elem()) {
+		switch {
+		case immutableColumns[field.column], immutable[field.column]:
+			continue
+		case field.column == "updated_at":
+			updatedAt, _ = field.value.Addr().Interface().(*time.Time)
+			continue
+		}
+
+		args = append(args, field.value.Interface())
+		sets = append(sets, fmt.Sprintf("%s = $%d", field.column, len(args)))
+	}
+
+	if updatedAt == nil {
+		return "", nil, nil, fmt.Errorf("update %s: %T has no updated_at time field", tableName, entity)
+	}
+	if len(sets) == 0 {
+		return "", nil, nil, fmt.Errorf("update %s: %T has no updatable columns", tableName, entity)
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s, updated_at = NOW() WHERE id = $%d%s RETURNING updated_at",
+		tableName, strings.Join(sets, ", "), len(args)+1, extraWhere,
+	)
+
+	return query, args, updatedAt, nil
+}
+
+// dbField is a db-tagged struct field and the column it maps to
+type dbField struct {
+	column string
+	value  reflect.Value
+}
+
+// dbFields returns the db-tagged fields of a struct in declaration order,
+// descending into embedded structs such as models.BaseModel
+func dbFields(value reflect.Value) []dbField {
+	var fields []dbField
+
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, dbFields(value.Field(i))...)
+			continue
+		}
+
+		column := field.Tag.Get("db")
+		if column == "" || column == "-" {
+			continue
+		}
+
+		fields = append(fields, dbField{column: column, value: value.Field(i)})
+	}
+
+	return fields
+}
+
+// SoftDelete marks an entity deleted by setting deleted_at, keeping the row
+// Returns ErrSoftDeleteUnsupported unless the repository was created with NewSoftDeleteRepository,
+// and an error wrapping pgx.ErrNoRows when no live row has the id
+func (r *BaseRepository[T, PT]) SoftDelete(ctx context.Context, id int64) error {
+	if !r.softDelete {
+		return fmt.Errorf("%w: %s", ErrSoftDeleteUnsupported, r.tableName)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL", r.tableName)
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to soft delete entity from %s: %w", r.tableName, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("entity with id %d not found in %s: %w", id, r.tableName, pgx.ErrNoRows)
+	}
+
+	log.Debug("Entity soft deleted", "table", r.tableName, "id", id)
+	return nil
+}
+
 // Exists checks if an entity with the given ID exists
-func (r *BaseRepository[T]) Exists(ctx context.Context, id int64) (bool, error) {
-	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE id = $1)", r.tableName)
+func (r *BaseRepository[T, PT]) Exists(ctx context.Context, id int64) (bool, error) {
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE id = $1%s)", r.tableName, r.andNotDeleted(ctx))
 
 	var exists bool
 	err := r.db.QueryRow(ctx, query, id).Scan(&exists)
@@ -79,13 +294,86 @@ func (r *BaseRepository[T]) Exists(ctx context.Context, id int64) (bool, error)
 	return exists, nil
 }
 
+// ListAfter returns up to limit entities following cursor in (created_at DESC, id DESC) order,
+// along with the cursor of the next page, "" once the last page is reached
+// An empty cursor starts at the newest row. columns and scan must select and read the same fields
+func (r *BaseRepository[T, PT]) ListAfter(
+	ctx context.Context,
+	columns string,
+	cursor string,
+	limit int,
+	scan func(row pgx.Row) (*T, error),
+) ([]*T, string, error) {
+	query, args, err := r.buildListAfter(ctx, columns, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list %s: %w", r.tableName, err)
+	}
+	defer rows.Close()
+
+	var items []*T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan %s row: %w", r.tableName, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to list %s: %w", r.tableName, err)
+	}
+
+	// One extra row was fetched to tell whether another page follows
+	if len(items) <= limit {
+		return items, "", nil
+	}
+	items = items[:limit]
+
+	last := PT(items[limit-1])
+	return items, EncodeCursor(Cursor{CreatedAt: last.GetCreatedAt(), ID: last.GetID()}), nil
+}
+
+// buildListAfter builds the keyset query for ListAfter, fetching limit+1 rows
+func (r *BaseRepository[T, PT]) buildListAfter(ctx context.Context, columns, cursor string, limit int) (string, []any, error) {
+	if cursor == "" {
+		query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY created_at DESC, id DESC LIMIT $1",
+			columns, r.tableName, r.whereNotDeleted(ctx))
+		return query, []any{limit + 1}, nil
+	}
+
+	after, err := DecodeCursor(cursor)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE (created_at, id) < ($1, $2)%s ORDER BY created_at DESC, id DESC LIMIT $3",
+		columns, r.tableName, r.andNotDeleted(ctx))
+	return query, []any{after.CreatedAt, after.ID, limit + 1}, nil
+}
+
 // WithTransaction executes a function within a database transaction
 // If the function returns an error, the transactio is rolled back
 // Otherwise it's commited
-func (r *BaseRepository[T]) WithTransaction(ctx context.Context, fn func(tx pgx.Tx) error) error {
-	tx, err := r.db.Begin(ctx)
+func (r *BaseRepository[T, PT]) WithTransaction(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	_, err := WithTransactionResult(ctx, r.db, func(tx pgx.Tx) (struct{}, error) {
+		return struct{}{}, fn(tx)
+	})
+	return err
+}
+
+// WithTransactionResult is WithTransaction for functions producing a value, e.g. a created id
+// It is a package-level function because methods can't declare type parameters, call it with r.DB()
+// The result is only returned once the transaction has committed, on error it is R's zero value
+func WithTransactionResult[R any](ctx context.Context, db *pgxpool.Pool, fn func(tx pgx.Tx) (R, error)) (R, error) {
+	var zero R
+
+	tx, err := db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return zero, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	defer func() {
@@ -96,25 +384,27 @@ func (r *BaseRepository[T]) WithTransaction(ctx context.Context, fn func(tx pgx.
 			panic(p) // Re-throw panic after rollback
 		}
 	}()
-	if err := fn(tx); err != nil {
+
+	result, err := fn(tx)
+	if err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
 			log.Error("Failed to rollback transaction", "error", rbErr)
-			return fmt.Errorf("transaction error: %w (rollback also failed: %v)", err, rbErr)
+			return zero, fmt.Errorf("transaction error: %w (rollback also failed: %v)", err, rbErr)
 		}
-		return err
+		return zero, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return zero, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
 
 // BatchOperation executes a function for each item concurrenlty using go-routines
 // maxConcurrency limits the number of concurrent operations
 // This is useful for bulk operations that don't need to be in a transaction
-func (r *BaseRepository[T]) BatchOperation(
+func (r *BaseRepository[T, PT]) BatchOperation(
 	ctx context.Context,
 	items []T,
 	maxConcurrency int,
@@ -148,7 +438,7 @@ func (r *BaseRepository[T]) BatchOperation(
 
 // BatchOperationWithResults executes a function for each item concurrently and collects results
 // This is useful when we need to process items and gather their results
-func (r *BaseRepository[T]) BatchOperationWithResults(
+func (r *BaseRepository[T, PT]) BatchOperationWithResults(
 	ctx context.Context,
 	items []T,
 	maxConcurrency int,
@@ -188,7 +478,7 @@ func (r *BaseRepository[T]) BatchOperationWithResults(
 
 // ConcurrentFetch fetches multiple items by IDs concurrently
 // The fetch function should retrieve a single item by ID
-func (r *BaseRepository[T]) ConcurrentFetch(
+func (r *BaseRepository[T, PT]) ConcurrentFetch(
 	ctx context.Context,
 	ids []int64,
 	maxConcurrency int,
@@ -228,7 +518,7 @@ func (r *BaseRepository[T]) ConcurrentFetch(
 }
 
 // BulkDelete deletes multiple entities by IDs concurrently
-func (r *BaseRepository[T]) BulkDelete(ctx context.Context, ids []int64, maxConcurrency int) error {
+func (r *BaseRepository[T, PT]) BulkDelete(ctx context.Context, ids []int64, maxConcurrency int) error {
 	if len(ids) == 0 {
 		return nil
 	}
@@ -257,7 +547,7 @@ func (r *BaseRepository[T]) BulkDelete(ctx context.Context, ids []int64, maxConc
 
 // ExecuteInBatches splits a large slice into batches and processes each batch
 // This is useful for very large operations to avoid overwhelming the database
-func (r *BaseRepository[T]) ExecuteInBatches(
+func (r *BaseRepository[T, PT]) ExecuteInBatches(
 	ctx context.Context,
 	items []T,
 	batchSize int,
@@ -287,6 +577,6 @@ func (r *BaseRepository[T]) ExecuteInBatches(
 }
 
 // Ping checks if the database connection is alive
-func (r *BaseRepository[T]) Ping(ctx context.Context) error {
+func (r *BaseRepository[T, PT]) Ping(ctx context.Context) error {
 	return r.db.Ping(ctx)
 }
@@ -0,0 +1,292 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"LegoManagerAPI/internal/models"
+)
+
+// collectionColumns is the column list selected by every collection query, in the order scanCollection expects
+const collectionColumns = "id, user_id, item_type, item_id, quantity, condition, purchase_price, purchase_date, notes, archived, archived_at, created_at, updated_at"
+
+// collectionItemConstraint is the unique constraint on (user_id, item_type, item_id, condition)
+const collectionItemConstraint = "collection_items_user_item_key"
+
+var (
+	// ErrCollectionItemNotFound is returned when no collection item matches the given id
+	ErrCollectionItemNotFound = errors.New("collection item not found")
+
+	// ErrCollectionItemExists is returned when the user already owns the item in the same condition,
+	// the existing entry's quantity should be changed instead
+	ErrCollectionItemExists = errors.New("collection item already exists")
+
+	// ErrCollectionItemArchived is returned when changing or deleting an archived item, it must be unarchived first
+	ErrCollectionItemArchived = errors.New("collection item is archived")
+)
+
+// CollectionRepository handles the items users own
+type CollectionRepository struct {
+	*BaseRepository[models.Collection, *models.Collection]
+}
+
+// NewCollectionRepository creates a new Collection repository
+func NewCollectionRepository(db *pgxpool.Pool) *CollectionRepository {
+	r := &CollectionRepository{
+		BaseRepository: NewBaseRepository[models.Collection](db, "collection_items"),
+	}
+	// The archive state only changes through Archive and Unarchive
+	r.SetImmutableColumns("archived", "archived_at")
+	return r
+}
+
+// Create inserts a new collection item, transient database errors are retried
+func (r *CollectionRepository) Create(ctx context.Context, item *models.Collection) error {
+	query := `
+		INSERT INTO collection_items (user_id, item_type, item_id, quantity, condition, purchase_price, purchase_date, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		return r.DB().QueryRow(
+			ctx,
+			query,
+			item.UserID,
+			item.ItemType,
+			item.ItemID,
+			item.Quantity,
+			item.Condition,
+			item.PurchasePrice,
+			item.PurchaseDate,
+			item.Notes,
+		).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	})
+
+	if exists := collectionExistsError(err, item); exists != nil {
+		return exists
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create collection item: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a collection item by ID, transient database errors are retried
+func (r *CollectionRepository) FindByID(ctx context.Context, id int64) (*models.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collection_items WHERE id = $1`
+
+	var item *models.Collection
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = scanCollection(r.DB().QueryRow(ctx, query, id))
+		return err
+	})
+
+	if err == pgx.ErrNoRows {
+		return nil, ErrCollectionItemNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find collection item: %w", err)
+	}
+
+	return item, nil
+}
+
+// FindByUser returns the items the user owns, newest first, archived items only when includeArchived is set
+func (r *CollectionRepository) FindByUser(ctx context.Context, userID int64, includeArchived bool) ([]*models.Collection, error) {
+	filters := []Filter{{Column: "user_id", Operator: OpEqual, Value: userID}}
+	if !includeArchived {
+		filters = append(filters, Filter{Column: "archived", Operator: OpEqual, Value: false})
+	}
+
+	items, err := r.Find(ctx,
+		filters,
+		QueryOptions{
+			Columns: collectionColumns,
+			OrderBy: []Order{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+		},
+		scanCollection,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find collection of user %d: %w", userID, err)
+	}
+
+	return items, nil
+}
+
+// UserIDs returns the ids of the users owning at least one item that is not archived, in ascending order
+func (r *CollectionRepository) UserIDs(ctx context.Context) ([]int64, error) {
+	query := `SELECT DISTINCT user_id FROM collection_items WHERE NOT archived ORDER BY user_id`
+
+	var ids []int64
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		rows, err := r.DB().Query(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		ids, err = pgx.CollectRows(rows, pgx.RowTo[int64])
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection owners: %w", err)
+	}
+
+	return ids, nil
+}
+
+// FindByUserAndItem returns the user's entries for one catalog item, at most one per condition
+// Archived entries are included, they still hold the item's condition. An empty slice means the user doesn't own the item
+func (r *CollectionRepository) FindByUserAndItem(ctx context.Context, userID int64, itemType, itemID string) ([]*models.Collection, error) {
+	items, err := r.Find(ctx,
+		[]Filter{
+			{Column: "user_id", Operator: OpEqual, Value: userID},
+			{Column: "item_type", Operator: OpEqual, Value: itemType},
+			{Column: "item_id", Operator: OpEqual, Value: itemID},
+		},
+		QueryOptions{
+			Columns: collectionColumns,
+			OrderBy: []Order{{Column: "condition"}},
+		},
+		scanCollection,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s %s in collection of user %d: %w", itemType, itemID, userID, err)
+	}
+
+	return items, nil
+}
+
+// Update modifies an existing collection item, archived items are read-only and return ErrCollectionItemArchived
+// Archived and ArchivedAt are not written, they only change through Archive and Unarchive
+func (r *CollectionRepository) Update(ctx context.Context, item *models.Collection) error {
+	query, args, updatedAt, err := buildUpdate("collection_items", item, " AND NOT archived", r.immutable)
+	if err != nil {
+		return fmt.Errorf("failed to update collection item: %w", err)
+	}
+
+	err = r.DB().QueryRow(ctx, query, append(args, item.ID)...).Scan(updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.readOnlyError(ctx, item.ID)
+	}
+
+	if exists := collectionExistsError(err, item); exists != nil {
+		return exists
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update collection item: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a collection item, unlike users the row is not kept
+// Archived items can't be deleted, they return ErrCollectionItemArchived
+func (r *CollectionRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.DB().Exec(ctx, `DELETE FROM collection_items WHERE id = $1 AND NOT archived`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return r.readOnlyError(ctx, id)
+	}
+
+	return nil
+}
+
+// Archive tucks a collection item away instead of deleting it, it stays readable but can't be changed
+// Archiving an archived item keeps its original ArchivedAt
+func (r *CollectionRepository) Archive(ctx context.Context, id int64) error {
+	query := `
+		UPDATE collection_items
+		SET archived = TRUE, archived_at = COALESCE(archived_at, NOW())
+		WHERE id = $1
+	`
+
+	return r.setArchived(ctx, query, id)
+}
+
+// Unarchive makes an archived collection item active again, unarchiving an active item does nothing
+func (r *CollectionRepository) Unarchive(ctx context.Context, id int64) error {
+	query := `
+		UPDATE collection_items
+		SET archived = FALSE, archived_at = NULL
+		WHERE id = $1
+	`
+
+	return r.setArchived(ctx, query, id)
+}
+
+// setArchived runs an Archive or Unarchive query, transient database errors are retried
+func (r *CollectionRepository) setArchived(ctx context.Context, query string, id int64) error {
+	var rowsAffected int64
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		result, err := r.DB().Exec(ctx, query, id)
+		rowsAffected = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change archive state of collection item %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCollectionItemNotFound
+	}
+
+	return nil
+}
+
+// readOnlyError explains why a write matched no active item with the given id,
+// ErrCollectionItemArchived when the item is archived and ErrCollectionItemNotFound when there is none
+func (r *CollectionRepository) readOnlyError(ctx context.Context, id int64) error {
+	// An item found active again was unarchived after the write was rejected, it still failed for being archived
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	return ErrCollectionItemArchived
+}
+
+// collectionExistsError maps a violation of collectionItemConstraint to ErrCollectionItemExists, nil for any other error
+func collectionExistsError(err error, item *models.Collection) error {
+	constraint, ok := uniqueViolationConstraint(err)
+	if !ok || constraint != collectionItemConstraint {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s (%s)", ErrCollectionItemExists, item.ItemType, item.ItemID, item.Condition)
+}
+
+// scanCollection scans a row selected with collectionColumns into a collection item
+func scanCollection(row pgx.Row) (*models.Collection, error) {
+	var item models.Collection
+	err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.ItemType,
+		&item.ItemID,
+		&item.Quantity,
+		&item.Condition,
+		&item.PurchasePrice,
+		&item.PurchaseDate,
+		&item.Notes,
+		&item.Archived,
+		&item.ArchivedAt,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
@@ -0,0 +1,144 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestCollectionRepository_Lifecycle(t *testing.T) {
+	users := newIntegrationRepo(t)
+	collection := NewCollectionRepository(users.DB())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	owner := &models.User{
+		Username:          fmt.Sprintf("collector-%d", time.Now().UnixNano()),
+		PasswordHash:      "hash",
+		FirstName:         "Col",
+		LastName:          "Lector",
+		PreferredCurrency: "USD",
+	}
+	require.NoError(t, users.Create(ctx, owner))
+	// Deleting the user cascades to their collection
+	t.Cleanup(func() { users.Delete(context.Background(), owner.ID) })
+
+	price := 12.5
+	purchased := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newItem := func(condition string) *models.Collection {
+		return &models.Collection{
+			UserID:        owner.ID,
+			ItemType:      "MINIFIG",
+			ItemID:        "sw0001",
+			Quantity:      1,
+			Condition:     condition,
+			PurchasePrice: &price,
+			PurchaseDate:  &purchased,
+		}
+	}
+
+	item := newItem(models.ConditionNew)
+	require.NoError(t, collection.Create(ctx, item))
+	require.NoError(t, collection.Create(ctx, newItem(models.ConditionUsed)))
+
+	err := collection.Create(ctx, newItem(models.ConditionNew))
+	assert.ErrorIs(t, err, ErrCollectionItemExists)
+
+	owned, err := collection.FindByUserAndItem(ctx, owner.ID, "MINIFIG", "sw0001")
+	require.NoError(t, err)
+	require.Len(t, owned, 2)
+	assert.Equal(t, models.ConditionNew, owned[0].Condition)
+	require.NotNil(t, owned[0].PurchasePrice)
+	assert.Equal(t, price, *owned[0].PurchasePrice)
+	require.NotNil(t, owned[0].PurchaseDate)
+	assert.True(t, purchased.Equal(*owned[0].PurchaseDate))
+
+	item.Quantity = 3
+	item.Notes = "Still sealed"
+	require.NoError(t, collection.Update(ctx, item))
+
+	stored, err := collection.FindByID(ctx, item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Quantity)
+	assert.Equal(t, "Still sealed", stored.Notes)
+
+	all, err := collection.FindByUser(ctx, owner.ID, false)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	require.NoError(t, collection.Delete(ctx, item.ID))
+	assert.ErrorIs(t, collection.Delete(ctx, item.ID), ErrCollectionItemNotFound)
+	_, err = collection.FindByID(ctx, item.ID)
+	assert.ErrorIs(t, err, ErrCollectionItemNotFound)
+
+	none, err := collection.FindByUserAndItem(ctx, owner.ID, "SET", "75192-1")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestCollectionRepository_Archive(t *testing.T) {
+	users := newIntegrationRepo(t)
+	collection := NewCollectionRepository(users.DB())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	owner := &models.User{
+		Username:          fmt.Sprintf("archivist-%d", time.Now().UnixNano()),
+		PasswordHash:      "hash",
+		FirstName:         "Ar",
+		LastName:          "Chivist",
+		PreferredCurrency: "USD",
+	}
+	require.NoError(t, users.Create(ctx, owner))
+	t.Cleanup(func() { users.Delete(context.Background(), owner.ID) })
+
+	active := &models.Collection{UserID: owner.ID, ItemType: "SET", ItemID: "75192-1", Quantity: 1, Condition: models.ConditionNew}
+	old := &models.Collection{UserID: owner.ID, ItemType: "MINIFIG", ItemID: "sw0001", Quantity: 2, Condition: models.ConditionUsed}
+	require.NoError(t, collection.Create(ctx, active))
+	require.NoError(t, collection.Create(ctx, old))
+
+	require.NoError(t, collection.Archive(ctx, old.ID))
+	require.NoError(t, collection.Archive(ctx, old.ID), "archiving twice is fine")
+	assert.ErrorIs(t, collection.Archive(ctx, -1), ErrCollectionItemNotFound)
+
+	stored, err := collection.FindByID(ctx, old.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Archived)
+	require.NotNil(t, stored.ArchivedAt)
+
+	listed, err := collection.FindByUser(ctx, owner.ID, false)
+	require.NoError(t, err)
+	require.Len(t, listed, 1, "archived items are left out by default")
+	assert.Equal(t, active.ID, listed[0].ID)
+
+	listed, err = collection.FindByUser(ctx, owner.ID, true)
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+
+	// Archived items are read-only
+	stored.Quantity = 5
+	assert.ErrorIs(t, collection.Update(ctx, stored), ErrCollectionItemArchived)
+	assert.ErrorIs(t, collection.Delete(ctx, old.ID), ErrCollectionItemArchived)
+	assert.ErrorIs(t, collection.Update(ctx, &models.Collection{BaseModel: models.BaseModel{ID: -1}}), ErrCollectionItemNotFound)
+
+	unchanged, err := collection.FindByID(ctx, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, unchanged.Quantity)
+
+	require.NoError(t, collection.Unarchive(ctx, old.ID))
+	stored.Quantity = 5
+	require.NoError(t, collection.Update(ctx, stored))
+
+	unarchived, err := collection.FindByID(ctx, old.ID)
+	require.NoError(t, err)
+	assert.False(t, unarchived.Archived)
+	assert.Nil(t, unarchived.ArchivedAt)
+	assert.Equal(t, 5, unarchived.Quantity)
+	require.NoError(t, collection.Delete(ctx, old.ID))
+}
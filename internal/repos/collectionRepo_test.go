@@ -0,0 +1,88 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestCollectionExistsError(t *testing.T) {
+	item := &models.Collection{ItemType: "MINIFIG", ItemID: "sw0001", Condition: models.ConditionNew}
+
+	err := collectionExistsError(&pgconn.PgError{Code: "23505", ConstraintName: "collection_items_user_item_key"}, item)
+	assert.ErrorIs(t, err, ErrCollectionItemExists)
+	assert.ErrorContains(t, err, "MINIFIG sw0001 (N)")
+
+	assert.NoError(t, collectionExistsError(&pgconn.PgError{Code: "23505", ConstraintName: "collection_items_pkey"}, item))
+	assert.NoError(t, collectionExistsError(errors.New("connection reset"), item))
+	assert.NoError(t, collectionExistsError(nil, item))
+}
+
+func TestBuildUpdate_Collection(t *testing.T) {
+	price := 4.5
+	item := &models.Collection{UserID: 7, ItemType: "SET", ItemID: "75192-1", Quantity: 2, Condition: models.ConditionUsed, PurchasePrice: &price}
+
+	query, args, updatedAt, err := buildUpdate("collection_items", item, "", NewCollectionRepository(nil).immutable)
+	require.NoError(t, err)
+
+	assert.Equal(t, "UPDATE collection_items SET user_id = $1, item_type = $2, item_id = $3, quantity = $4, condition = $5,"+
+		" purchase_price = $6, purchase_date = $7, notes = $8, updated_at = NOW() WHERE id = $9 RETURNING updated_at", query)
+	assert.Len(t, args, 8)
+	assert.Same(t, &item.UpdatedAt, updatedAt)
+}
+
+func TestBuildFind_CollectionByUserAndItem(t *testing.T) {
+	collection := NewCollectionRepository(nil)
+
+	query, args, err := collection.buildFind(context.Background(),
+		[]Filter{
+			{Column: "user_id", Operator: OpEqual, Value: int64(7)},
+			{Column: "item_type", Operator: OpEqual, Value: "MINIFIG"},
+			{Column: "item_id", Operator: OpEqual, Value: "sw0001"},
+		},
+		QueryOptions{Columns: collectionColumns, OrderBy: []Order{{Column: "condition"}}},
+	)
+	require.NoError(t, err)
+
+	// collection_items has no deleted_at, so no soft delete condition is added
+	assert.Equal(t, "SELECT "+collectionColumns+" FROM collection_items"+
+		" WHERE user_id = $1 AND item_type = $2 AND item_id = $3 ORDER BY condition ASC", query)
+	assert.Equal(t, []any{int64(7), "MINIFIG", "sw0001"}, args)
+}
+
+func TestBuildFind_CollectionByUserExcludesArchived(t *testing.T) {
+	collection := NewCollectionRepository(nil)
+
+	query, args, err := collection.buildFind(context.Background(),
+		[]Filter{
+			{Column: "user_id", Operator: OpEqual, Value: int64(7)},
+			{Column: "archived", Operator: OpEqual, Value: false},
+		},
+		QueryOptions{Columns: collectionColumns},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "SELECT "+collectionColumns+" FROM collection_items WHERE user_id = $1 AND archived = $2", query)
+	assert.Equal(t, []any{int64(7), false}, args)
+}
+
+func TestBuildUpdate_CollectionSkipsArchiveColumns(t *testing.T) {
+	archivedAt := time.Now()
+	item := &models.Collection{ItemType: "SET", ItemID: "75192-1", Quantity: 1, Archived: true, ArchivedAt: &archivedAt}
+
+	query, _, _, err := buildUpdate("collection_items", item, " AND NOT archived", NewCollectionRepository(nil).immutable)
+	require.NoError(t, err)
+
+	// Only Archive and Unarchive change the archive state, and archived rows don't match
+	assert.NotContains(t, query, "archived =")
+	assert.NotContains(t, query, "archived_at")
+	assert.True(t, strings.HasSuffix(query, "WHERE id = $9 AND NOT archived RETURNING updated_at"), query)
+}
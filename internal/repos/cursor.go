@@ -0,0 +1,49 @@
+package repos
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a page in (created_at DESC, id DESC) order
+// Clients only see it encoded, see EncodeCursor
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// EncodeCursor returns the opaque base64 form of c handed out as next_cursor
+func EncodeCursor(c Cursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(encoded string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, fmt.Errorf("%w: missing id", ErrInvalidCursor)
+	}
+
+	var c Cursor
+	if c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return c, nil
+}
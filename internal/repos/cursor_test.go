@@ -0,0 +1,53 @@
+package repos
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	c := Cursor{CreatedAt: time.Date(2026, 1, 1, 12, 0, 0, 123456000, time.UTC), ID: 42}
+
+	decoded, err := DecodeCursor(EncodeCursor(c))
+	require.NoError(t, err)
+	assert.True(t, c.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, c.ID, decoded.ID)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for name, cursor := range map[string]string{
+		"not base64": "!!!",
+		"missing id": base64.RawURLEncoding.EncodeToString([]byte("2026-01-01T12:00:00Z")),
+		"bad time":   base64.RawURLEncoding.EncodeToString([]byte("yesterday|42")),
+		"bad id":     base64.RawURLEncoding.EncodeToString([]byte("2026-01-01T12:00:00Z|x")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := DecodeCursor(cursor)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
+
+func TestBuildListAfter(t *testing.T) {
+	users := NewUserRepository(nil)
+	ctx := context.Background()
+
+	query, args, err := users.buildListAfter(ctx, "id", "", 20)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $1", query)
+	assert.Equal(t, []any{21}, args, "one extra row tells whether another page follows")
+
+	after := Cursor{CreatedAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), ID: 7}
+	query, args, err = users.buildListAfter(ctx, "id", EncodeCursor(after), 20)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE (created_at, id) < ($1, $2) AND deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $3", query)
+	assert.Equal(t, []any{after.CreatedAt, int64(7), 21}, args)
+
+	_, _, err = users.buildListAfter(ctx, "id", "!!!", 20)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,231 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidFilter is returned by Find for filters or orderings it refuses to turn into SQL
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Operator is a comparison Find allows in a Filter
+type Operator string
+
+const (
+	OpEqual          Operator = "="
+	OpNotEqual       Operator = "!="
+	OpLess           Operator = "<"
+	OpGreater        Operator = ">"
+	OpGreaterOrEqual Operator = ">="
+	OpILike          Operator = "ILIKE"
+
+	// OpIn matches any element of a slice value, it is sent as a single array parameter
+	OpIn Operator = "IN"
+)
+
+// allowedOperators is the allow-list of operators Find puts into a query
+var allowedOperators = map[Operator]bool{
+	OpEqual:          true,
+	OpNotEqual:       true,
+	OpLess:           true,
+	OpGreater:        true,
+	OpGreaterOrEqual: true,
+	OpILike:          true,
+	OpIn:             true,
+}
+
+// likeEscaper escapes the LIKE wildcards with Postgres' default escape character, the backslash
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes s match itself literally inside an OpILike pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Filter is one condition of a Find query, filters are combined with AND
+// Column must be a db column of the model, Value is always sent as a parameter
+type Filter struct {
+	Column   string
+	Operator Operator
+	Value    any
+
+	// anyOf holds the alternatives of a filter built with AnyOf
+	anyOf []Filter
+}
+
+// AnyOf returns a filter matching when at least one of filters does
+func AnyOf(filters ...Filter) Filter {
+	return Filter{anyOf: append([]Filter{}, filters...)}
+}
+
+// Order sorts Find results by a db column of the model
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// QueryOptions shapes the result of a Find query
+type QueryOptions struct {
+	// Columns is the select list handed to the scan callback, defaulting to every db column of the model
+	// It is put into the query as-is, so it must never come from user input
+	Columns string
+
+	OrderBy []Order
+
+	// Limit caps the number of rows, 0 returns all of them
+	Limit  int
+	Offset int
+}
+
+// Find returns the entities matching all filters, reading each row with scan
+// Soft-deleted rows are skipped as in the other generic queries. Columns and operators are
+// checked against the model's db tags and the operator allow-list, returning ErrInvalidFilter otherwise
+func (r *BaseRepository[T, PT]) Find(
+	ctx context.Context,
+	filters []Filter,
+	opts QueryOptions,
+	scan func(row pgx.Row) (*T, error),
+) ([]*T, error) {
+	query, args, err := r.buildFind(ctx, filters, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", r.tableName, err)
+	}
+	defer rows.Close()
+
+	var items []*T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", r.tableName, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", r.tableName, err)
+	}
+
+	return items, nil
+}
+
+// buildFind builds the parameterized SELECT for Find
+func (r *BaseRepository[T, PT]) buildFind(ctx context.Context, filters []Filter, opts QueryOptions) (string, []any, error) {
+	columns := modelColumns[T]()
+
+	b := findBuilder{columns: make(map[string]bool, len(columns))}
+	for _, column := range columns {
+		b.columns[column] = true
+	}
+
+	conditions := make([]string, 0, len(filters))
+	for _, filter := range filters {
+		condition, err := b.condition(filter)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	selectList := opts.Columns
+	if selectList == "" {
+		selectList = strings.Join(columns, ", ")
+	}
+
+	var query strings.Builder
+	fmt.Fprintf(&query, "SELECT %s FROM %s", selectList, r.tableName)
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE " + strings.Join(conditions, " AND ") + r.andNotDeleted(ctx))
+	} else {
+		query.WriteString(r.whereNotDeleted(ctx))
+	}
+
+	if len(opts.OrderBy) > 0 {
+		orders := make([]string, len(opts.OrderBy))
+		for i, order := range opts.OrderBy {
+			if !b.columns[order.Column] {
+				return "", nil, fmt.Errorf("%w: unknown column %q", ErrInvalidFilter, order.Column)
+			}
+			orders[i] = order.Column + " ASC"
+			if order.Desc {
+				orders[i] = order.Column + " DESC"
+			}
+		}
+		query.WriteString(" ORDER BY " + strings.Join(orders, ", "))
+	}
+
+	if opts.Limit > 0 {
+		query.WriteString(" LIMIT " + b.param(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.WriteString(" OFFSET " + b.param(opts.Offset))
+	}
+
+	return query.String(), b.args, nil
+}
+
+// modelColumns returns the db columns of T in declaration order
+func modelColumns[T any]() []string {
+	var model T
+	fields := dbFields(reflect.ValueOf(&model).Elem())
+
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.column
+	}
+	return columns
+}
+
+// findBuilder collects the parameters of a Find query while its conditions are rendered
+type findBuilder struct {
+	columns map[string]bool
+	args    []any
+}
+
+// param adds value as the next parameter and returns its placeholder
+func (b *findBuilder) param(value any) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *findBuilder) condition(filter Filter) (string, error) {
+	if filter.anyOf != nil {
+		if len(filter.anyOf) == 0 {
+			return "", fmt.Errorf("%w: empty AnyOf", ErrInvalidFilter)
+		}
+
+		alternatives := make([]string, len(filter.anyOf))
+		for i, alternative := range filter.anyOf {
+			condition, err := b.condition(alternative)
+			if err != nil {
+				return "", err
+			}
+			alternatives[i] = condition
+		}
+		return "(" + strings.Join(alternatives, " OR ") + ")", nil
+	}
+
+	if !b.columns[filter.Column] {
+		return "", fmt.Errorf("%w: unknown column %q", ErrInvalidFilter, filter.Column)
+	}
+	if !allowedOperators[filter.Operator] {
+		return "", fmt.Errorf("%w: operator %q not allowed", ErrInvalidFilter, filter.Operator)
+	}
+
+	if filter.Operator == OpIn {
+		if kind := reflect.ValueOf(filter.Value).Kind(); kind != reflect.Slice && kind != reflect.Array {
+			return "", fmt.Errorf("%w: IN on %s needs a slice value", ErrInvalidFilter, filter.Column)
+		}
+		return filter.Column + " = ANY(" + b.param(filter.Value) + ")", nil
+	}
+
+	return filter.Column + " " + string(filter.Operator) + " " + b.param(filter.Value), nil
+}
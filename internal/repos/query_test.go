@@ -0,0 +1,78 @@
+package repos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestBuildFind(t *testing.T) {
+	users := NewUserRepository(nil)
+	ctx := context.Background()
+
+	query, args, err := users.buildFind(ctx,
+		[]Filter{
+			AnyOf(
+				Filter{Column: "first_name", Operator: OpILike, Value: "%ada%"},
+				Filter{Column: "last_name", Operator: OpILike, Value: "%ada%"},
+			),
+			Filter{Column: "id", Operator: OpIn, Value: []int64{1, 2}},
+			Filter{Column: "preferred_currency", Operator: OpNotEqual, Value: "USD"},
+		},
+		QueryOptions{
+			Columns: "id, username",
+			OrderBy: []Order{{Column: "last_name"}, {Column: "id", Desc: true}},
+			Limit:   10,
+			Offset:  20,
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id, username FROM users"+
+		" WHERE (first_name ILIKE $1 OR last_name ILIKE $2) AND id = ANY($3) AND preferred_currency != $4 AND deleted_at IS NULL"+
+		" ORDER BY last_name ASC, id DESC LIMIT $5 OFFSET $6", query)
+	assert.Equal(t, []any{"%ada%", "%ada%", []int64{1, 2}, "USD", 10, 20}, args)
+}
+
+func TestBuildFind_DefaultsToModelColumns(t *testing.T) {
+	plain := NewBaseRepository[models.User](nil, "users")
+
+	query, args, err := plain.buildFind(context.Background(), nil, QueryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id, created_at, updated_at, username, password_hash, first_name, last_name,"+
+		" preferred_currency, timezone, email, external_id, deleted_at FROM users", query)
+	assert.Empty(t, args)
+}
+
+func TestBuildFind_RejectsUnsafeInput(t *testing.T) {
+	users := NewUserRepository(nil)
+
+	tests := map[string]struct {
+		filters []Filter
+		opts    QueryOptions
+	}{
+		"unknown column":   {filters: []Filter{{Column: "1=1; DROP TABLE users; --", Operator: OpEqual, Value: 1}}},
+		"unknown operator": {filters: []Filter{{Column: "id", Operator: "= 1 OR 1 =", Value: 1}}},
+		"IN without slice": {filters: []Filter{{Column: "id", Operator: OpIn, Value: 1}}},
+		"empty AnyOf":      {filters: []Filter{AnyOf()}},
+		"unknown in AnyOf": {filters: []Filter{AnyOf(Filter{Column: "nope", Operator: OpEqual, Value: 1})}},
+		"unknown order":    {opts: QueryOptions{OrderBy: []Order{{Column: "random()"}}}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := users.buildFind(context.Background(), tt.filters, tt.opts)
+			assert.ErrorIs(t, err, ErrInvalidFilter)
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "plain", escapeLike("plain"))
+	assert.Equal(t, `100\%`, escapeLike("100%"))
+	assert.Equal(t, `first\_name`, escapeLike("first_name"))
+	assert.Equal(t, `a\\b`, escapeLike(`a\b`))
+}
@@ -7,7 +7,7 @@ import (
 )
 
 // Repository is the base interface for all repos
-type Repository[T models.Model] interface {
+type Repository[T any, PT models.ModelPtr[T]] interface {
 	// Basic CRUD
 	Create(ctx context.Context, entity *T) error
 	FindByID(ctx context.Context, id int64) (*T, error)
@@ -17,7 +17,7 @@ type Repository[T models.Model] interface {
 	Count(ctx context.Context) (int, error)
 
 	// Batch operations (uses goroutines internally)
-	CreateBatch(ctx context.Context, entities []*T) error
+	CreateBatch(ctx context.Context, entities []*T, maxConcurrency int) error
 	FindByIDs(ctx context.Context, ids []int64) ([]*T, error)
 }
 
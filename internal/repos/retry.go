@@ -0,0 +1,89 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RetryPolicy controls how Retry handles transient database errors
+// MaxAttempts counts the first try, so 1 or less disables retries
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// retryableCodes are the Postgres error codes worth retrying: serialization failures, deadlocks and
+// the server going away or not accepting connections yet, e.g. during a failover
+var retryableCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// isRetryable reports whether err is a transient failure that can be retried without side effects
+// A connection lost mid-statement is not retried, the statement may already have been applied
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 covers connection exceptions
+		return retryableCodes[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	// pgx reports whether the failure happened before anything reached the server
+	return pgconn.SafeToRetry(err)
+}
+
+// SetRetryPolicy sets the policy used by Retry, the zero value disables retries
+func (r *BaseRepository[T, PT]) SetRetryPolicy(policy RetryPolicy) {
+	r.retry = policy
+}
+
+// Retry runs fn and retries it with exponential backoff while it fails with a transient error
+// Other errors, like unique violations or not found, are returned right away
+// Queries opt in by wrapping their database call, fn must be safe to run more than once
+func (r *BaseRepository[T, PT]) Retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	return retry(ctx, r.retry, fn)
+}
+
+func retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	delay := policy.BaseDelay
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if attempt >= policy.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		log.Warn("Transient database error, retrying", "attempt", attempt, "max_attempts", policy.MaxAttempts, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
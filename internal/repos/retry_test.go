@@ -0,0 +1,106 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"server shutting down", fmt.Errorf("query: %w", &pgconn.PgError{Code: "57P01"}), true},
+		{"connect error", &pgconn.ConnectError{}, true},
+		{"unique violation", &pgconn.PgError{Code: uniqueViolation}, false},
+		{"not found", pgx.ErrNoRows, false},
+		{"user not found", ErrUserNotFound, false},
+		{"cancelled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, false},
+		{"other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	transient := &pgconn.PgError{Code: "40001"}
+
+	t.Run("succeeds after transient errors", func(t *testing.T) {
+		calls := 0
+		err := retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return transient
+		})
+
+		assert.ErrorIs(t, err, transient)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("non-retryable errors pass through immediately", func(t *testing.T) {
+		calls := 0
+		err := retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return &pgconn.PgError{Code: uniqueViolation}
+		})
+
+		assert.True(t, isUniqueViolation(err))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("zero policy does not retry", func(t *testing.T) {
+		calls := 0
+		err := retry(context.Background(), RetryPolicy{}, func(ctx context.Context) error {
+			calls++
+			return transient
+		})
+
+		assert.ErrorIs(t, err, transient)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stops waiting when the context ends", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls := 0
+		err := retry(ctx, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}, func(ctx context.Context) error {
+			calls++
+			return transient
+		})
+
+		assert.ErrorIs(t, err, transient)
+		assert.Equal(t, 1, calls)
+	})
+}
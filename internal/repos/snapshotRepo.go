@@ -0,0 +1,131 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"LegoManagerAPI/internal/models"
+)
+
+// snapshotColumns is the column list selected by every snapshot query, in the order scanSnapshot expects
+const snapshotColumns = "id, user_id, currency, total_value, total_purchase_cost, item_count, unpriced_count, created_at, updated_at"
+
+// SnapshotRepository handles the portfolio value history, snapshots are only ever added
+type SnapshotRepository struct {
+	*BaseRepository[models.PortfolioSnapshot, *models.PortfolioSnapshot]
+}
+
+// NewSnapshotRepository creates a new PortfolioSnapshot repository
+func NewSnapshotRepository(db *pgxpool.Pool) *SnapshotRepository {
+	return &SnapshotRepository{
+		BaseRepository: NewBaseRepository[models.PortfolioSnapshot](db, "portfolio_snapshots"),
+	}
+}
+
+// Create stores a new snapshot taken now, transient database errors are retried
+func (r *SnapshotRepository) Create(ctx context.Context, snapshot *models.PortfolioSnapshot) error {
+	query := `
+		INSERT INTO portfolio_snapshots (user_id, currency, total_value, total_purchase_cost, item_count, unpriced_count, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		return r.DB().QueryRow(
+			ctx,
+			query,
+			snapshot.UserID,
+			snapshot.Currency,
+			snapshot.TotalValue,
+			snapshot.TotalPurchaseCost,
+			snapshot.ItemCount,
+			snapshot.UnpricedCount,
+		).Scan(&snapshot.ID, &snapshot.CreatedAt, &snapshot.UpdatedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create portfolio snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// FindByUser returns the user's snapshots taken from from up to but excluding to, oldest first
+// A zero from or to leaves that end of the range open, both are compared in UTC like the stored timestamps
+func (r *SnapshotRepository) FindByUser(ctx context.Context, userID int64, from, to time.Time) ([]*models.PortfolioSnapshot, error) {
+	filters := []Filter{{Column: "user_id", Operator: OpEqual, Value: userID}}
+	if !from.IsZero() {
+		filters = append(filters, Filter{Column: "created_at", Operator: OpGreaterOrEqual, Value: from.UTC()})
+	}
+	if !to.IsZero() {
+		filters = append(filters, Filter{Column: "created_at", Operator: OpLess, Value: to.UTC()})
+	}
+
+	snapshots, err := r.Find(ctx,
+		filters,
+		QueryOptions{
+			Columns: snapshotColumns,
+			OrderBy: []Order{{Column: "created_at"}, {Column: "id"}},
+		},
+		scanSnapshot,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find portfolio snapshots of user %d: %w", userID, err)
+	}
+
+	return snapshots, nil
+}
+
+// LatestByUser returns when each user's most recent snapshot was taken, users without snapshots are missing
+func (r *SnapshotRepository) LatestByUser(ctx context.Context) (map[int64]time.Time, error) {
+	query := `SELECT user_id, MAX(created_at) FROM portfolio_snapshots GROUP BY user_id`
+
+	var latest map[int64]time.Time
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		rows, err := r.DB().Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		latest = make(map[int64]time.Time)
+		for rows.Next() {
+			var userID int64
+			var takenAt time.Time
+			if err := rows.Scan(&userID, &takenAt); err != nil {
+				return err
+			}
+			latest[userID] = takenAt
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find latest portfolio snapshots: %w", err)
+	}
+
+	return latest, nil
+}
+
+// scanSnapshot scans a row selected with snapshotColumns into a snapshot
+func scanSnapshot(row pgx.Row) (*models.PortfolioSnapshot, error) {
+	var snapshot models.PortfolioSnapshot
+	err := row.Scan(
+		&snapshot.ID,
+		&snapshot.UserID,
+		&snapshot.Currency,
+		&snapshot.TotalValue,
+		&snapshot.TotalPurchaseCost,
+		&snapshot.ItemCount,
+		&snapshot.UnpricedCount,
+		&snapshot.CreatedAt,
+		&snapshot.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
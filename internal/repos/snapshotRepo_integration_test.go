@@ -0,0 +1,55 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestSnapshotRepository_History(t *testing.T) {
+	users := newIntegrationRepo(t)
+	snapshots := NewSnapshotRepository(users.DB())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	owner := &models.User{
+		Username:          fmt.Sprintf("historian-%d", time.Now().UnixNano()),
+		PasswordHash:      "hash",
+		FirstName:         "His",
+		LastName:          "Torian",
+		PreferredCurrency: "EUR",
+	}
+	require.NoError(t, users.Create(ctx, owner))
+	// Deleting the user cascades to their snapshots
+	t.Cleanup(func() { users.Delete(context.Background(), owner.ID) })
+
+	first := &models.PortfolioSnapshot{UserID: owner.ID, Currency: "EUR", TotalValue: 10.5, TotalPurchaseCost: 7, ItemCount: 2}
+	second := &models.PortfolioSnapshot{UserID: owner.ID, Currency: "EUR", TotalValue: 12.25, TotalPurchaseCost: 7, ItemCount: 2, UnpricedCount: 1}
+	require.NoError(t, snapshots.Create(ctx, first))
+	require.NoError(t, snapshots.Create(ctx, second))
+
+	all, err := snapshots.FindByUser(ctx, owner.ID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, first.ID, all[0].ID, "oldest first")
+	assert.Equal(t, 12.25, all[1].TotalValue)
+	assert.Equal(t, 1, all[1].UnpricedCount)
+
+	fromSecond, err := snapshots.FindByUser(ctx, owner.ID, second.CreatedAt, time.Time{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, fromSecond)
+
+	none, err := snapshots.FindByUser(ctx, owner.ID, time.Time{}, first.CreatedAt)
+	require.NoError(t, err)
+	assert.Empty(t, none, "to is exclusive")
+
+	latest, err := snapshots.LatestByUser(ctx)
+	require.NoError(t, err)
+	assert.True(t, latest[owner.ID].Equal(second.CreatedAt), "latest is the newest snapshot")
+}
@@ -0,0 +1,30 @@
+package repos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFind_SnapshotsInRange(t *testing.T) {
+	snapshots := NewSnapshotRepository(nil)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	query, args, err := snapshots.buildFind(context.Background(),
+		[]Filter{
+			{Column: "user_id", Operator: OpEqual, Value: int64(7)},
+			{Column: "created_at", Operator: OpGreaterOrEqual, Value: from},
+			{Column: "created_at", Operator: OpLess, Value: to},
+		},
+		QueryOptions{Columns: snapshotColumns, OrderBy: []Order{{Column: "created_at"}, {Column: "id"}}},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "SELECT "+snapshotColumns+" FROM portfolio_snapshots"+
+		" WHERE user_id = $1 AND created_at >= $2 AND created_at < $3 ORDER BY created_at ASC, id ASC", query)
+	assert.Equal(t, []any{int64(7), from, to}, args)
+}
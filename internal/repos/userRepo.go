@@ -2,7 +2,10 @@ package repos
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,26 +14,97 @@ import (
 	"LegoManagerAPI/internal/models"
 )
 
+// userColumns is the column list selected by every user query, in the order scanUser expects
+const userColumns = "id, username, password_hash, first_name, last_name, preferred_currency, timezone, email, external_id, created_at, updated_at, deleted_at"
+
+// emailConstraint is the unique constraint Postgres names for users.email
+const emailConstraint = "users_email_key"
+
+var (
+	// ErrUserNotFound is returned when no user matches the given id or username
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUsernameTaken is returned when creating a user whose username already exists
+	ErrUsernameTaken = errors.New("username already exists")
+
+	// ErrEmailTaken is returned when storing a user whose email belongs to another user
+	ErrEmailTaken = errors.New("email already exists")
+
+	// ErrUserDeleted is returned when syncing a user whose external id belongs to a soft deleted user
+	ErrUserDeleted = errors.New("user is deleted")
+)
+
 // UserRepository handles user data operations
 type UserRepository struct {
-	*BaseRepository[models.User] // Non-pointer generic
+	*BaseRepository[models.User, *models.User]
 }
 
 // NewUserRepository creates a new User repository
+// Users are soft deleted so their portfolio history survives, lookups skip them unless IncludeDeleted is used
 func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 	return &UserRepository{
-		BaseRepository: NewBaseRepository[models.User](db, "users"),
+		BaseRepository: NewSoftDeleteRepository[models.User](db, "users"),
 	}
 }
 
-// Create inserts a new user
+// insertUserQuery inserts one user, its parameters come from insertUserArgs
+const insertUserQuery = `
+	INSERT INTO users (username, password_hash, first_name, last_name, preferred_currency, timezone, email, external_id, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+	RETURNING id, created_at, updated_at
+`
+
+// insertUserArgs returns the parameters of insertUserQuery for user
+func insertUserArgs(user *models.User) []any {
+	return []any{
+		user.Username,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.PreferredCurrency,
+		user.Timezone,
+		user.Email,
+		user.ExternalID,
+	}
+}
+
+// Create inserts a new user, transient database errors are retried
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		return r.DB().QueryRow(ctx, insertUserQuery, insertUserArgs(user)...).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	})
+
+	if taken := takenError(err, user); taken != nil {
+		return taken
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertByExternalID inserts the user or, when a user with the same external id exists, updates its profile
+// The password hash is only set on insert, so re-running a sync never resets a password
+// A soft deleted user is left alone and ErrUserDeleted returned, a sync never brings an account back
+// Returns whether a new user was created
+func (r *UserRepository) UpsertByExternalID(ctx context.Context, user *models.User) (bool, error) {
+	if user.ExternalID == nil || *user.ExternalID == "" {
+		return false, fmt.Errorf("upsert requires an external id")
+	}
+
 	query := `
-		INSERT INTO users (username, password_hash, first_name, last_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		INSERT INTO users (username, password_hash, first_name, last_name, preferred_currency, timezone, email, external_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		ON CONFLICT (external_id) DO UPDATE
+		SET username = EXCLUDED.username, first_name = EXCLUDED.first_name, last_name = EXCLUDED.last_name,
+			preferred_currency = EXCLUDED.preferred_currency, timezone = EXCLUDED.timezone, email = EXCLUDED.email, updated_at = NOW()
+		WHERE users.deleted_at IS NULL
+		RETURNING id, created_at, updated_at, (xmax = 0) AS inserted
 	`
 
+	var inserted bool
 	err := r.DB().QueryRow(
 		ctx,
 		query,
@@ -38,88 +112,77 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		user.PasswordHash,
 		user.FirstName,
 		user.LastName,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		user.PreferredCurrency,
+		user.Timezone,
+		user.Email,
+		user.ExternalID,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &inserted)
+
+	// The username or email can still clash with a different user
+	if taken := takenError(err, user); taken != nil {
+		return false, taken
+	}
+
+	// The conflicting row was soft deleted, so the update was skipped and nothing returned
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrUserDeleted
+	}
 
 	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		return false, fmt.Errorf("failed to upsert user: %w", err)
 	}
 
-	return nil
+	return inserted, nil
 }
 
-// FindByID retrieves a user by ID
+// FindByID retrieves a user by ID, transient database errors are retried
 func (r *UserRepository) FindByID(ctx context.Context, id int64) (*models.User, error) {
-	query := `SELECT id, username, password_hash, first_name, last_name, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1` + r.andNotDeleted(ctx)
 
-	var user models.User
-	err := r.DB().QueryRow(ctx, query, id).Scan(
-		&user.ID,
-		&user.Username,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	var user *models.User
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = scanUser(r.DB().QueryRow(ctx, query, id))
+		return err
+	})
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // FindByUsername retrieves a user by username
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
-	query := `SELECT id, username, password_hash, first_name, last_name, created_at, updated_at FROM users WHERE username = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1` + r.andNotDeleted(ctx)
 
-	var user models.User
-	err := r.DB().QueryRow(ctx, query, username).Scan(
-		&user.ID,
-		&user.Username,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.DB().QueryRow(ctx, query, username))
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user by username: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // Update modifies an existing user
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
-	query := `
-		UPDATE users
-		SET username = $1, password_hash = $2, first_name = $3, last_name = $4, updated_at = NOW()
-		WHERE id = $5
-		RETURNING updated_at
-	`
-
-	err := r.DB().QueryRow(
-		ctx,
-		query,
-		user.Username,
-		user.PasswordHash,
-		user.FirstName,
-		user.LastName,
-		user.ID,
-	).Scan(&user.UpdatedAt)
+	err := r.BaseRepository.Update(ctx, user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
 
-	if err == pgx.ErrNoRows {
-		return fmt.Errorf("user not found")
+	if taken := takenError(err, user); taken != nil {
+		return taken
 	}
 
 	if err != nil {
@@ -129,13 +192,22 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// SoftDelete marks the user deleted, the row and its history are kept
+func (r *UserRepository) SoftDelete(ctx context.Context, id int64) error {
+	err := r.BaseRepository.SoftDelete(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+
+	return err
+}
+
 // UpdatePassword updates only the user's password hash
 func (r *UserRepository) UpdatePassword(ctx context.Context, userID int64, newPasswordHash string) error {
 	query := `
 		UPDATE users
 		SET password_hash = $1, updated_at = NOW()
-		WHERE id = $2
-	`
+		WHERE id = $2` + r.andNotDeleted(ctx)
 
 	result, err := r.DB().Exec(ctx, query, newPasswordHash, userID)
 	if err != nil {
@@ -143,18 +215,57 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, userID int64, newPa
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	return nil
 }
 
-// List retrieves users with pagination
-func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
+// userSortColumns allow-lists the fields List can sort by, mapped to their column
+// Only these values ever reach the ORDER BY clause
+var userSortColumns = map[string]string{
+	"username":   "username",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"created_at": "created_at",
+}
+
+// UserSort is a validated sort order for List, build it with ParseUserSort
+// The zero value sorts newest first
+type UserSort struct {
+	column string
+	desc   bool
+}
+
+// ParseUserSort parses a sort param such as "username" or "-created_at", a leading - sorts descending
+// Unknown fields fall back to the default newest-first order, ok reports whether value was valid
+func ParseUserSort(value string) (sort UserSort, ok bool) {
+	field, desc := strings.CutPrefix(value, "-")
+	column, ok := userSortColumns[field]
+	if !ok {
+		return UserSort{}, false
+	}
+	return UserSort{column: column, desc: desc}, true
+}
+
+// orderBy returns the ORDER BY expressions, id breaks ties so pages don't overlap
+func (s UserSort) orderBy() string {
+	if s.column == "" {
+		return "created_at DESC, id DESC"
+	}
+	direction := "ASC"
+	if s.desc {
+		direction = "DESC"
+	}
+	return s.column + " " + direction + ", id " + direction
+}
+
+// List retrieves users with pagination in the given order
+func (r *UserRepository) List(ctx context.Context, limit, offset int, sort UserSort) ([]*models.User, error) {
 	query := `
-		SELECT id, username, password_hash, first_name, last_name, created_at, updated_at
-		FROM users
-		ORDER BY created_at DESC
+		SELECT ` + userColumns + `
+		FROM users` + r.whereNotDeleted(ctx) + `
+		ORDER BY ` + sort.orderBy() + `
 		LIMIT $1 OFFSET $2
 	`
 
@@ -166,26 +277,27 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*models
 
 	var users []*models.User
 	for rows.Next() {
-		var user models.User
-		err := rows.Scan(
-			&user.ID,
-			&user.Username,
-			&user.PasswordHash,
-			&user.FirstName,
-			&user.LastName,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		users = append(users, &user)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
 	return users, nil
 }
 
+// ListAfter returns the page of users following cursor, newest first, and the next page's cursor
+// Unlike List, pages stay consistent while users are created; returns ErrInvalidCursor for a bad cursor
+func (r *UserRepository) ListAfter(ctx context.Context, cursor string, limit int) ([]*models.User, string, error) {
+	return r.BaseRepository.ListAfter(ctx, userColumns, cursor, limit, scanUser)
+}
+
 // UsernameExists checks if a username is already taken
+// Soft-deleted users keep their username, so they are included
 func (r *UserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
 
@@ -198,50 +310,66 @@ func (r *UserRepository) UsernameExists(ctx context.Context, username string) (b
 	return exists, nil
 }
 
-// SearchByName searches users by first or last name
-func (r *UserRepository) SearchByName(ctx context.Context, searchTerm string) ([]*models.User, error) {
-	query := `
-		SELECT id, username, password_hash, first_name, last_name, created_at, updated_at
-		FROM users
-		WHERE first_name ILIKE $1 OR last_name ILIKE $1
-		ORDER BY first_name ASC, last_name ASC
-	`
+// EmailExists checks if an email is already taken, emails are compared as stored (lowercase)
+// Soft-deleted users keep their email, so they are included
+func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
 
-	rows, err := r.DB().Query(ctx, query, "%"+searchTerm+"%")
+	var exists bool
+	err := r.DB().QueryRow(ctx, query, email).Scan(&exists)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search users: %w", err)
+		return false, fmt.Errorf("failed to check email existence: %w", err)
 	}
-	defer rows.Close()
 
-	var users []*models.User
-	for rows.Next() {
-		var user models.User
-		err := rows.Scan(
-			&user.ID,
-			&user.Username,
-			&user.PasswordHash,
-			&user.FirstName,
-			&user.LastName,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan user: %w", err)
-		}
-		users = append(users, &user)
+	return exists, nil
+}
+
+// takenError maps a unique violation to ErrEmailTaken or ErrUsernameTaken, nil for any other error
+func takenError(err error, user *models.User) error {
+	constraint, ok := uniqueViolationConstraint(err)
+	if !ok {
+		return nil
+	}
+	if constraint == emailConstraint && user.Email != nil {
+		return fmt.Errorf("%w: %s", ErrEmailTaken, *user.Email)
+	}
+	return fmt.Errorf("%w: %s", ErrUsernameTaken, user.Username)
+}
+
+// SearchByName searches users by first or last name, searchTerm is matched literally
+func (r *UserRepository) SearchByName(ctx context.Context, searchTerm string) ([]*models.User, error) {
+	pattern := "%" + escapeLike(searchTerm) + "%"
+
+	users, err := r.Find(ctx,
+		[]Filter{AnyOf(
+			Filter{Column: "first_name", Operator: OpILike, Value: pattern},
+			Filter{Column: "last_name", Operator: OpILike, Value: pattern},
+		)},
+		QueryOptions{
+			Columns: userColumns,
+			OrderBy: []Order{{Column: "first_name"}, {Column: "last_name"}},
+		},
+		scanUser,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
 
 	return users, nil
 }
 
-// CreateBatch creates multiple users (useful for seeding/importing)
-func (r *UserRepository) CreateBatch(ctx context.Context, users []*models.User) error {
+// CreateBatch creates multiple users with up to maxConcurrency concurrent inserts (useful for seeding/importing)
+// It is not atomic: users inserted before a failure stay committed. Use CreateBatchAtomic for all-or-nothing
+func (r *UserRepository) CreateBatch(ctx context.Context, users []*models.User, maxConcurrency int) error {
 	if len(users) == 0 {
 		return nil
 	}
+	if maxConcurrency < 1 {
+		maxConcurrency = 10
+	}
 
 	g, gCtx := errgroup.WithContext(ctx)
-	sem := make(chan struct{}, 10) // Max 10 concurrent
+	sem := make(chan struct{}, maxConcurrency)
 
 	for _, user := range users {
 		user := user // Capture
@@ -257,6 +385,104 @@ func (r *UserRepository) CreateBatch(ctx context.Context, users []*models.User)
 	return g.Wait()
 }
 
+// CreateBatchAtomic inserts all users in one transaction, sent to Postgres as a single pgx.Batch round trip
+// Any failure rolls back the whole batch, e.g. a taken username returns ErrUsernameTaken and inserts nothing.
+// IDs and timestamps are only set on the users once the transaction has committed
+func (r *UserRepository) CreateBatchAtomic(ctx context.Context, users []*models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	inserted, err := WithTransactionResult(ctx, r.DB(), func(tx pgx.Tx) ([]models.BaseModel, error) {
+		batch := &pgx.Batch{}
+		for _, user := range users {
+			batch.Queue(insertUserQuery, insertUserArgs(user)...)
+		}
+
+		results := tx.SendBatch(ctx, batch)
+		defer results.Close()
+
+		inserted := make([]models.BaseModel, len(users))
+		for i, user := range users {
+			err := results.QueryRow().Scan(&inserted[i].ID, &inserted[i].CreatedAt, &inserted[i].UpdatedAt)
+			if taken := takenError(err, user); taken != nil {
+				return nil, taken
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to create user %s: %w", user.Username, err)
+			}
+		}
+
+		return inserted, results.Close()
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, user := range users {
+		user.BaseModel = inserted[i]
+	}
+
+	return nil
+}
+
+// BatchFailure records why a user in a tolerant batch was not created
+type BatchFailure struct {
+	Username string
+	Err      error
+}
+
+// BatchCreateResult summarizes a CreateBatchTolerant run
+type BatchCreateResult struct {
+	Created []*models.User
+	Skipped []BatchFailure // Username or email already existed
+	Failed  []BatchFailure
+}
+
+// CreateBatchTolerant creates each user independently, unlike CreateBatch one failure does not abort the rest
+// Existing usernames are skipped rather than failed, so an interrupted import can simply be re-run
+// Every successful create is committed on its own, results keep the input order
+func (r *UserRepository) CreateBatchTolerant(ctx context.Context, users []*models.User) *BatchCreateResult {
+	return createBatchTolerant(ctx, users, r.Create)
+}
+
+// createBatchTolerant runs create for every user with bounded concurrency and sorts the outcomes
+func createBatchTolerant(ctx context.Context, users []*models.User, create func(ctx context.Context, user *models.User) error) *BatchCreateResult {
+	errs := make([]error, len(users))
+	sem := make(chan struct{}, 10) // Max 10 concurrent
+
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			errs[i] = create(ctx, user)
+		}()
+	}
+	wg.Wait()
+
+	result := &BatchCreateResult{
+		Created: []*models.User{},
+		Skipped: []BatchFailure{},
+		Failed:  []BatchFailure{},
+	}
+	for i, user := range users {
+		switch {
+		case errs[i] == nil:
+			result.Created = append(result.Created, user)
+		case errors.Is(errs[i], ErrUsernameTaken), errors.Is(errs[i], ErrEmailTaken):
+			result.Skipped = append(result.Skipped, BatchFailure{Username: user.Username, Err: errs[i]})
+		default:
+			result.Failed = append(result.Failed, BatchFailure{Username: user.Username, Err: errs[i]})
+		}
+	}
+
+	return result
+}
+
 // FindByIDs retrieves multiple users by their IDs concurrently
 func (r *UserRepository) FindByIDs(ctx context.Context, ids []int64) ([]*models.User, error) {
 	if len(ids) == 0 {
@@ -290,3 +516,27 @@ func (r *UserRepository) FindByIDs(ctx context.Context, ids []int64) ([]*models.
 
 	return results, nil
 }
+
+// scanUser scans a row selected with userColumns into a user
+func scanUser(row pgx.Row) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.FirstName,
+		&user.LastName,
+		&user.PreferredCurrency,
+		&user.Timezone,
+		&user.Email,
+		&user.ExternalID,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,285 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/config/database"
+	dbpkg "LegoManagerAPI/internal/database"
+	"LegoManagerAPI/internal/database/migrate"
+	"LegoManagerAPI/internal/models"
+)
+
+// newIntegrationRepo connects to the database configured through POSTGRES_* env vars, skipping when none is set
+// The schema is migrated first so the users table exists
+func newIntegrationRepo(t *testing.T) *UserRepository {
+	t.Helper()
+
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST not set, skipping database test")
+	}
+
+	port := 5432
+	if p := os.Getenv("POSTGRES_PORT"); p != "" {
+		fmt.Sscanf(p, "%d", &port)
+	}
+
+	db, err := dbpkg.NewPostgresDB(database.DatabaseConfig{
+		Host:     os.Getenv("POSTGRES_HOST"),
+		Port:     port,
+		User:     os.Getenv("POSTGRES_USER"),
+		Password: os.Getenv("POSTGRES_PASSWORD"),
+		DBName:   os.Getenv("POSTGRES_DB"),
+		SSLMode:  "disable",
+		MaxConns: 5,
+		MinConns: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, migrate.Up(context.Background(), db.Pool))
+
+	return NewUserRepository(db.Pool)
+}
+
+func TestUpsertByExternalID_RepeatedSyncCreatesOneUser(t *testing.T) {
+	repo := newIntegrationRepo(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	externalID := fmt.Sprintf("sso-%d", time.Now().UnixNano())
+	sync := func(firstName string) (*models.User, bool) {
+		user := &models.User{
+			Username:          externalID,
+			PasswordHash:      "hash",
+			FirstName:         firstName,
+			LastName:          "Synced",
+			PreferredCurrency: "USD",
+			ExternalID:        &externalID,
+		}
+		created, err := repo.UpsertByExternalID(ctx, user)
+		require.NoError(t, err)
+		return user, created
+	}
+
+	first, created := sync("Ada")
+	t.Cleanup(func() { repo.Delete(context.Background(), first.ID) })
+	assert.True(t, created)
+
+	second, created := sync("Grace")
+	assert.False(t, created, "a repeated sync should update rather than insert")
+	assert.Equal(t, first.ID, second.ID)
+
+	stored, err := repo.FindByUsername(ctx, externalID)
+	require.NoError(t, err)
+	assert.Equal(t, "Grace", stored.FirstName)
+	require.NotNil(t, stored.ExternalID)
+	assert.Equal(t, externalID, *stored.ExternalID)
+}
+
+func TestUpsertByExternalID_LeavesDeletedUsersAlone(t *testing.T) {
+	repo := newIntegrationRepo(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	externalID := fmt.Sprintf("sso-%d", time.Now().UnixNano())
+	user := &models.User{
+		Username:          externalID,
+		PasswordHash:      "hash",
+		FirstName:         "Ada",
+		LastName:          "Synced",
+		PreferredCurrency: "USD",
+		ExternalID:        &externalID,
+	}
+	_, err := repo.UpsertByExternalID(ctx, user)
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Delete(context.Background(), user.ID) })
+	require.NoError(t, repo.SoftDelete(ctx, user.ID))
+
+	user.FirstName = "Grace"
+	_, err = repo.UpsertByExternalID(ctx, user)
+	assert.ErrorIs(t, err, ErrUserDeleted)
+
+	stored, err := repo.FindByID(IncludeDeleted(ctx), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", stored.FirstName)
+	assert.NotNil(t, stored.DeletedAt, "the sync should not restore the user")
+}
+
+func TestCreate_DuplicateEmailIsTaken(t *testing.T) {
+	repo := newIntegrationRepo(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	suffix := time.Now().UnixNano()
+	email := fmt.Sprintf("dup-%d@example.com", suffix)
+	newUser := func(username string) *models.User {
+		return &models.User{
+			Username:          username,
+			PasswordHash:      "hash",
+			FirstName:         "Dup",
+			LastName:          "Email",
+			PreferredCurrency: "USD",
+			Email:             &email,
+		}
+	}
+
+	first := newUser(fmt.Sprintf("dup-a-%d", suffix))
+	require.NoError(t, repo.Create(ctx, first))
+	t.Cleanup(func() { repo.Delete(context.Background(), first.ID) })
+
+	exists, err := repo.EmailExists(ctx, email)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	err = repo.Create(ctx, newUser(fmt.Sprintf("dup-b-%d", suffix)))
+	assert.ErrorIs(t, err, ErrEmailTaken)
+}
+
+func TestSoftDelete_HidesUserUnlessIncludeDeleted(t *testing.T) {
+	repo := newIntegrationRepo(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user := &models.User{
+		Username:          fmt.Sprintf("soft-%d", time.Now().UnixNano()),
+		PasswordHash:      "hash",
+		FirstName:         "Soft",
+		LastName:          "Deleted",
+		PreferredCurrency: "USD",
+	}
+	require.NoError(t, repo.Create(ctx, user))
+	t.Cleanup(func() { repo.Delete(context.Background(), user.ID) })
+
+	require.NoError(t, repo.SoftDelete(ctx, user.ID))
+	assert.ErrorIs(t, repo.SoftDelete(ctx, user.ID), ErrUserNotFound, "a deleted user can't be deleted again")
+
+	_, err := repo.FindByID(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	stored, err := repo.FindByID(IncludeDeleted(ctx), user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.DeletedAt)
+}
+
+func TestListAfter_PagesWithCursor(t *testing.T) {
+	repo := newIntegrationRepo(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	suffix := time.Now().UnixNano()
+	var created []*models.User
+	for i := 0; i < 3; i++ {
+		user := &models.User{
+			Username:          fmt.Sprintf("page-%d-%d", suffix, i),
+			PasswordHash:      "hash",
+			FirstName:         "Page",
+			LastName:          "User",
+			PreferredCurrency: "USD",
+		}
+		require.NoError(t, repo.Create(ctx, user))
+		t.Cleanup(func() { repo.Delete(context.Background(), user.ID) })
+		created = append(created, user)
+	}
+
+	first, next, err := repo.ListAfter(ctx, "", 2)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	require.NotEmpty(t, next)
+	assert.Equal(t, created[2].ID, first[0].ID, "newest users come first")
+	assert.Equal(t, created[1].ID, first[1].ID)
+
+	second, _, err := repo.ListAfter(ctx, next, 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, second)
+	assert.Equal(t, created[0].ID, second[0].ID, "the next page continues after the cursor")
+}
+
+func TestCreateBatchAtomic_DuplicateRollsBackEverything(t *testing.T) {
+	repo := newIntegrationRepo(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	suffix := time.Now().UnixNano()
+	newUser := func(username string) *models.User {
+		return &models.User{
+			Username:          username,
+			PasswordHash:      "hash",
+			FirstName:         "Batch",
+			LastName:          "User",
+			PreferredCurrency: "USD",
+		}
+	}
+
+	first := fmt.Sprintf("batch-a-%d", suffix)
+	duplicate := fmt.Sprintf("batch-b-%d", suffix)
+	last := fmt.Sprintf("batch-c-%d", suffix)
+	users := []*models.User{newUser(first), newUser(duplicate), newUser(duplicate), newUser(last)}
+
+	err := repo.CreateBatchAtomic(ctx, users)
+	require.ErrorIs(t, err, ErrUsernameTaken)
+
+	for _, username := range []string{first, duplicate, last} {
+		exists, err := repo.UsernameExists(ctx, username)
+		require.NoError(t, err)
+		assert.False(t, exists, "%s must not be committed", username)
+	}
+	for _, user := range users {
+		assert.Zero(t, user.ID, "ids are only set after a commit")
+	}
+
+	users = []*models.User{newUser(first), newUser(last)}
+	require.NoError(t, repo.CreateBatchAtomic(ctx, users))
+	for _, user := range users {
+		t.Cleanup(func() { repo.Delete(context.Background(), user.ID) })
+		assert.NotZero(t, user.ID)
+	}
+}
+
+func TestWithTransactionResult(t *testing.T) {
+	repo := newIntegrationRepo(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	username := fmt.Sprintf("tx-%d", time.Now().UnixNano())
+	insert := func(tx pgx.Tx) (int64, error) {
+		var id int64
+		err := tx.QueryRow(ctx, insertUserQuery, insertUserArgs(&models.User{
+			Username:          username,
+			PasswordHash:      "hash",
+			FirstName:         "Tx",
+			LastName:          "Result",
+			PreferredCurrency: "USD",
+		})...).Scan(&id, new(time.Time), new(time.Time))
+		return id, err
+	}
+
+	id, err := WithTransactionResult(ctx, repo.DB(), func(tx pgx.Tx) (int64, error) {
+		id, err := insert(tx)
+		if err != nil {
+			return 0, err
+		}
+		return id, errors.New("abort")
+	})
+	require.EqualError(t, err, "abort")
+	assert.Zero(t, id, "a rolled back transaction returns the zero value")
+
+	exists, err := repo.UsernameExists(ctx, username)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	id, err = WithTransactionResult(ctx, repo.DB(), insert)
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Delete(context.Background(), id) })
+
+	stored, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, username, stored.Username)
+}
@@ -0,0 +1,166 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	err := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"})
+	assert.True(t, isUniqueViolation(err))
+	assert.False(t, isUniqueViolation(&pgconn.PgError{Code: "23502"}))
+	assert.False(t, isUniqueViolation(errors.New("connection reset")))
+}
+
+func TestTakenError(t *testing.T) {
+	email := "alice@example.com"
+	user := &models.User{Username: "alice", Email: &email}
+
+	err := takenError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}, user)
+	assert.ErrorIs(t, err, ErrEmailTaken)
+
+	err = takenError(&pgconn.PgError{Code: "23505", ConstraintName: "users_username_key"}, user)
+	assert.ErrorIs(t, err, ErrUsernameTaken)
+
+	assert.NoError(t, takenError(errors.New("connection reset"), user))
+	assert.NoError(t, takenError(nil, user))
+}
+
+func TestCreateBatchTolerant_SkipsExistingUsernames(t *testing.T) {
+	existing := map[string]bool{"bob": true, "dave": true}
+	users := []*models.User{
+		{Username: "alice"},
+		{Username: "bob"},
+		{Username: "carol"},
+		{Username: "dave"},
+		{Username: "eve"},
+	}
+
+	result := createBatchTolerant(context.Background(), users, func(ctx context.Context, user *models.User) error {
+		if existing[user.Username] {
+			return fmt.Errorf("%w: %s", ErrUsernameTaken, user.Username)
+		}
+		if user.Username == "eve" {
+			return errors.New("connection reset")
+		}
+		return nil
+	})
+
+	require.Len(t, result.Created, 2)
+	assert.Equal(t, "alice", result.Created[0].Username)
+	assert.Equal(t, "carol", result.Created[1].Username)
+
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, "bob", result.Skipped[0].Username)
+	assert.ErrorIs(t, result.Skipped[0].Err, ErrUsernameTaken)
+	assert.Equal(t, "dave", result.Skipped[1].Username)
+
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, "eve", result.Failed[0].Username)
+	assert.EqualError(t, result.Failed[0].Err, "connection reset")
+}
+
+func TestBuildUpdate_User(t *testing.T) {
+	externalID := "sso-42"
+	email := "alice@example.com"
+	user := &models.User{
+		BaseModel:         models.BaseModel{ID: 7},
+		Username:          "alice",
+		PasswordHash:      "hash",
+		FirstName:         "Alice",
+		LastName:          "Smith",
+		PreferredCurrency: "EUR",
+		Timezone:          "Europe/Berlin",
+		Email:             &email,
+		ExternalID:        &externalID,
+	}
+
+	query, args, updatedAt, err := buildUpdate("users", user, " AND deleted_at IS NULL", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"UPDATE users SET username = $1, password_hash = $2, first_name = $3, last_name = $4, "+
+			"preferred_currency = $5, timezone = $6, email = $7, external_id = $8, updated_at = NOW() "+
+			"WHERE id = $9 AND deleted_at IS NULL RETURNING updated_at",
+		query,
+	)
+	assert.Equal(t, []any{"alice", "hash", "Alice", "Smith", "EUR", "Europe/Berlin", &email, &externalID}, args)
+	assert.Same(t, &user.UpdatedAt, updatedAt)
+}
+
+func TestBuildUpdate_RequiresUpdatedAt(t *testing.T) {
+	entity := &struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}{}
+
+	_, _, _, err := buildUpdate("things", entity, "", nil)
+	assert.ErrorContains(t, err, "no updated_at")
+}
+
+func TestBuildUpdate_ImmutableColumnsArePerRepository(t *testing.T) {
+	entity := &struct {
+		ID        int64     `db:"id"`
+		Archived  bool      `db:"archived"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}{}
+
+	query, _, _, err := buildUpdate("things", entity, "", nil)
+	require.NoError(t, err)
+	assert.Contains(t, query, "archived = $1", "only the collection repository protects its archive columns")
+
+	_, _, _, err = buildUpdate("things", entity, "", NewCollectionRepository(nil).immutable)
+	assert.ErrorContains(t, err, "no updatable columns")
+}
+
+func TestNotDeletedFilters(t *testing.T) {
+	users := NewUserRepository(nil)
+	ctx := context.Background()
+
+	assert.Equal(t, " WHERE deleted_at IS NULL", users.whereNotDeleted(ctx))
+	assert.Equal(t, " AND deleted_at IS NULL", users.andNotDeleted(ctx))
+
+	admin := IncludeDeleted(ctx)
+	assert.Empty(t, users.whereNotDeleted(admin))
+	assert.Empty(t, users.andNotDeleted(admin))
+
+	plain := NewBaseRepository[models.User](nil, "users")
+	assert.Empty(t, plain.whereNotDeleted(ctx), "tables without deleted_at must not be filtered on it")
+}
+
+func TestSoftDelete_Unsupported(t *testing.T) {
+	plain := NewBaseRepository[models.User](nil, "users")
+	assert.ErrorIs(t, plain.SoftDelete(context.Background(), 1), ErrSoftDeleteUnsupported)
+}
+
+func TestParseUserSort(t *testing.T) {
+	tests := []struct {
+		value   string
+		orderBy string
+		ok      bool
+	}{
+		{"username", "username ASC, id ASC", true},
+		{"-last_name", "last_name DESC, id DESC", true},
+		{"created_at", "created_at ASC, id ASC", true},
+		{"", "created_at DESC, id DESC", false},
+		{"password_hash", "created_at DESC, id DESC", false},
+		{"-username; DROP TABLE users", "created_at DESC, id DESC", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			sort, ok := ParseUserSort(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.orderBy, sort.orderBy())
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"LegoManagerAPI/internal/models"
+)
+
+// wishlistColumns is the column list selected by every wishlist query, in the order scanWishlist expects
+const wishlistColumns = "id, user_id, item_type, item_id, condition, target_price, created_at, updated_at"
+
+// wishlistItemConstraint is the unique constraint on (user_id, item_type, item_id, condition)
+const wishlistItemConstraint = "wishlist_items_user_item_key"
+
+var (
+	// ErrWishlistItemNotFound is returned when no wishlist item matches the given id
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+
+	// ErrWishlistItemExists is returned when the item is already on the user's wishlist in the same condition
+	ErrWishlistItemExists = errors.New("wishlist item already exists")
+)
+
+// WishlistRepository handles the items users want to buy
+type WishlistRepository struct {
+	*BaseRepository[models.Wishlist, *models.Wishlist]
+}
+
+// NewWishlistRepository creates a new Wishlist repository
+func NewWishlistRepository(db *pgxpool.Pool) *WishlistRepository {
+	return &WishlistRepository{
+		BaseRepository: NewBaseRepository[models.Wishlist](db, "wishlist_items"),
+	}
+}
+
+// Create adds an item to a wishlist, transient database errors are retried
+func (r *WishlistRepository) Create(ctx context.Context, item *models.Wishlist) error {
+	query := `
+		INSERT INTO wishlist_items (user_id, item_type, item_id, condition, target_price, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		return r.DB().QueryRow(ctx, query, item.UserID, item.ItemType, item.ItemID, item.Condition, item.TargetPrice).
+			Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	})
+
+	if exists := wishlistExistsError(err, item); exists != nil {
+		return exists
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create wishlist item: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a wishlist item by ID, transient database errors are retried
+func (r *WishlistRepository) FindByID(ctx context.Context, id int64) (*models.Wishlist, error) {
+	query := `SELECT ` + wishlistColumns + ` FROM wishlist_items WHERE id = $1`
+
+	var item *models.Wishlist
+	err := r.Retry(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = scanWishlist(r.DB().QueryRow(ctx, query, id))
+		return err
+	})
+
+	if err == pgx.ErrNoRows {
+		return nil, ErrWishlistItemNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find wishlist item: %w", err)
+	}
+
+	return item, nil
+}
+
+// FindByUser returns the user's wishlist, most recently added first
+func (r *WishlistRepository) FindByUser(ctx context.Context, userID int64) ([]*models.Wishlist, error) {
+	items, err := r.Find(ctx,
+		[]Filter{{Column: "user_id", Operator: OpEqual, Value: userID}},
+		QueryOptions{
+			Columns: wishlistColumns,
+			OrderBy: []Order{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+		},
+		scanWishlist,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find wishlist of user %d: %w", userID, err)
+	}
+
+	return items, nil
+}
+
+// Update modifies an existing wishlist item
+func (r *WishlistRepository) Update(ctx context.Context, item *models.Wishlist) error {
+	err := r.BaseRepository.Update(ctx, item)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWishlistItemNotFound
+	}
+
+	if exists := wishlistExistsError(err, item); exists != nil {
+		return exists
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update wishlist item: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an item from its wishlist
+func (r *WishlistRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.DB().Exec(ctx, `DELETE FROM wishlist_items WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete wishlist item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrWishlistItemNotFound
+	}
+
+	return nil
+}
+
+// wishlistExistsError maps a violation of wishlistItemConstraint to ErrWishlistItemExists, nil for any other error
+func wishlistExistsError(err error, item *models.Wishlist) error {
+	constraint, ok := uniqueViolationConstraint(err)
+	if !ok || constraint != wishlistItemConstraint {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s (%s)", ErrWishlistItemExists, item.ItemType, item.ItemID, item.Condition)
+}
+
+// scanWishlist scans a row selected with wishlistColumns into a wishlist item
+func scanWishlist(row pgx.Row) (*models.Wishlist, error) {
+	var item models.Wishlist
+	err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.ItemType,
+		&item.ItemID,
+		&item.Condition,
+		&item.TargetPrice,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
@@ -0,0 +1,50 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestWishlistRepository_Lifecycle(t *testing.T) {
+	users := newIntegrationRepo(t)
+	wishlist := NewWishlistRepository(users.DB())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	owner := &models.User{
+		Username:          fmt.Sprintf("wisher-%d", time.Now().UnixNano()),
+		PasswordHash:      "hash",
+		FirstName:         "Wish",
+		LastName:          "Ful",
+		PreferredCurrency: "USD",
+	}
+	require.NoError(t, users.Create(ctx, owner))
+	// Deleting the user cascades to their wishlist
+	t.Cleanup(func() { users.Delete(context.Background(), owner.ID) })
+
+	target := 450.0
+	item := &models.Wishlist{UserID: owner.ID, ItemType: "SET", ItemID: "75192-1", Condition: models.ConditionNew, TargetPrice: &target}
+	require.NoError(t, wishlist.Create(ctx, item))
+
+	err := wishlist.Create(ctx, &models.Wishlist{UserID: owner.ID, ItemType: "SET", ItemID: "75192-1", Condition: models.ConditionNew})
+	assert.ErrorIs(t, err, ErrWishlistItemExists)
+
+	item.TargetPrice = nil
+	require.NoError(t, wishlist.Update(ctx, item))
+
+	items, err := wishlist.FindByUser(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Nil(t, items[0].TargetPrice)
+
+	require.NoError(t, wishlist.Delete(ctx, item.ID))
+	_, err = wishlist.FindByID(ctx, item.ID)
+	assert.ErrorIs(t, err, ErrWishlistItemNotFound)
+}
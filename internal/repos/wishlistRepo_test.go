@@ -0,0 +1,34 @@
+package repos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"LegoManagerAPI/internal/models"
+)
+
+func TestWishlistExistsError(t *testing.T) {
+	item := &models.Wishlist{ItemType: "SET", ItemID: "75192-1", Condition: models.ConditionNew}
+
+	err := wishlistExistsError(&pgconn.PgError{Code: "23505", ConstraintName: "wishlist_items_user_item_key"}, item)
+	assert.ErrorIs(t, err, ErrWishlistItemExists)
+
+	assert.NoError(t, wishlistExistsError(&pgconn.PgError{Code: "23505", ConstraintName: "collection_items_user_item_key"}, item))
+	assert.NoError(t, wishlistExistsError(errors.New("connection reset"), item))
+}
+
+func TestBuildUpdate_Wishlist(t *testing.T) {
+	target := 450.0
+	item := &models.Wishlist{UserID: 7, ItemType: "SET", ItemID: "75192-1", Condition: models.ConditionNew, TargetPrice: &target}
+
+	query, args, _, err := buildUpdate("wishlist_items", item, "", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "UPDATE wishlist_items SET user_id = $1, item_type = $2, item_id = $3, condition = $4,"+
+		" target_price = $5, updated_at = NOW() WHERE id = $6 RETURNING updated_at", query)
+	assert.Equal(t, []any{int64(7), "SET", "75192-1", "N", &target}, args)
+}